@@ -0,0 +1,179 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Conformance classes from OGC API - Records - Part 1: Core.
+const (
+	RecordCoreConformance            = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-core"
+	RecordCollectionConformance      = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-collection"
+	SearchableCatalogConformance     = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/searchable-catalog"
+	LocalResourcesCatalogConformance = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/local-resources-catalog"
+	CrawlableCatalogConformance      = "http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/crawlable-catalog"
+)
+
+const (
+	catalogType        = "Catalog"
+	recordItemType     = "record"
+	catalogRequirement = "http://www.opengis.net/spec/ogcapi-records-1/1.0/req/record-collection"
+)
+
+// RecordCatalog is an extension for collections that are OGC API - Records catalogs.  The
+// collection is encoded with a "Catalog" type and a "record" item type.  If ConformsTo is empty,
+// the catalog conforms to the record core and record collection conformance classes.
+type RecordCatalog struct {
+	ConformsTo []string
+	Keywords   []string
+	Themes     []*Theme
+	Contacts   []*Contact
+	Language   *Language
+	Languages  []*Language
+	Rights     string
+	License    string
+	Created    time.Time
+	Updated    time.Time
+}
+
+var (
+	_ Extension = (*RecordCatalog)(nil)
+)
+
+func (c *RecordCatalog) URI() string {
+	return catalogRequirement
+}
+
+func (c *RecordCatalog) conformsTo() []string {
+	if len(c.ConformsTo) > 0 {
+		return c.ConformsTo
+	}
+	return []string{RecordCoreConformance, RecordCollectionConformance}
+}
+
+func (c *RecordCatalog) Encode(collectionMap map[string]any) error {
+	if err := validateRecordMetadata(c.Themes, c.Contacts, c.Language, c.Languages, nil); err != nil {
+		return err
+	}
+
+	itemType, _ := collectionMap["itemType"].(string)
+	if itemType != "" && itemType != recordItemType {
+		return fmt.Errorf("expected item type %q for a catalog, got %q", recordItemType, itemType)
+	}
+
+	collectionMap["type"] = catalogType
+	collectionMap["itemType"] = recordItemType
+	collectionMap["conformsTo"] = c.conformsTo()
+
+	if len(c.Keywords) > 0 {
+		collectionMap["keywords"] = c.Keywords
+	}
+	if len(c.Themes) > 0 {
+		collectionMap["themes"] = c.Themes
+	}
+	if len(c.Contacts) > 0 {
+		collectionMap["contacts"] = c.Contacts
+	}
+	if c.Language != nil {
+		collectionMap["language"] = c.Language
+	}
+	if len(c.Languages) > 0 {
+		collectionMap["languages"] = c.Languages
+	}
+	if c.Rights != "" {
+		collectionMap["rights"] = c.Rights
+	}
+	if c.License != "" {
+		collectionMap["license"] = c.License
+	}
+	if !c.Created.IsZero() {
+		collectionMap["created"] = c.Created.Format(time.RFC3339Nano)
+	}
+	if !c.Updated.IsZero() {
+		collectionMap["updated"] = c.Updated.Format(time.RFC3339Nano)
+	}
+
+	return nil
+}
+
+type decodedCatalog struct {
+	Type       string      `json:"type"`
+	ItemType   string      `json:"itemType"`
+	ConformsTo []string    `json:"conformsTo"`
+	Keywords   []string    `json:"keywords"`
+	Themes     []*Theme    `json:"themes"`
+	Contacts   []*Contact  `json:"contacts"`
+	Language   *Language   `json:"language"`
+	Languages  []*Language `json:"languages"`
+	Rights     string      `json:"rights"`
+	License    string      `json:"license"`
+	Created    string      `json:"created"`
+	Updated    string      `json:"updated"`
+}
+
+// Decode reads the catalog properties from collection JSON.  An error is returned if the
+// collection is not a catalog of records.
+func (c *RecordCatalog) Decode(data []byte) error {
+	d := &decodedCatalog{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return err
+	}
+	if d.Type != catalogType {
+		return fmt.Errorf("expected type %q for a catalog, got %q", catalogType, d.Type)
+	}
+	if d.ItemType != recordItemType {
+		return fmt.Errorf("expected item type %q for a catalog, got %q", recordItemType, d.ItemType)
+	}
+	if len(d.ConformsTo) == 0 {
+		return errors.New("missing conformsTo")
+	}
+	if err := validateRecordMetadata(d.Themes, d.Contacts, d.Language, d.Languages, nil); err != nil {
+		return err
+	}
+
+	decoded := &RecordCatalog{
+		ConformsTo: d.ConformsTo,
+		Keywords:   d.Keywords,
+		Themes:     d.Themes,
+		Contacts:   d.Contacts,
+		Language:   d.Language,
+		Languages:  d.Languages,
+		Rights:     d.Rights,
+		License:    d.License,
+	}
+	if d.Created != "" {
+		created, err := parseRecordTime(d.Created)
+		if err != nil {
+			return fmt.Errorf("invalid created time: %w", err)
+		}
+		decoded.Created = created
+	}
+	if d.Updated != "" {
+		updated, err := parseRecordTime(d.Updated)
+		if err != nil {
+			return fmt.Errorf("invalid updated time: %w", err)
+		}
+		decoded.Updated = updated
+	}
+
+	*c = *decoded
+	return nil
+}
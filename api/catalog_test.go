@@ -0,0 +1,161 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordCatalog(t *testing.T) {
+	catalog := &api.RecordCatalog{
+		ConformsTo: []string{
+			api.RecordCoreConformance,
+			api.RecordCollectionConformance,
+			api.SearchableCatalogConformance,
+			api.LocalResourcesCatalogConformance,
+		},
+		Keywords: []string{"tiles", "scenes"},
+		Themes: []*api.Theme{
+			{
+				Concepts: []*api.Concept{{Id: "imagery"}},
+				Scheme:   "https://example.com/themes",
+			},
+		},
+		Contacts: []*api.Contact{
+			{Organization: "Example Org", Emails: []*api.ContactPoint{{Value: "info@example.com"}}},
+		},
+		Language: &api.Language{Code: "en"},
+		License:  "CC-BY-4.0",
+		Updated:  time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	collection := &api.Collection{
+		Id:         "catalog",
+		Title:      "Example Catalog",
+		Links:      []*api.Link{},
+		Extensions: []api.Extension{catalog},
+	}
+
+	expected := `{
+		"id": "catalog",
+		"type": "Catalog",
+		"itemType": "record",
+		"title": "Example Catalog",
+		"links": [],
+		"conformsTo": [
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-core",
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-collection",
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/searchable-catalog",
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/local-resources-catalog"
+		],
+		"keywords": ["tiles", "scenes"],
+		"themes": [{"concepts": [{"id": "imagery"}], "scheme": "https://example.com/themes"}],
+		"contacts": [{"organization": "Example Org", "emails": [{"value": "info@example.com"}]}],
+		"language": {"code": "en"},
+		"license": "CC-BY-4.0",
+		"updated": "2023-06-01T00:00:00Z"
+	}`
+
+	actual, err := json.Marshal(collection)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+
+	decodedCatalog := &api.RecordCatalog{}
+	decoded := &api.Collection{Extensions: []api.Extension{decodedCatalog}}
+	require.NoError(t, json.Unmarshal(actual, decoded))
+	assert.Equal(t, catalog, decodedCatalog)
+	assert.Equal(t, "record", decoded.ItemType)
+}
+
+func TestRecordCatalogDefaultConformance(t *testing.T) {
+	collection := &api.Collection{
+		Id:         "catalog",
+		Links:      []*api.Link{},
+		Extensions: []api.Extension{&api.RecordCatalog{}},
+	}
+
+	expected := `{
+		"id": "catalog",
+		"type": "Catalog",
+		"itemType": "record",
+		"links": [],
+		"conformsTo": [
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-core",
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/conf/record-collection"
+		]
+	}`
+
+	actual, err := json.Marshal(collection)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func TestRecordCatalogErrors(t *testing.T) {
+	t.Run("encode item type", func(t *testing.T) {
+		collection := &api.Collection{
+			Id:         "catalog",
+			ItemType:   "feature",
+			Extensions: []api.Extension{&api.RecordCatalog{}},
+		}
+		_, err := json.Marshal(collection)
+		assert.ErrorContains(t, err, `expected item type "record" for a catalog, got "feature"`)
+	})
+
+	t.Run("encode invalid contact", func(t *testing.T) {
+		collection := &api.Collection{
+			Id:         "catalog",
+			Extensions: []api.Extension{&api.RecordCatalog{Contacts: []*api.Contact{{}}}},
+		}
+		_, err := json.Marshal(collection)
+		assert.ErrorContains(t, err, "invalid contact 0: missing name or organization")
+	})
+
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "not a catalog",
+			data: `{"id": "c", "itemType": "record", "links": [], "conformsTo": ["x"]}`,
+			err:  `expected type "Catalog" for a catalog, got ""`,
+		},
+		{
+			name: "feature items",
+			data: `{"id": "c", "type": "Catalog", "itemType": "feature", "links": [], "conformsTo": ["x"]}`,
+			err:  `expected item type "record" for a catalog, got "feature"`,
+		},
+		{
+			name: "missing conformsTo",
+			data: `{"id": "c", "type": "Catalog", "itemType": "record", "links": []}`,
+			err:  "missing conformsTo",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			collection := &api.Collection{Extensions: []api.Extension{&api.RecordCatalog{}}}
+			assert.EqualError(t, json.Unmarshal([]byte(tc.data), collection), tc.err)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/planetlabs/go-ogc/util/mercator"
+)
+
+// CRS URIs used by OGC API - Features.
+const (
+	CRS84    = "http://www.opengis.net/def/crs/OGC/1.3/CRS84"
+	CRS84h   = "http://www.opengis.net/def/crs/OGC/0/CRS84h"
+	EPSG3857 = "http://www.opengis.net/def/crs/EPSG/0/3857"
+)
+
+// ContentCrsHeader is the response header that identifies the CRS of the returned coordinates.
+const ContentCrsHeader = "Content-Crs"
+
+const crsURIPrefix = "http://www.opengis.net/def/crs/"
+
+// isGeographicCRS reports whether coordinates in the CRS are longitude and latitude (CRS84 or
+// CRS84h).  An empty CRS is the default (CRS84).
+func isGeographicCRS(crs string) bool {
+	return crs == "" || crs == CRS84 || crs == CRS84h
+}
+
+// ParseCRS validates a CRS URI or safe CURIE and returns the CRS URI.  A safe CURIE is an
+// authority and code in square brackets (e.g. "[EPSG:3857]" or "[OGC:CRS84]").  URIs must be
+// OGC CRS URIs of the form http://www.opengis.net/def/crs/{authority}/{version}/{code}.
+func ParseCRS(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		authority, code, ok := strings.Cut(value[1:len(value)-1], ":")
+		if !ok || authority == "" || code == "" {
+			return "", fmt.Errorf("invalid CRS %q: expected [authority:code]", value)
+		}
+		switch strings.ToUpper(authority) {
+		case "EPSG":
+			if !isDigits(code) {
+				return "", fmt.Errorf("invalid CRS %q: expected a numeric EPSG code", value)
+			}
+			return crsURIPrefix + "EPSG/0/" + code, nil
+		case "OGC":
+			switch code {
+			case "CRS84":
+				return CRS84, nil
+			case "CRS84h":
+				return CRS84h, nil
+			}
+			return crsURIPrefix + "OGC/0/" + code, nil
+		}
+		return "", fmt.Errorf("invalid CRS %q: unsupported authority %q", value, authority)
+	}
+
+	if strings.HasPrefix(value, "https://www.opengis.net/def/crs/") {
+		value = "http://" + strings.TrimPrefix(value, "https://")
+	}
+	if !strings.HasPrefix(value, crsURIPrefix) {
+		return "", fmt.Errorf("invalid CRS %q: expected a URI starting with %s or a safe CURIE", value, crsURIPrefix)
+	}
+	parts := strings.Split(strings.TrimPrefix(value, crsURIPrefix), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("invalid CRS %q: expected %s{authority}/{version}/{code}", value, crsURIPrefix)
+	}
+	if parts[0] == "EPSG" && !isDigits(parts[2]) {
+		return "", fmt.Errorf("invalid CRS %q: expected a numeric EPSG code", value)
+	}
+	return value, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// CRSQuery holds the crs and bbox-crs query parameters from OGC API - Features - Part 2.
+type CRSQuery struct {
+	// Crs is the CRS requested for the response coordinates.
+	Crs string
+
+	// BboxCrs is the CRS of the bbox query parameter.
+	BboxCrs string
+}
+
+// ParseCRSQuery parses the crs and bbox-crs query parameters.  Both default to CRS84 and must be
+// one of the supported CRS (for example, the Crs of a collection).  CRS84 is always supported.
+// Errors are returned as an *InvalidParameterError.
+func ParseCRSQuery(query url.Values, supported []string) (*CRSQuery, error) {
+	allowed := map[string]bool{CRS84: true}
+	for _, value := range supported {
+		crs, err := ParseCRS(value)
+		if err != nil {
+			continue
+		}
+		allowed[crs] = true
+	}
+
+	params := &CRSQuery{Crs: CRS84, BboxCrs: CRS84}
+	for _, param := range []struct {
+		name   string
+		target *string
+	}{
+		{name: "crs", target: &params.Crs},
+		{name: "bbox-crs", target: &params.BboxCrs},
+	} {
+		if !query.Has(param.name) {
+			continue
+		}
+		crs, err := ParseCRS(query.Get(param.name))
+		if err != nil {
+			return nil, invalidParameter(param.name, "%s", err)
+		}
+		if !allowed[crs] {
+			return nil, invalidParameter(param.name, "unsupported CRS %q", crs)
+		}
+		*param.target = crs
+	}
+	return params, nil
+}
+
+// ContentCrs returns the Content-Crs header value for a CRS URI.
+func ContentCrs(crs string) string {
+	return "<" + crs + ">"
+}
+
+// CoordinateTransform transforms a position from one CRS to another.  The provided position must
+// not be modified.
+type CoordinateTransform func(position []float64) []float64
+
+// GetCoordinateTransform returns a transform between two CRS.  Transforms between CRS84 (or
+// CRS84h) and EPSG:3857 are supported, and any third coordinate is preserved.  The transform is
+// nil if the CRS are the same.
+func GetCoordinateTransform(from string, to string) (CoordinateTransform, error) {
+	fromCrs, err := ParseCRS(from)
+	if err != nil {
+		return nil, err
+	}
+	toCrs, err := ParseCRS(to)
+	if err != nil {
+		return nil, err
+	}
+	if fromCrs == toCrs {
+		return nil, nil
+	}
+
+	switch {
+	case isGeographicCRS(fromCrs) && isGeographicCRS(toCrs):
+		return nil, nil
+	case isGeographicCRS(fromCrs) && toCrs == EPSG3857:
+		return transformXY(mercator.Forward), nil
+	case fromCrs == EPSG3857 && isGeographicCRS(toCrs):
+		return transformXY(mercator.Inverse), nil
+	}
+	return nil, fmt.Errorf("unsupported transform from %s to %s", fromCrs, toCrs)
+}
+
+func transformXY(transform func([]float64) []float64) CoordinateTransform {
+	return func(position []float64) []float64 {
+		transformed := append(transform(position[:2]), position[2:]...)
+		return transformed
+	}
+}
+
+// transformFeatures returns copies of the features with transformed geometries.
+func transformFeatures(features []*Feature, transform CoordinateTransform) []*Feature {
+	transformed := make([]*Feature, len(features))
+	for i, feature := range features {
+		if feature == nil || feature.Geometry == nil {
+			transformed[i] = feature
+			continue
+		}
+		clone := *feature
+		clone.Geometry = geometry.Transform(feature.Geometry, transform)
+		transformed[i] = &clone
+	}
+	return transformed
+}
@@ -0,0 +1,159 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCRS(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected string
+		err      string
+	}{
+		{value: "http://www.opengis.net/def/crs/EPSG/0/3857", expected: api.EPSG3857},
+		{value: "https://www.opengis.net/def/crs/EPSG/0/4326", expected: "http://www.opengis.net/def/crs/EPSG/0/4326"},
+		{value: "http://www.opengis.net/def/crs/OGC/1.3/CRS84", expected: api.CRS84},
+		{value: "[EPSG:3857]", expected: api.EPSG3857},
+		{value: "[OGC:CRS84]", expected: api.CRS84},
+		{value: "[OGC:CRS84h]", expected: api.CRS84h},
+		{value: "EPSG:3857", err: `invalid CRS "EPSG:3857": expected a URI starting with http://www.opengis.net/def/crs/ or a safe CURIE`},
+		{value: "[EPSG:abc]", err: `invalid CRS "[EPSG:abc]": expected a numeric EPSG code`},
+		{value: "[FOO:1]", err: `invalid CRS "[FOO:1]": unsupported authority "FOO"`},
+		{value: "[EPSG]", err: `invalid CRS "[EPSG]": expected [authority:code]`},
+		{value: "http://www.opengis.net/def/crs/EPSG/3857", err: `invalid CRS "http://www.opengis.net/def/crs/EPSG/3857": expected http://www.opengis.net/def/crs/{authority}/{version}/{code}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			crs, err := api.ParseCRS(c.value)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, crs)
+		})
+	}
+}
+
+func TestParseCRSQuery(t *testing.T) {
+	supported := []string{api.CRS84, "[EPSG:3857]"}
+
+	params, err := api.ParseCRSQuery(url.Values{}, supported)
+	require.NoError(t, err)
+	assert.Equal(t, &api.CRSQuery{Crs: api.CRS84, BboxCrs: api.CRS84}, params)
+
+	params, err = api.ParseCRSQuery(url.Values{"crs": {"[EPSG:3857]"}, "bbox-crs": {api.EPSG3857}}, supported)
+	require.NoError(t, err)
+	assert.Equal(t, &api.CRSQuery{Crs: api.EPSG3857, BboxCrs: api.EPSG3857}, params)
+
+	_, err = api.ParseCRSQuery(url.Values{"crs": {"[EPSG:4326]"}}, supported)
+	assert.EqualError(t, err, `invalid crs parameter: unsupported CRS "http://www.opengis.net/def/crs/EPSG/0/4326"`)
+
+	_, err = api.ParseCRSQuery(url.Values{"bbox-crs": {"nope"}}, supported)
+	assert.ErrorContains(t, err, `invalid bbox-crs parameter: invalid CRS "nope"`)
+}
+
+func TestContentCrs(t *testing.T) {
+	assert.Equal(t, "<http://www.opengis.net/def/crs/EPSG/0/3857>", api.ContentCrs(api.EPSG3857))
+}
+
+func TestCollectionStorageCrs(t *testing.T) {
+	collection := &api.Collection{
+		Id:                        "buildings",
+		Links:                     []*api.Link{},
+		Crs:                       []string{api.CRS84, api.EPSG3857},
+		StorageCrs:                api.EPSG3857,
+		StorageCrsCoordinateEpoch: 2017.23,
+	}
+
+	expected := `{
+		"id": "buildings",
+		"links": [],
+		"crs": ["http://www.opengis.net/def/crs/OGC/1.3/CRS84", "http://www.opengis.net/def/crs/EPSG/0/3857"],
+		"storageCrs": "http://www.opengis.net/def/crs/EPSG/0/3857",
+		"storageCrsCoordinateEpoch": 2017.23
+	}`
+
+	data, err := json.Marshal(collection)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(data))
+
+	decoded := &api.Collection{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, collection, decoded)
+}
+
+func TestGetCoordinateTransform(t *testing.T) {
+	transform, err := api.GetCoordinateTransform("[OGC:CRS84]", "[OGC:CRS84]")
+	require.NoError(t, err)
+	assert.Nil(t, transform)
+
+	forward, err := api.GetCoordinateTransform(api.CRS84h, api.EPSG3857)
+	require.NoError(t, err)
+	projected := forward([]float64{180, 0, 100})
+	assert.InDelta(t, 20037508.342789244, projected[0], 1e-6)
+	assert.InDelta(t, 0, projected[1], 1e-6)
+	assert.Equal(t, float64(100), projected[2])
+
+	inverse, err := api.GetCoordinateTransform(api.EPSG3857, api.CRS84)
+	require.NoError(t, err)
+	geographic := inverse(projected[:2])
+	assert.InDelta(t, 180, geographic[0], 1e-9)
+	assert.InDelta(t, 0, geographic[1], 1e-9)
+
+	_, err = api.GetCoordinateTransform(api.CRS84, "[EPSG:4326]")
+	assert.EqualError(t, err, "unsupported transform from http://www.opengis.net/def/crs/OGC/1.3/CRS84 to http://www.opengis.net/def/crs/EPSG/0/4326")
+}
+
+func TestFeatureCollectionTransform(t *testing.T) {
+	transform, err := api.GetCoordinateTransform(api.CRS84, api.EPSG3857)
+	require.NoError(t, err)
+
+	point := &geometry.Point{Coordinates: []float64{90, 0}}
+	collection := &api.FeatureCollection{
+		Features: []*api.Feature{
+			{Id: "a", Geometry: point, Properties: map[string]any{}},
+			{Id: "b", Properties: map[string]any{}},
+		},
+		Transform: transform,
+	}
+
+	data, err := json.Marshal(collection)
+	require.NoError(t, err)
+
+	decoded := &api.FeatureCollection{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	require.Len(t, decoded.Features, 2)
+	coordinates := decoded.Features[0].Geometry.(*geometry.Point).Coordinates
+	assert.InDelta(t, 10018754.171394622, coordinates[0], 1e-6)
+	assert.InDelta(t, 0, coordinates[1], 1e-6)
+	assert.Nil(t, decoded.Features[1].Geometry)
+
+	// the original features are not modified
+	assert.Equal(t, []float64{90, 0}, point.Coordinates)
+	assert.Same(t, point, collection.Features[0].Geometry)
+}
@@ -0,0 +1,223 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ExceptionMediaType is the media type of JSON exception documents (RFC 7807 problem details).
+const ExceptionMediaType = "application/problem+json"
+
+// Exception is an OGC API exception document.  The members are the problem details from RFC 7807.
+type Exception struct {
+	// Type is a URI reference that identifies the problem type.  If empty, the type is
+	// "about:blank" and the title should be the HTTP status text.
+	Type string `json:"type,omitempty"`
+
+	// Title is a short summary of the problem type.
+	Title string `json:"title,omitempty"`
+
+	// Status is the HTTP status code.
+	Status int `json:"status,omitempty"`
+
+	// Detail is an explanation specific to this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is a URI reference that identifies this occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+}
+
+var _ error = (*Exception)(nil)
+
+func (e *Exception) Error() string {
+	if e.Detail == "" {
+		return e.Title
+	}
+	return e.Title + ": " + e.Detail
+}
+
+// NewException creates an exception with the HTTP status text as the title.
+func NewException(status int, detail string) *Exception {
+	return &Exception{Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// InvalidParameter creates an exception for a query parameter that is not valid.
+func InvalidParameter(name string, detail string) *Exception {
+	return &Exception{
+		Title:  "Invalid parameter",
+		Status: http.StatusBadRequest,
+		Detail: (&InvalidParameterError{Parameter: name, Detail: detail}).Error(),
+	}
+}
+
+// NotFound creates an exception for a resource that does not exist.
+func NotFound(detail string) *Exception {
+	return NewException(http.StatusNotFound, detail)
+}
+
+// UnsupportedMediaType creates an exception for a request body with a media type that is not
+// supported.
+func UnsupportedMediaType(mediaType string, supported ...string) *Exception {
+	detail := fmt.Sprintf("unsupported media type %q", mediaType)
+	if len(supported) > 0 {
+		detail += fmt.Sprintf(", expected one of %s", strings.Join(supported, ", "))
+	}
+	return NewException(http.StatusUnsupportedMediaType, detail)
+}
+
+// ExceptionFromError returns an exception for an error.  An *InvalidParameterError is a bad
+// request and ErrPreconditionFailed is a failed precondition.  Other errors are internal server
+// errors, and their messages are not included in the exception.
+func ExceptionFromError(err error) *Exception {
+	exception := &Exception{}
+	if errors.As(err, &exception) {
+		return exception
+	}
+
+	paramErr := &InvalidParameterError{}
+	if errors.As(err, &paramErr) {
+		return InvalidParameter(paramErr.Parameter, paramErr.Detail)
+	}
+
+	if errors.Is(err, ErrPreconditionFailed) {
+		return NewException(http.StatusPreconditionFailed, "the resource has been modified")
+	}
+
+	return NewException(http.StatusInternalServerError, "")
+}
+
+var exceptionTemplate = template.Must(template.New("exception").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Status }} {{ .Title }}</title>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{ if .Detail }}<p>{{ .Detail }}</p>
+{{ end }}</body>
+</html>
+`))
+
+// HTML returns an HTML page describing the exception.
+func (e *Exception) HTML() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	if err := exceptionTemplate.Execute(buffer, e); err != nil {
+		return nil, fmt.Errorf("trouble rendering exception: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// NegotiateExceptionMediaType returns the media type to use for an exception in response to a
+// request.  An "f" query parameter with "html" or "json" takes precedence over the Accept header.
+// HTML is only used if it is preferred to JSON.  The result is ExceptionMediaType or "text/html".
+func NegotiateExceptionMediaType(r *http.Request) string {
+	switch r.URL.Query().Get(formatParam) {
+	case "html":
+		return "text/html"
+	case "json":
+		return ExceptionMediaType
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return ExceptionMediaType
+	}
+	htmlQuality := acceptQuality(accept, "text/html")
+	jsonQuality := max(acceptQuality(accept, ExceptionMediaType), acceptQuality(accept, "application/json"))
+	if htmlQuality > jsonQuality {
+		return "text/html"
+	}
+	return ExceptionMediaType
+}
+
+// acceptQuality returns the quality value for a media type from the most specific matching media
+// range in an Accept header.
+func acceptQuality(accept string, mediaType string) float64 {
+	kind, _, _ := strings.Cut(mediaType, "/")
+
+	quality := 0.0
+	specificity := -1
+	for _, mediaRange := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		match := -1
+		switch rangeType {
+		case mediaType:
+			match = 2
+		case kind + "/*":
+			match = 1
+		case "*/*":
+			match = 0
+		}
+		if match <= specificity {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		specificity = match
+		quality = q
+	}
+	return quality
+}
+
+// WriteException writes an exception as JSON or HTML depending on the request.
+func WriteException(w http.ResponseWriter, r *http.Request, exception *Exception) error {
+	status := exception.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	if NegotiateExceptionMediaType(r) == "text/html" {
+		data, err := exception.HTML()
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(status)
+		_, err = w.Write(data)
+		return err
+	}
+
+	data, err := json.Marshal(exception)
+	if err != nil {
+		return fmt.Errorf("trouble encoding exception: %w", err)
+	}
+	w.Header().Set("Content-Type", ExceptionMediaType)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,183 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExceptionJSON(t *testing.T) {
+	exception := api.InvalidParameter("limit", "expected a positive integer")
+	exception.Instance = "/collections/a/items"
+
+	data, err := json.Marshal(exception)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"title": "Invalid parameter",
+		"status": 400,
+		"detail": "invalid limit parameter: expected a positive integer",
+		"instance": "/collections/a/items"
+	}`, string(data))
+
+	decoded := &api.Exception{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, exception, decoded)
+}
+
+func TestExceptionHelpers(t *testing.T) {
+	cases := []struct {
+		name      string
+		exception *api.Exception
+		expected  *api.Exception
+	}{
+		{
+			name:      "not found",
+			exception: api.NotFound(`no collection with id "a"`),
+			expected:  &api.Exception{Title: "Not Found", Status: 404, Detail: `no collection with id "a"`},
+		},
+		{
+			name:      "unsupported media type",
+			exception: api.UnsupportedMediaType("text/plain", "application/geo+json", "application/json"),
+			expected: &api.Exception{
+				Title:  "Unsupported Media Type",
+				Status: 415,
+				Detail: `unsupported media type "text/plain", expected one of application/geo+json, application/json`,
+			},
+		},
+		{
+			name:      "new exception",
+			exception: api.NewException(http.StatusConflict, ""),
+			expected:  &api.Exception{Title: "Conflict", Status: 409},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, c.exception)
+		})
+	}
+}
+
+func TestExceptionFromError(t *testing.T) {
+	notFound := api.NotFound("missing")
+
+	cases := []struct {
+		name     string
+		err      error
+		expected *api.Exception
+	}{
+		{
+			name:     "exception",
+			err:      notFound,
+			expected: notFound,
+		},
+		{
+			name:     "wrapped exception",
+			err:      fmt.Errorf("trouble: %w", notFound),
+			expected: notFound,
+		},
+		{
+			name: "invalid parameter",
+			err:  &api.InvalidParameterError{Parameter: "bbox", Detail: "expected 4 or 6 values, got 3"},
+			expected: &api.Exception{
+				Title:  "Invalid parameter",
+				Status: 400,
+				Detail: "invalid bbox parameter: expected 4 or 6 values, got 3",
+			},
+		},
+		{
+			name:     "precondition failed",
+			err:      api.ErrPreconditionFailed,
+			expected: &api.Exception{Title: "Precondition Failed", Status: 412, Detail: "the resource has been modified"},
+		},
+		{
+			name:     "other error",
+			err:      errors.New("database password is hunter2"),
+			expected: &api.Exception{Title: "Internal Server Error", Status: 500},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, api.ExceptionFromError(c.err))
+		})
+	}
+}
+
+func TestNegotiateExceptionMediaType(t *testing.T) {
+	cases := []struct {
+		name     string
+		url      string
+		accept   string
+		expected string
+	}{
+		{name: "no accept", url: "/", expected: api.ExceptionMediaType},
+		{name: "json", url: "/", accept: "application/json", expected: api.ExceptionMediaType},
+		{name: "html", url: "/", accept: "text/html", expected: "text/html"},
+		{
+			name:     "browser",
+			url:      "/",
+			accept:   "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			expected: "text/html",
+		},
+		{name: "json preferred", url: "/", accept: "text/html;q=0.5, application/json", expected: api.ExceptionMediaType},
+		{name: "tie", url: "/", accept: "text/html, application/json", expected: api.ExceptionMediaType},
+		{name: "any", url: "/", accept: "*/*", expected: api.ExceptionMediaType},
+		{name: "html excluded", url: "/", accept: "text/*;q=0.1, text/html;q=0, */*", expected: api.ExceptionMediaType},
+		{name: "f html", url: "/?f=html", accept: "application/json", expected: "text/html"},
+		{name: "f json", url: "/?f=json", accept: "text/html", expected: api.ExceptionMediaType},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, c.url, nil)
+			if c.accept != "" {
+				r.Header.Set("Accept", c.accept)
+			}
+			assert.Equal(t, c.expected, api.NegotiateExceptionMediaType(r))
+		})
+	}
+}
+
+func TestWriteException(t *testing.T) {
+	exception := api.NotFound(`no tileset with id "<script>"`)
+
+	r := httptest.NewRequest(http.MethodGet, "/tiles/x", nil)
+	w := httptest.NewRecorder()
+	require.NoError(t, api.WriteException(w, r, exception))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, api.ExceptionMediaType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"title": "Not Found", "status": 404, "detail": "no tileset with id \"<script>\""}`, w.Body.String())
+
+	r = httptest.NewRequest(http.MethodGet, "/tiles/x", nil)
+	r.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	require.NoError(t, api.WriteException(w, r, exception))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<h1>Not Found</h1>")
+	assert.Contains(t, w.Body.String(), "no tileset with id &#34;&lt;script&gt;&#34;")
+}
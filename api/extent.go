@@ -0,0 +1,440 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// contains reports whether the other interval is within this one.
+func (i *TimeInterval) contains(other TimeInterval) bool {
+	if i.Start != nil && (other.Start == nil || other.Start.Before(*i.Start)) {
+		return false
+	}
+	if i.End != nil && (other.End == nil || other.End.After(*i.End)) {
+		return false
+	}
+	return true
+}
+
+// union returns the interval that covers this one and the other.
+func (i *TimeInterval) union(other TimeInterval) TimeInterval {
+	union := TimeInterval{}
+	if i.Start != nil && other.Start != nil {
+		union.Start = i.Start
+		if other.Start.Before(*i.Start) {
+			union.Start = other.Start
+		}
+	}
+	if i.End != nil && other.End != nil {
+		union.End = i.End
+		if other.End.After(*i.End) {
+			union.End = other.End
+		}
+	}
+	return union
+}
+
+// NewTemporalExtent creates a temporal extent from intervals.  Per OGC API - Common, the first
+// interval should be the overall extent and any others describe it in more detail.
+func NewTemporalExtent(intervals ...TimeInterval) *TemporalExtent {
+	extent := &TemporalExtent{Interval: make([][]any, len(intervals))}
+	for i, interval := range intervals {
+		extent.Interval[i] = []any{formatExtentTime(interval.Start), formatExtentTime(interval.End)}
+	}
+	return extent
+}
+
+// formatExtentTime formats an interval start or end.  OGC API - Common uses null for an open
+// start or end.
+func formatExtentTime(value *time.Time) any {
+	if value == nil {
+		return nil
+	}
+	return formatIntervalTime(value)
+}
+
+// Intervals returns the typed intervals of the extent.  Interval bounds may be RFC 3339 strings,
+// time.Time values, or null (or "..") for an unbounded start or end.
+func (e *TemporalExtent) Intervals() ([]TimeInterval, error) {
+	intervals := make([]TimeInterval, len(e.Interval))
+	for i, values := range e.Interval {
+		if len(values) != 2 {
+			return nil, fmt.Errorf("expected 2 items for interval %d, got %d", i, len(values))
+		}
+		start, err := parseExtentTime(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid start for interval %d: %w", i, err)
+		}
+		end, err := parseExtentTime(values[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid end for interval %d: %w", i, err)
+		}
+		intervals[i] = TimeInterval{Start: start, End: end}
+	}
+	return intervals, nil
+}
+
+func parseExtentTime(value any) (*time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &v, nil
+	case *time.Time:
+		return v, nil
+	case string:
+		if v == openBound {
+			return nil, nil
+		}
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC 3339 timestamp, got %q", v)
+		}
+		return &parsed, nil
+	}
+	return nil, fmt.Errorf("expected a string or null, got %v", value)
+}
+
+// Validate checks that each interval has a start before its end and that the first interval
+// contains all of the others.
+func (e *TemporalExtent) Validate() error {
+	intervals, err := e.Intervals()
+	if err != nil {
+		return err
+	}
+	if len(intervals) == 0 {
+		return errors.New("expected at least one interval")
+	}
+	for i, interval := range intervals {
+		if interval.Start == nil && interval.End == nil {
+			// an extent may be unbounded on both sides
+			continue
+		}
+		if err := interval.validate(); err != nil {
+			return fmt.Errorf("invalid interval %d: %w", i, err)
+		}
+	}
+	for i, interval := range intervals[1:] {
+		if !intervals[0].contains(interval) {
+			return fmt.Errorf("interval %d is not within the overall extent (the first interval)", i+1)
+		}
+	}
+	return nil
+}
+
+// UnionTemporalExtents returns a temporal extent with a single interval that covers all of the
+// intervals in the given extents.  Nil extents are ignored.  The extents must use the same
+// temporal reference system.
+func UnionTemporalExtents(extents ...*TemporalExtent) (*TemporalExtent, error) {
+	var overall *TimeInterval
+	trs := ""
+	seen := false
+	for i, extent := range extents {
+		if extent == nil {
+			continue
+		}
+		if seen && extent.Trs != trs {
+			return nil, fmt.Errorf("extent %d has a different temporal reference system", i)
+		}
+		trs = extent.Trs
+		seen = true
+
+		intervals, err := extent.Intervals()
+		if err != nil {
+			return nil, fmt.Errorf("invalid extent %d: %w", i, err)
+		}
+		for _, interval := range intervals {
+			if overall == nil {
+				first := interval
+				overall = &first
+				continue
+			}
+			union := overall.union(interval)
+			overall = &union
+		}
+	}
+	if overall == nil {
+		return nil, errors.New("expected at least one interval")
+	}
+
+	union := NewTemporalExtent(*overall)
+	union.Trs = trs
+	return union, nil
+}
+
+// bbox is a parsed bounding box.  Boxes that cross the antimeridian have minX > maxX.
+type bbox struct {
+	minX, minY, maxX, maxY float64
+	minZ, maxZ             float64
+	hasZ                   bool
+}
+
+func parseBbox(values []float64) (*bbox, error) {
+	switch len(values) {
+	case 4:
+		return &bbox{minX: values[0], minY: values[1], maxX: values[2], maxY: values[3]}, nil
+	case 6:
+		return &bbox{
+			minX: values[0], minY: values[1], minZ: values[2],
+			maxX: values[3], maxY: values[4], maxZ: values[5],
+			hasZ: true,
+		}, nil
+	}
+	return nil, fmt.Errorf("expected 4 or 6 values, got %d", len(values))
+}
+
+func (b *bbox) values() []float64 {
+	if b.hasZ {
+		return []float64{b.minX, b.minY, b.minZ, b.maxX, b.maxY, b.maxZ}
+	}
+	return []float64{b.minX, b.minY, b.maxX, b.maxY}
+}
+
+func (b *bbox) validate(geographic bool) error {
+	if b.minY > b.maxY {
+		return fmt.Errorf("min y %g is greater than max y %g", b.minY, b.maxY)
+	}
+	if b.hasZ && b.minZ > b.maxZ {
+		return fmt.Errorf("min z %g is greater than max z %g", b.minZ, b.maxZ)
+	}
+	if !geographic {
+		if b.minX > b.maxX {
+			return fmt.Errorf("min x %g is greater than max x %g", b.minX, b.maxX)
+		}
+		return nil
+	}
+	if b.minX < -180 || b.minX > 180 || b.maxX < -180 || b.maxX > 180 {
+		return errors.New("longitude out of range [-180, 180]")
+	}
+	if b.minY < -90 || b.maxY > 90 {
+		return errors.New("latitude out of range [-90, 90]")
+	}
+	return nil
+}
+
+// lonRanges returns the longitude ranges covered by the box, splitting boxes that cross the
+// antimeridian.
+func (b *bbox) lonRanges() [][2]float64 {
+	if b.minX > b.maxX {
+		return [][2]float64{{b.minX, 180}, {-180, b.maxX}}
+	}
+	return [][2]float64{{b.minX, b.maxX}}
+}
+
+// contains reports whether the other box is within this one.
+func (b *bbox) contains(other *bbox) bool {
+	if other.minY < b.minY || other.maxY > b.maxY {
+		return false
+	}
+	if b.hasZ && other.hasZ && (other.minZ < b.minZ || other.maxZ > b.maxZ) {
+		return false
+	}
+	ranges := b.lonRanges()
+	for _, r := range other.lonRanges() {
+		covered := false
+		for _, c := range ranges {
+			if r[0] >= c[0] && r[1] <= c[1] {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// UnionBboxes returns the smallest box that covers all of the given boxes.  Longitudes are
+// treated as geographic, so the union crosses the antimeridian (minX > maxX) when that covers
+// less than the box that does not.  Boxes that cross the antimeridian as input are also
+// supported.  The result has six values if all boxes have six values and four values otherwise.
+func UnionBboxes(boxes ...[]float64) ([]float64, error) {
+	if len(boxes) == 0 {
+		return nil, errors.New("expected at least one bbox")
+	}
+
+	parsed := make([]*bbox, len(boxes))
+	for i, values := range boxes {
+		b, err := parseBbox(values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox %d: %w", i, err)
+		}
+		parsed[i] = b
+	}
+	return unionBboxes(parsed).values(), nil
+}
+
+func unionBboxes(boxes []*bbox) *bbox {
+	union := &bbox{
+		minY: math.Inf(1), maxY: math.Inf(-1),
+		minZ: math.Inf(1), maxZ: math.Inf(-1),
+		hasZ: true,
+	}
+	ranges := [][2]float64{}
+	for _, b := range boxes {
+		union.minY = math.Min(union.minY, b.minY)
+		union.maxY = math.Max(union.maxY, b.maxY)
+		if b.hasZ {
+			union.minZ = math.Min(union.minZ, b.minZ)
+			union.maxZ = math.Max(union.maxZ, b.maxZ)
+		} else {
+			union.hasZ = false
+		}
+		ranges = append(ranges, b.lonRanges()...)
+	}
+	if !union.hasZ {
+		union.minZ, union.maxZ = 0, 0
+	}
+	union.minX, union.maxX = unionLonRanges(ranges)
+	return union
+}
+
+// unionLonRanges returns the smallest longitude range that covers all of the ranges.  The
+// result leaves out the largest gap between the merged ranges.  If the largest gap includes the
+// antimeridian, the result does not cross it.
+func unionLonRanges(ranges [][2]float64) (float64, float64) {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i][0] < ranges[j][0]
+	})
+
+	merged := [][2]float64{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1] {
+			last[1] = math.Max(last[1], r[1])
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	first := merged[0]
+	last := merged[len(merged)-1]
+
+	// the gap across the antimeridian is preferred when gaps are equal
+	minX, maxX := first[0], last[1]
+	largestGap := (first[0] + 180) + (180 - last[1])
+	for i := 1; i < len(merged); i++ {
+		gap := merged[i][0] - merged[i-1][1]
+		if gap > largestGap {
+			largestGap = gap
+			minX, maxX = merged[i][0], merged[i-1][1]
+		}
+	}
+	return minX, maxX
+}
+
+// NewSpatialExtent creates a spatial extent from the bounds of the feature geometries.  Features
+// without a geometry are skipped.  Feature coordinates are assumed to be longitude and latitude,
+// and the extent crosses the antimeridian if that gives a smaller box.
+func NewSpatialExtent(features []*Feature) (*SpatialExtent, error) {
+	boxes := []*bbox{}
+	for i, feature := range features {
+		if feature == nil || feature.Geometry == nil {
+			continue
+		}
+		bounds := feature.Geometry.Bounds()
+		if bounds == nil {
+			continue
+		}
+		b, err := parseBbox(bounds)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bounds for feature %d: %w", i, err)
+		}
+		boxes = append(boxes, b)
+	}
+	if len(boxes) == 0 {
+		return nil, errors.New("expected at least one feature with a geometry")
+	}
+	return &SpatialExtent{Bbox: [][]float64{unionBboxes(boxes).values()}}, nil
+}
+
+func (e *SpatialExtent) geographic() bool {
+	return isGeographicCRS(e.Crs)
+}
+
+// Validate checks that each bbox is well formed and that the first bbox contains all of the
+// others.  If the extent uses CRS84 (the default) or CRS84h, longitudes and latitudes must be in
+// range and boxes may cross the antimeridian.
+func (e *SpatialExtent) Validate() error {
+	if len(e.Bbox) == 0 {
+		return errors.New("expected at least one bbox")
+	}
+	geographic := e.geographic()
+	boxes := make([]*bbox, len(e.Bbox))
+	for i, values := range e.Bbox {
+		b, err := parseBbox(values)
+		if err != nil {
+			return fmt.Errorf("invalid bbox %d: %w", i, err)
+		}
+		if err := b.validate(geographic); err != nil {
+			return fmt.Errorf("invalid bbox %d: %w", i, err)
+		}
+		boxes[i] = b
+	}
+	for i, b := range boxes[1:] {
+		if !boxes[0].contains(b) {
+			return fmt.Errorf("bbox %d is not within the overall extent (the first bbox)", i+1)
+		}
+	}
+	return nil
+}
+
+// UnionSpatialExtents returns a spatial extent with a single bbox that covers all of the boxes in
+// the given extents.  Nil extents are ignored.  The extents must use the same CRS, and only
+// CRS84 (or CRS84h) extents can be combined across the antimeridian.
+func UnionSpatialExtents(extents ...*SpatialExtent) (*SpatialExtent, error) {
+	boxes := []*bbox{}
+	crs := ""
+	seen := false
+	for i, extent := range extents {
+		if extent == nil {
+			continue
+		}
+		if seen && extent.Crs != crs {
+			return nil, fmt.Errorf("extent %d has a different CRS", i)
+		}
+		crs = extent.Crs
+		seen = true
+
+		for j, values := range extent.Bbox {
+			b, err := parseBbox(values)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bbox %d in extent %d: %w", j, i, err)
+			}
+			boxes = append(boxes, b)
+		}
+	}
+	if len(boxes) == 0 {
+		return nil, errors.New("expected at least one bbox")
+	}
+
+	union := unionBboxes(boxes)
+	if !(&SpatialExtent{Crs: crs}).geographic() {
+		union.minX, union.maxX = math.Inf(1), math.Inf(-1)
+		for _, b := range boxes {
+			union.minX = math.Min(union.minX, b.minX)
+			union.maxX = math.Max(union.maxX, b.maxX)
+		}
+	}
+	return &SpatialExtent{Bbox: [][]float64{union.values()}, Crs: crs}, nil
+}
@@ -0,0 +1,334 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemporalExtent(t *testing.T) {
+	start := timePointer(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	middle := timePointer(time.Date(2021, 6, 1, 12, 30, 0, 500000000, time.UTC))
+
+	extent := api.NewTemporalExtent(
+		api.TimeInterval{Start: start},
+		api.TimeInterval{Start: start, End: middle},
+	)
+	require.NoError(t, extent.Validate())
+
+	data, err := json.Marshal(extent)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"interval": [["2020-01-01T00:00:00Z", null], ["2020-01-01T00:00:00Z", "2021-06-01T12:30:00.5Z"]]}`, string(data))
+
+	decoded := &api.TemporalExtent{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	intervals, err := decoded.Intervals()
+	require.NoError(t, err)
+	assert.Equal(t, []api.TimeInterval{{Start: start}, {Start: start, End: middle}}, intervals)
+}
+
+func TestTemporalExtentIntervals(t *testing.T) {
+	offset := time.Date(2020, 1, 1, 2, 0, 0, 0, time.FixedZone("", 2*60*60))
+	extent := &api.TemporalExtent{Interval: [][]any{{"..", "2020-01-01T02:00:00+02:00"}, {offset, nil}}}
+
+	intervals, err := extent.Intervals()
+	require.NoError(t, err)
+	require.Len(t, intervals, 2)
+	assert.Nil(t, intervals[0].Start)
+	assert.True(t, offset.Equal(*intervals[0].End))
+	assert.True(t, offset.Equal(*intervals[1].Start))
+	assert.Nil(t, intervals[1].End)
+}
+
+func TestTemporalExtentValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		interval [][]any
+		err      string
+	}{
+		{
+			name:     "empty",
+			interval: [][]any{},
+			err:      "expected at least one interval",
+		},
+		{
+			name:     "wrong length",
+			interval: [][]any{{"2020-01-01T00:00:00Z"}},
+			err:      "expected 2 items for interval 0, got 1",
+		},
+		{
+			name:     "invalid time",
+			interval: [][]any{{"2020-01-01", nil}},
+			err:      `invalid start for interval 0: expected an RFC 3339 timestamp, got "2020-01-01"`,
+		},
+		{
+			name:     "reversed",
+			interval: [][]any{{"2021-01-01T00:00:00Z", "2020-01-01T00:00:00Z"}},
+			err:      "invalid interval 0: interval start 2021-01-01T00:00:00Z is after end 2020-01-01T00:00:00Z",
+		},
+		{
+			name:     "first is not overall",
+			interval: [][]any{{"2020-01-01T00:00:00Z", "2020-12-31T00:00:00Z"}, {"2020-06-01T00:00:00Z", nil}},
+			err:      "interval 1 is not within the overall extent (the first interval)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			extent := &api.TemporalExtent{Interval: tc.interval}
+			assert.EqualError(t, extent.Validate(), tc.err)
+		})
+	}
+}
+
+func TestUnionTemporalExtents(t *testing.T) {
+	cases := []struct {
+		name     string
+		extents  []*api.TemporalExtent
+		expected string
+	}{
+		{
+			name: "closed",
+			extents: []*api.TemporalExtent{
+				{Interval: [][]any{{"2020-01-01T00:00:00Z", "2020-06-01T00:00:00Z"}}},
+				{Interval: [][]any{{"2019-01-01T00:00:00Z", "2019-06-01T00:00:00Z"}}},
+				nil,
+			},
+			expected: `{"interval": [["2019-01-01T00:00:00Z", "2020-06-01T00:00:00Z"]]}`,
+		},
+		{
+			name: "open end",
+			extents: []*api.TemporalExtent{
+				{Interval: [][]any{{"2020-01-01T00:00:00Z", nil}}, Trs: "http://www.opengis.net/def/uom/ISO-8601/0/Gregorian"},
+				{Interval: [][]any{{"2018-01-01T00:00:00Z", "2019-06-01T00:00:00Z"}, {"2018-01-01T00:00:00Z", "2018-02-01T00:00:00Z"}}, Trs: "http://www.opengis.net/def/uom/ISO-8601/0/Gregorian"},
+			},
+			expected: `{"interval": [["2018-01-01T00:00:00Z", null]], "trs": "http://www.opengis.net/def/uom/ISO-8601/0/Gregorian"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			union, err := api.UnionTemporalExtents(tc.extents...)
+			require.NoError(t, err)
+			data, err := json.Marshal(union)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.expected, string(data))
+		})
+	}
+}
+
+func TestUnionTemporalExtentsErrors(t *testing.T) {
+	_, err := api.UnionTemporalExtents(nil)
+	assert.EqualError(t, err, "expected at least one interval")
+
+	_, err = api.UnionTemporalExtents(
+		&api.TemporalExtent{Interval: [][]any{{"2020-01-01T00:00:00Z", nil}}},
+		&api.TemporalExtent{Interval: [][]any{{"2020-01-01T00:00:00Z", nil}}, Trs: "other"},
+	)
+	assert.EqualError(t, err, "extent 1 has a different temporal reference system")
+}
+
+func TestUnionBboxes(t *testing.T) {
+	cases := []struct {
+		name     string
+		boxes    [][]float64
+		expected []float64
+	}{
+		{
+			name:     "single",
+			boxes:    [][]float64{{1, 2, 3, 4}},
+			expected: []float64{1, 2, 3, 4},
+		},
+		{
+			name:     "disjoint",
+			boxes:    [][]float64{{-10, -5, 10, 5}, {100, 20, 120, 30}},
+			expected: []float64{-10, -5, 120, 30},
+		},
+		{
+			name:     "either side of the antimeridian",
+			boxes:    [][]float64{{170, -20, 178, -10}, {-178, -15, -170, -5}},
+			expected: []float64{170, -20, -170, -5},
+		},
+		{
+			name:     "crossing input",
+			boxes:    [][]float64{{170, -20, -170, -10}, {160, 0, 165, 5}},
+			expected: []float64{160, -20, -170, 5},
+		},
+		{
+			name:     "crossing input within another",
+			boxes:    [][]float64{{170, -20, -170, -10}, {-180, -30, 180, 30}},
+			expected: []float64{-180, -30, 180, 30},
+		},
+		{
+			name:     "crossing inputs overlap everywhere",
+			boxes:    [][]float64{{0, 0, -10, 1}, {-20, 0, 5, 1}},
+			expected: []float64{-180, 0, 180, 1},
+		},
+		{
+			name:     "touching the antimeridian",
+			boxes:    [][]float64{{170, 0, 180, 1}, {-180, 0, -170, 1}},
+			expected: []float64{170, 0, -170, 1},
+		},
+		{
+			name:     "3d",
+			boxes:    [][]float64{{0, 0, -10, 1, 1, 10}, {2, 2, 0, 3, 3, 100}},
+			expected: []float64{0, 0, -10, 3, 3, 100},
+		},
+		{
+			name:     "mixed dimensions",
+			boxes:    [][]float64{{0, 0, -10, 1, 1, 10}, {2, 2, 3, 3}},
+			expected: []float64{0, 0, 3, 3},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			union, err := api.UnionBboxes(tc.boxes...)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, union)
+		})
+	}
+}
+
+func TestUnionBboxesErrors(t *testing.T) {
+	_, err := api.UnionBboxes()
+	assert.EqualError(t, err, "expected at least one bbox")
+
+	_, err = api.UnionBboxes([]float64{0, 0, 1, 1}, []float64{1, 2, 3})
+	assert.EqualError(t, err, "invalid bbox 1: expected 4 or 6 values, got 3")
+}
+
+func TestNewSpatialExtent(t *testing.T) {
+	features := []*api.Feature{
+		{Geometry: &geometry.Point{Coordinates: []float64{179, -17}}},
+		{Geometry: nil},
+		{Geometry: &geometry.LineString{Coordinates: [][]float64{{-179.5, -18}, {-178, -16}}}},
+	}
+
+	extent, err := api.NewSpatialExtent(features)
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{179, -18, -178, -16}}, extent.Bbox)
+	assert.NoError(t, extent.Validate())
+
+	_, err = api.NewSpatialExtent([]*api.Feature{{}})
+	assert.EqualError(t, err, "expected at least one feature with a geometry")
+}
+
+func TestSpatialExtentValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		extent *api.SpatialExtent
+		err    string
+	}{
+		{
+			name:   "valid",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{-180, -90, 180, 90}, {170, -10, -170, 10}, {0, 0, 1, 1}}},
+		},
+		{
+			name:   "valid crossing",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{160, -20, -160, 20}, {170, -10, 175, 10}, {-170, 0, -165, 1}}},
+		},
+		{
+			name:   "valid crossing with height",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{160, -20, 0, -160, 20, 100}}, Crs: "http://www.opengis.net/def/crs/OGC/0/CRS84h"},
+		},
+		{
+			name:   "latitude with height",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{0, -91, 0, 1, 1, 100}}, Crs: "http://www.opengis.net/def/crs/OGC/0/CRS84h"},
+			err:    "invalid bbox 0: latitude out of range [-90, 90]",
+		},
+		{
+			name:   "valid projected",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{0, 0, 1000000, 1000000}}, Crs: "http://www.opengis.net/def/crs/EPSG/0/3857"},
+		},
+		{
+			name:   "empty",
+			extent: &api.SpatialExtent{},
+			err:    "expected at least one bbox",
+		},
+		{
+			name:   "latitude",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{0, -91, 1, 1}}},
+			err:    "invalid bbox 0: latitude out of range [-90, 90]",
+		},
+		{
+			name:   "reversed y",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{0, 1, 1, 0}}},
+			err:    "invalid bbox 0: min y 1 is greater than max y 0",
+		},
+		{
+			name:   "reversed x projected",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{10, 0, 0, 1}}, Crs: "http://www.opengis.net/def/crs/EPSG/0/3857"},
+			err:    "invalid bbox 0: min x 10 is greater than max x 0",
+		},
+		{
+			name:   "first is not overall",
+			extent: &api.SpatialExtent{Bbox: [][]float64{{160, -20, -160, 20}, {0, 0, 1, 1}}},
+			err:    "bbox 1 is not within the overall extent (the first bbox)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.extent.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestUnionSpatialExtents(t *testing.T) {
+	union, err := api.UnionSpatialExtents(
+		&api.SpatialExtent{Bbox: [][]float64{{170, -20, 175, -10}}},
+		nil,
+		&api.SpatialExtent{Bbox: [][]float64{{-175, -15, -170, -5}}},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, &api.SpatialExtent{Bbox: [][]float64{{170, -20, -170, -5}}}, union)
+
+	withHeight := "http://www.opengis.net/def/crs/OGC/0/CRS84h"
+	union, err = api.UnionSpatialExtents(
+		&api.SpatialExtent{Bbox: [][]float64{{170, -20, 175, -10}}, Crs: withHeight},
+		&api.SpatialExtent{Bbox: [][]float64{{-175, -15, -170, -5}}, Crs: withHeight},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, &api.SpatialExtent{Bbox: [][]float64{{170, -20, -170, -5}}, Crs: withHeight}, union)
+
+	projected := "http://www.opengis.net/def/crs/EPSG/0/3857"
+	union, err = api.UnionSpatialExtents(
+		&api.SpatialExtent{Bbox: [][]float64{{-100, 0, -50, 10}}, Crs: projected},
+		&api.SpatialExtent{Bbox: [][]float64{{150, 0, 200, 10}}, Crs: projected},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, &api.SpatialExtent{Bbox: [][]float64{{-100, 0, 200, 10}}, Crs: projected}, union)
+
+	_, err = api.UnionSpatialExtents(
+		&api.SpatialExtent{Bbox: [][]float64{{0, 0, 1, 1}}},
+		&api.SpatialExtent{Bbox: [][]float64{{0, 0, 1, 1}}, Crs: projected},
+	)
+	assert.EqualError(t, err, "extent 1 has a different CRS")
+}
@@ -17,10 +17,15 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
+	"strconv"
 
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/planetlabs/go-ogc/geometry"
 )
 
 type Collection struct {
@@ -32,6 +37,13 @@ type Collection struct {
 	ItemType    string      `json:"itemType,omitempty"`
 	Crs         []string    `json:"crs,omitempty"`
 	Extensions  []Extension `json:"-"`
+
+	// StorageCrs is the CRS of the stored coordinates (OGC API - Features - Part 2).
+	StorageCrs string `json:"storageCrs,omitempty"`
+
+	// StorageCrsCoordinateEpoch is the epoch of the storage CRS for dynamic coordinate
+	// reference systems.
+	StorageCrsCoordinateEpoch float64 `json:"storageCrsCoordinateEpoch,omitempty"`
 }
 
 var (
@@ -101,14 +113,53 @@ type CollectionsList struct {
 }
 
 type Feature struct {
-	Id         string         `json:"id,omitempty"`
-	Geometry   any            `json:"geometry"`
-	Properties map[string]any `json:"properties"`
-	Links      []*Link        `json:"links,omitempty"`
-	Extensions []Extension    `json:"-"`
+	Id         string            `json:"id,omitempty"`
+	Geometry   geometry.Geometry `json:"geometry"`
+	Properties map[string]any    `json:"properties"`
+	Links      []*Link           `json:"links,omitempty"`
+
+	// ConformsTo lists conformance classes in addition to the URIs of the extensions.
+	ConformsTo []string `json:"-"`
+
+	// ForeignMembers holds top-level members that are not otherwise part of the feature.  When
+	// decoding, this includes members that were also decoded by extensions.
+	ForeignMembers map[string]any `json:"-"`
+
+	Extensions []Extension `json:"-"`
 }
 
-var _ json.Marshaler = (*Feature)(nil)
+var (
+	_ json.Marshaler          = (*Feature)(nil)
+	_ json.Unmarshaler        = (*Feature)(nil)
+	_ filter.PropertyResolver = (*Feature)(nil)
+)
+
+// ResolveProperty returns the value at a property path.  The "id" and "geometry" paths resolve to
+// the feature id and geometry.  Paths starting with "properties" are resolved against the feature
+// properties object, and any other path is resolved against the members of the properties object.
+func (feature *Feature) ResolveProperty(path filter.PropertyPath) (any, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+
+	switch path[0] {
+	case "id":
+		if len(path) == 1 && feature.Id != "" {
+			return feature.Id, true
+		}
+	case "geometry":
+		if len(path) == 1 && feature.Geometry != nil {
+			return feature.Geometry, true
+		}
+	case "properties":
+		if feature.Properties == nil {
+			return nil, false
+		}
+		return path[1:].Resolve(feature.Properties)
+	}
+
+	return path.Resolve(feature.Properties)
+}
 
 func (feature Feature) MarshalJSON() ([]byte, error) {
 	featureMap := map[string]any{"type": "Feature"}
@@ -125,6 +176,8 @@ func (feature Feature) MarshalJSON() ([]byte, error) {
 		return nil, decodeErr
 	}
 
+	addForeignMembers(featureMap, feature.ForeignMembers)
+
 	extensionUris := []string{}
 	lookup := map[string]bool{}
 
@@ -139,6 +192,13 @@ func (feature Feature) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	for _, uri := range feature.ConformsTo {
+		if !lookup[uri] {
+			extensionUris = append(extensionUris, uri)
+			lookup[uri] = true
+		}
+	}
+
 	if len(extensionUris) > 0 {
 		featureMap["conformsTo"] = extensionUris
 	}
@@ -146,6 +206,64 @@ func (feature Feature) MarshalJSON() ([]byte, error) {
 	return json.Marshal(featureMap)
 }
 
+type decodedFeature struct {
+	Type       string          `json:"type"`
+	Id         any             `json:"id"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+	Links      []*Link         `json:"links"`
+	ConformsTo []string        `json:"conformsTo"`
+}
+
+var featureMembers = []string{"type", "id", "geometry", "properties", "links", "conformsTo"}
+
+// UnmarshalJSON decodes a GeoJSON feature.  A numeric id is converted to a string.  Any
+// extensions on the feature are used to decode the data, except for extensions with a URI that
+// is not listed in a conformsTo member.
+func (feature *Feature) UnmarshalJSON(data []byte) error {
+	d := &decodedFeature{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return err
+	}
+	if d.Type != "Feature" {
+		return fmt.Errorf("expected a Feature, got type %q", d.Type)
+	}
+
+	decoded := &Feature{
+		Properties: d.Properties,
+		Links:      d.Links,
+		ConformsTo: d.ConformsTo,
+		Extensions: feature.Extensions,
+	}
+
+	switch id := d.Id.(type) {
+	case nil:
+	case string:
+		decoded.Id = id
+	case float64:
+		decoded.Id = strconv.FormatFloat(id, 'f', -1, 64)
+	default:
+		return fmt.Errorf("expected a string or number id, got %v", id)
+	}
+
+	if len(d.Geometry) > 0 && !bytes.Equal(d.Geometry, []byte("null")) {
+		g, err := geometry.Unmarshal(d.Geometry)
+		if err != nil {
+			return fmt.Errorf("trouble decoding geometry: %w", err)
+		}
+		decoded.Geometry = g
+	}
+
+	foreignMembers, err := decodeForeignMembers(data, featureMembers)
+	if err != nil {
+		return err
+	}
+	decoded.ForeignMembers = foreignMembers
+
+	*feature = *decoded
+	return decodeExtensions(data, d.ConformsTo, feature.Extensions)
+}
+
 type FeatureCollection struct {
 	Type           string     `json:"type"`
 	Features       []*Feature `json:"features"`
@@ -153,4 +271,158 @@ type FeatureCollection struct {
 	TimeStamp      string     `json:"timeStamp,omitempty"`
 	NumberMatched  int        `json:"numberMatched,omitempty"`
 	NumberReturned int        `json:"numberReturned,omitempty"`
+
+	// CountsKnown is true if NumberMatched is known.  If so, numberMatched and numberReturned
+	// are written even if they are zero (e.g. when no features match a query).
+	CountsKnown bool `json:"-"`
+
+	// ConformsTo lists conformance classes in addition to the URIs of the extensions.
+	ConformsTo []string `json:"-"`
+
+	// ForeignMembers holds top-level members that are not otherwise part of the collection.
+	ForeignMembers map[string]any `json:"-"`
+
+	// Transform is applied to the feature geometries when the collection is encoded.  This can
+	// be used to write coordinates in the CRS requested with the crs query parameter.
+	Transform CoordinateTransform `json:"-"`
+
+	Extensions []Extension `json:"-"`
+}
+
+var (
+	_ json.Marshaler   = (*FeatureCollection)(nil)
+	_ json.Unmarshaler = (*FeatureCollection)(nil)
+)
+
+func (collection FeatureCollection) MarshalJSON() ([]byte, error) {
+	if collection.Transform != nil {
+		collection.Features = transformFeatures(collection.Features, collection.Transform)
+	}
+
+	collectionMap := map[string]any{}
+	decoder, decoderErr := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName: "json",
+		Result:  &collectionMap,
+	})
+	if decoderErr != nil {
+		return nil, decoderErr
+	}
+
+	decodeErr := decoder.Decode(collection)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+	if collection.Type == "" {
+		collectionMap["type"] = "FeatureCollection"
+	}
+	if collection.CountsKnown {
+		collectionMap["numberMatched"] = collection.NumberMatched
+		collectionMap["numberReturned"] = collection.NumberReturned
+	}
+
+	addForeignMembers(collectionMap, collection.ForeignMembers)
+
+	conformsTo := []string{}
+	for _, extension := range collection.Extensions {
+		if err := extension.Encode(collectionMap); err != nil {
+			return nil, fmt.Errorf("trouble encoding feature collection JSON with the %q extension: %w", extension.URI(), err)
+		}
+		if !slices.Contains(conformsTo, extension.URI()) {
+			conformsTo = append(conformsTo, extension.URI())
+		}
+	}
+	for _, uri := range collection.ConformsTo {
+		if !slices.Contains(conformsTo, uri) {
+			conformsTo = append(conformsTo, uri)
+		}
+	}
+	if len(conformsTo) > 0 {
+		collectionMap["conformsTo"] = conformsTo
+	}
+
+	return json.Marshal(collectionMap)
+}
+
+type decodedFeatureCollection struct {
+	Type           string     `json:"type"`
+	Features       []*Feature `json:"features"`
+	Links          []*Link    `json:"links"`
+	TimeStamp      string     `json:"timeStamp"`
+	NumberMatched  *int       `json:"numberMatched"`
+	NumberReturned int        `json:"numberReturned"`
+	ConformsTo     []string   `json:"conformsTo"`
+}
+
+var featureCollectionMembers = []string{"type", "features", "links", "timeStamp", "numberMatched", "numberReturned", "conformsTo"}
+
+// UnmarshalJSON decodes a GeoJSON feature collection.  Extensions on the collection are used to
+// decode the data, except for extensions with a URI that is not listed in a conformsTo member.
+func (collection *FeatureCollection) UnmarshalJSON(data []byte) error {
+	d := &decodedFeatureCollection{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return err
+	}
+	if d.Type != "FeatureCollection" {
+		return fmt.Errorf("expected a FeatureCollection, got type %q", d.Type)
+	}
+
+	foreignMembers, err := decodeForeignMembers(data, featureCollectionMembers)
+	if err != nil {
+		return err
+	}
+
+	*collection = FeatureCollection{
+		Type:           d.Type,
+		Features:       d.Features,
+		Links:          d.Links,
+		TimeStamp:      d.TimeStamp,
+		NumberReturned: d.NumberReturned,
+		CountsKnown:    d.NumberMatched != nil,
+		ConformsTo:     d.ConformsTo,
+		ForeignMembers: foreignMembers,
+		Extensions:     collection.Extensions,
+	}
+	if d.NumberMatched != nil {
+		collection.NumberMatched = *d.NumberMatched
+	}
+	return decodeExtensions(data, d.ConformsTo, collection.Extensions)
+}
+
+// addForeignMembers adds members that are not already set.
+func addForeignMembers(target map[string]any, members map[string]any) {
+	for key, value := range members {
+		if _, exists := target[key]; !exists {
+			target[key] = value
+		}
+	}
+}
+
+// decodeForeignMembers returns the top-level members of an object that are not in the list of
+// known members.  Nil is returned if there are no foreign members.
+func decodeForeignMembers(data []byte, known []string) (map[string]any, error) {
+	members := map[string]any{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for _, key := range known {
+		delete(members, key)
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// decodeExtensions decodes the data with each extension.  If a conformsTo list is provided,
+// extensions with a URI that is not in the list are skipped.
+func decodeExtensions(data []byte, conformsTo []string, extensions []Extension) error {
+	for _, extension := range extensions {
+		if conformsTo != nil && !slices.Contains(conformsTo, extension.URI()) {
+			continue
+		}
+		if err := extension.Decode(data); err != nil {
+			return fmt.Errorf("trouble decoding JSON with the %q extension: %w", extension.URI(), err)
+		}
+	}
+	return nil
 }
@@ -23,6 +23,8 @@ import (
 	"testing"
 
 	"github.com/planetlabs/go-ogc/api"
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/planetlabs/go-ogc/geometry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -110,6 +112,27 @@ func TestFeatureMarshal(t *testing.T) {
 				]
 			}`,
 		},
+		{
+			name: "point geometry",
+			feature: &api.Feature{
+				Id:       "bar",
+				Geometry: &geometry.Point{Coordinates: []float64{-120, 40}},
+				Properties: map[string]interface{}{
+					"one": "foo",
+				},
+			},
+			expected: `{
+				"type": "Feature",
+				"id": "bar",
+				"geometry": {
+					"type": "Point",
+					"coordinates": [-120, 40]
+				},
+				"properties": {
+					"one": "foo"
+				}
+			}`,
+		},
 		{
 			name: "minimal",
 			feature: &api.Feature{
@@ -160,7 +183,21 @@ func (e *FeatureExtension) Encode(featureMap map[string]any) error {
 }
 
 func (e *FeatureExtension) Decode(data []byte) error {
-	return errors.New("not implemented")
+	decoded := &struct {
+		RootFoo    string `json:"test:foo"`
+		Properties struct {
+			NestedBar string `json:"test:bar"`
+		} `json:"properties"`
+	}{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return err
+	}
+	if decoded.RootFoo == "" {
+		return errors.New("missing test:foo")
+	}
+	e.RootFoo = decoded.RootFoo
+	e.NestedBar = decoded.Properties.NestedBar
+	return nil
 }
 
 func (e *FeatureExtension) URI() string {
@@ -254,3 +291,282 @@ func TestCollectionUnmarshalExtension(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{"foo", "bar"}, extension.Classes)
 }
+
+func TestFeatureUnmarshal(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected *api.Feature
+	}{
+		{
+			name: "basic",
+			data: `{
+				"type": "Feature",
+				"id": "foo",
+				"geometry": {"type": "Point", "coordinates": [-120, 40]},
+				"properties": {"one": "foo"},
+				"links": [{"href": "http://example.com/resource.json", "rel": "self"}]
+			}`,
+			expected: &api.Feature{
+				Id:         "foo",
+				Geometry:   &geometry.Point{Coordinates: []float64{-120, 40}},
+				Properties: map[string]any{"one": "foo"},
+				Links:      []*api.Link{{Href: "http://example.com/resource.json", Rel: "self"}},
+			},
+		},
+		{
+			name: "numeric id and null geometry",
+			data: `{"type": "Feature", "id": 42, "geometry": null, "properties": null}`,
+			expected: &api.Feature{
+				Id: "42",
+			},
+		},
+		{
+			name: "foreign members and conformsTo",
+			data: `{
+				"type": "Feature",
+				"geometry": null,
+				"properties": {},
+				"bbox": [1, 2, 3, 4],
+				"custom": {"nested": true},
+				"conformsTo": ["https://example.com/other"]
+			}`,
+			expected: &api.Feature{
+				Properties: map[string]any{},
+				ConformsTo: []string{"https://example.com/other"},
+				ForeignMembers: map[string]any{
+					"bbox":   []any{float64(1), float64(2), float64(3), float64(4)},
+					"custom": map[string]any{"nested": true},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			feature := &api.Feature{}
+			require.NoError(t, json.Unmarshal([]byte(tc.data), feature))
+			assert.Equal(t, tc.expected, feature)
+
+			encoded, err := json.Marshal(feature)
+			require.NoError(t, err)
+			roundTrip := &api.Feature{}
+			require.NoError(t, json.Unmarshal(encoded, roundTrip))
+			assert.Equal(t, tc.expected, roundTrip)
+		})
+	}
+}
+
+func TestFeatureUnmarshalErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "wrong type",
+			data: `{"type": "FeatureCollection", "features": []}`,
+			err:  `expected a Feature, got type "FeatureCollection"`,
+		},
+		{
+			name: "missing type",
+			data: `{"geometry": null, "properties": {}}`,
+			err:  `expected a Feature, got type ""`,
+		},
+		{
+			name: "invalid id",
+			data: `{"type": "Feature", "id": true, "geometry": null, "properties": {}}`,
+			err:  "expected a string or number id, got true",
+		},
+		{
+			name: "invalid geometry",
+			data: `{"type": "Feature", "geometry": {"type": "Point", "coordinates": "nope"}, "properties": {}}`,
+			err:  "trouble decoding geometry: ",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tc.data), &api.Feature{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestFeatureUnmarshalExtension(t *testing.T) {
+	data := `{
+		"type": "Feature",
+		"geometry": null,
+		"properties": {
+			"one": "core-property",
+			"test:bar": "bar-value"
+		},
+		"test:foo": "foo-value",
+		"conformsTo": [
+			"https://example.com/test-extension"
+		]
+	}`
+
+	extension := &FeatureExtension{}
+	feature := &api.Feature{Extensions: []api.Extension{extension}}
+	require.NoError(t, json.Unmarshal([]byte(data), feature))
+	assert.Equal(t, "foo-value", extension.RootFoo)
+	assert.Equal(t, "bar-value", extension.NestedBar)
+	assert.Equal(t, []api.Extension{extension}, feature.Extensions)
+
+	encoded, err := json.Marshal(feature)
+	require.NoError(t, err)
+	assert.JSONEq(t, data, string(encoded))
+}
+
+func TestFeatureUnmarshalExtensionNotConforming(t *testing.T) {
+	data := `{
+		"type": "Feature",
+		"geometry": null,
+		"properties": {},
+		"conformsTo": ["https://example.com/other"]
+	}`
+
+	extension := &FeatureExtension{}
+	feature := &api.Feature{Extensions: []api.Extension{extension}}
+	require.NoError(t, json.Unmarshal([]byte(data), feature))
+	assert.Equal(t, "", extension.RootFoo)
+
+	missing := `{"type": "Feature", "geometry": null, "properties": {}}`
+	err := json.Unmarshal([]byte(missing), feature)
+	assert.EqualError(t, err, `trouble decoding JSON with the "https://example.com/test-extension" extension: missing test:foo`)
+}
+
+func TestFeatureCollectionUnmarshal(t *testing.T) {
+	data := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "id": "a", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"count": 1}},
+			{"type": "Feature", "id": 2, "geometry": null, "properties": {"count": 2}}
+		],
+		"links": [{"href": "http://example.com/items?page=2", "rel": "next"}],
+		"timeStamp": "2023-06-01T00:00:00Z",
+		"numberMatched": 10,
+		"numberReturned": 2,
+		"extra": "value"
+	}`
+
+	collection := &api.FeatureCollection{}
+	require.NoError(t, json.Unmarshal([]byte(data), collection))
+
+	expected := &api.FeatureCollection{
+		Type: "FeatureCollection",
+		Features: []*api.Feature{
+			{Id: "a", Geometry: &geometry.Point{Coordinates: []float64{1, 2}}, Properties: map[string]any{"count": float64(1)}},
+			{Id: "2", Properties: map[string]any{"count": float64(2)}},
+		},
+		Links:          []*api.Link{{Href: "http://example.com/items?page=2", Rel: "next"}},
+		TimeStamp:      "2023-06-01T00:00:00Z",
+		NumberMatched:  10,
+		NumberReturned: 2,
+		CountsKnown:    true,
+		ForeignMembers: map[string]any{"extra": "value"},
+	}
+	assert.Equal(t, expected, collection)
+
+	encoded, err := json.Marshal(collection)
+	require.NoError(t, err)
+	roundTrip := &api.FeatureCollection{}
+	require.NoError(t, json.Unmarshal(encoded, roundTrip))
+	assert.Equal(t, expected, roundTrip)
+}
+
+func TestFeatureCollectionUnmarshalErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "wrong type",
+			data: `{"type": "Feature", "geometry": null, "properties": {}}`,
+			err:  `expected a FeatureCollection, got type "Feature"`,
+		},
+		{
+			name: "invalid feature",
+			data: `{"type": "FeatureCollection", "features": [{"type": "Point", "coordinates": [1, 2]}]}`,
+			err:  `expected a Feature, got type "Point"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := json.Unmarshal([]byte(tc.data), &api.FeatureCollection{})
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestFeatureCollectionMarshal(t *testing.T) {
+	collection := &api.FeatureCollection{
+		Features: []*api.Feature{
+			{Id: "a", Properties: map[string]any{"count": 1}},
+		},
+		NumberReturned: 1,
+		ConformsTo:     []string{"https://example.com/other"},
+	}
+
+	expected := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "id": "a", "geometry": null, "properties": {"count": 1}}
+		],
+		"numberReturned": 1,
+		"conformsTo": ["https://example.com/other"]
+	}`
+
+	actual, err := json.Marshal(collection)
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func TestFeatureResolveProperty(t *testing.T) {
+	point := &geometry.Point{Coordinates: []float64{1, 2}}
+	feature := &api.Feature{
+		Id:       "feature-1",
+		Geometry: point,
+		Properties: map[string]any{
+			"id":             "property-id",
+			"name":           "test",
+			"eo:cloud.cover": 12.5,
+			"eo:bands": []any{
+				map[string]any{"name": "B1"},
+				map[string]any{"name": "B2"},
+			},
+		},
+	}
+
+	cases := []struct {
+		path  string
+		value any
+		found bool
+	}{
+		{path: "id", value: "feature-1", found: true},
+		{path: "geometry", value: point, found: true},
+		{path: "properties.id", value: "property-id", found: true},
+		{path: "name", value: "test", found: true},
+		{path: "/properties/name", value: "test", found: true},
+		{path: "eo:bands.name", value: []any{"B1", "B2"}, found: true},
+		{path: "properties.eo:bands.0.name", value: "B1", found: true},
+		{path: "eo:cloud.cover", value: 12.5, found: true},
+		{path: "properties.eo:cloud.cover", value: 12.5, found: true},
+		{path: "missing"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			path, err := filter.ParsePropertyPath(c.path)
+			require.NoError(t, err)
+
+			value, found := feature.ResolveProperty(path)
+			assert.Equal(t, c.found, found)
+			assert.Equal(t, c.value, value)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// openBound is written for an open interval start or end.
+const openBound = ".."
+
+// TimeInterval is an interval of time.  A nil start or end means the interval is open on that
+// side.
+type TimeInterval struct {
+	Start *time.Time
+	End   *time.Time
+}
+
+// validate checks that the interval has a start or an end and that the start is not after the
+// end.
+func (i *TimeInterval) validate() error {
+	if i.Start == nil && i.End == nil {
+		return errors.New("interval start or end must be provided")
+	}
+	if i.Start != nil && i.End != nil && i.Start.After(*i.End) {
+		return fmt.Errorf("interval start %s is after end %s", formatIntervalTime(i.Start), formatIntervalTime(i.End))
+	}
+	return nil
+}
+
+// formatIntervalTime formats an interval start or end as an RFC 3339 timestamp in UTC.  An open
+// start or end is formatted as "..".
+func formatIntervalTime(value *time.Time) string {
+	if value == nil {
+		return openBound
+	}
+	return value.UTC().Format(time.RFC3339Nano)
+}
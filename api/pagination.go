@@ -0,0 +1,215 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	limitParam  = "limit"
+	offsetParam = "offset"
+	formatParam = "f"
+)
+
+// Pagination describes a page of results.  It is used to set the links and counts of a
+// FeatureCollection.  Pages are selected with an offset unless a TokenParam is provided, in which
+// case the opaque NextToken and PrevToken values are used in the next and prev links.
+type Pagination struct {
+	// Limit is the maximum number of features in a page, including any default limit applied by
+	// the server.  It is written to the limit parameter of the links.  If zero, the limit
+	// parameter is left as it is in the request.  With offset paging, the next and prev links
+	// depend on the limit: without one, a full page has no next link (unless NumberMatched is
+	// known) and the prev link goes to the first page.
+	Limit int
+
+	// Offset is the number of features before this page.
+	Offset int
+
+	// TokenParam is the name of the query parameter with the token that selects a page.
+	TokenParam string
+
+	// NextToken is the token for the next page.  There is no next link if it is empty.
+	NextToken string
+
+	// PrevToken is the token for the previous page.  There is no prev link if it is empty.
+	PrevToken string
+
+	// NumberMatched is the total number of features that match the query, if known.
+	NumberMatched *int
+
+	// MediaType is the media type of the response.  Defaults to application/geo+json.
+	MediaType string
+
+	// Alternates maps other media types of the response to the value of the "f" query
+	// parameter used to request them.
+	Alternates map[string]string
+
+	// TimeStamp is the time the response was generated.  Defaults to now.
+	TimeStamp time.Time
+}
+
+// Apply sets the self, alternate, next, and prev links and the timeStamp, numberMatched, and
+// numberReturned members of a collection.  Links are built from the request URL, and query
+// parameters other than the ones used for paging (like filter, bbox, or datetime) are preserved.
+// Other links on the collection are kept.
+func (p *Pagination) Apply(requestURL *url.URL, collection *FeatureCollection) {
+	links := []*Link{}
+	for _, link := range collection.Links {
+		if !slices.Contains([]string{"self", "alternate", "next", "prev"}, link.Rel) {
+			links = append(links, link)
+		}
+	}
+	collection.Links = append(links, p.Links(requestURL, len(collection.Features))...)
+
+	collection.NumberReturned = len(collection.Features)
+	if p.NumberMatched != nil {
+		collection.NumberMatched = *p.NumberMatched
+		collection.CountsKnown = true
+	}
+
+	timeStamp := p.TimeStamp
+	if timeStamp.IsZero() {
+		timeStamp = time.Now()
+	}
+	collection.TimeStamp = timeStamp.UTC().Format(time.RFC3339)
+}
+
+// Links returns the self, alternate, next, and prev links for a page with the provided number of
+// features.
+func (p *Pagination) Links(requestURL *url.URL, numberReturned int) []*Link {
+	mediaType := p.MediaType
+	if mediaType == "" {
+		mediaType = "application/geo+json"
+	}
+
+	current := p.pageQuery(requestURL)
+	links := []*Link{{Href: withQuery(requestURL, current), Rel: "self", Type: mediaType}}
+
+	formats := make([]string, 0, len(p.Alternates))
+	for alternate := range p.Alternates {
+		if alternate != mediaType {
+			formats = append(formats, alternate)
+		}
+	}
+	sort.Strings(formats)
+	for _, alternate := range formats {
+		query := cloneQuery(current)
+		query.Set(formatParam, p.Alternates[alternate])
+		links = append(links, &Link{Href: withQuery(requestURL, query), Rel: "alternate", Type: alternate})
+	}
+
+	if next, ok := p.nextQuery(current, numberReturned); ok {
+		links = append(links, &Link{Href: withQuery(requestURL, next), Rel: "next", Type: mediaType})
+	}
+	if prev, ok := p.prevQuery(current); ok {
+		links = append(links, &Link{Href: withQuery(requestURL, prev), Rel: "prev", Type: mediaType})
+	}
+	return links
+}
+
+// pageQuery returns the query parameters that select the current page.
+func (p *Pagination) pageQuery(requestURL *url.URL) url.Values {
+	query := requestURL.Query()
+	if p.Limit > 0 {
+		query.Set(limitParam, strconv.Itoa(p.Limit))
+	}
+	if p.TokenParam != "" {
+		return query
+	}
+	if p.Offset > 0 {
+		query.Set(offsetParam, strconv.Itoa(p.Offset))
+	} else {
+		query.Del(offsetParam)
+	}
+	return query
+}
+
+func (p *Pagination) nextQuery(current url.Values, numberReturned int) (url.Values, bool) {
+	query := cloneQuery(current)
+	if p.TokenParam != "" {
+		if p.NextToken == "" {
+			return nil, false
+		}
+		query.Set(p.TokenParam, p.NextToken)
+		return query, true
+	}
+
+	limit := pageLimit(current)
+	next := p.Offset + numberReturned
+	if p.NumberMatched != nil {
+		if next >= *p.NumberMatched {
+			return nil, false
+		}
+	} else if limit <= 0 || numberReturned < limit {
+		return nil, false
+	}
+	query.Set(offsetParam, strconv.Itoa(next))
+	return query, true
+}
+
+func (p *Pagination) prevQuery(current url.Values) (url.Values, bool) {
+	query := cloneQuery(current)
+	if p.TokenParam != "" {
+		if p.PrevToken == "" {
+			return nil, false
+		}
+		query.Set(p.TokenParam, p.PrevToken)
+		return query, true
+	}
+
+	if p.Offset <= 0 {
+		return nil, false
+	}
+	limit := pageLimit(current)
+	prev := p.Offset - limit
+	if limit <= 0 || prev <= 0 {
+		query.Del(offsetParam)
+	} else {
+		query.Set(offsetParam, strconv.Itoa(prev))
+	}
+	return query, true
+}
+
+// pageLimit returns the limit from the query for a page.  The result is zero if there is no valid
+// limit.
+func pageLimit(query url.Values) int {
+	limit, err := strconv.Atoi(query.Get(limitParam))
+	if err != nil || limit < 0 {
+		return 0
+	}
+	return limit
+}
+
+func cloneQuery(query url.Values) url.Values {
+	clone := url.Values{}
+	for key, values := range query {
+		clone[key] = slices.Clone(values)
+	}
+	return clone
+}
+
+func withQuery(base *url.URL, query url.Values) string {
+	u := *base
+	u.RawQuery = query.Encode()
+	u.Fragment = ""
+	return u.String()
+}
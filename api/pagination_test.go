@@ -0,0 +1,281 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func linkHrefs(links []*api.Link) map[string]string {
+	hrefs := map[string]string{}
+	for _, link := range links {
+		key := link.Rel
+		if link.Rel == "alternate" {
+			key = link.Rel + " " + link.Type
+		}
+		hrefs[key] = link.Href
+	}
+	return hrefs
+}
+
+func TestPaginationLinks(t *testing.T) {
+	cases := []struct {
+		name       string
+		url        string
+		pagination *api.Pagination
+		returned   int
+		expected   map[string]string
+	}{
+		{
+			name:       "first page",
+			url:        "https://example.com/collections/a/items?limit=10&bbox=1,2,3,4",
+			pagination: &api.Pagination{Limit: 10, NumberMatched: intPtr(25)},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/collections/a/items?bbox=1%2C2%2C3%2C4&limit=10",
+				"next": "https://example.com/collections/a/items?bbox=1%2C2%2C3%2C4&limit=10&offset=10",
+			},
+		},
+		{
+			name:       "middle page",
+			url:        "https://example.com/items?limit=10&offset=10&datetime=2023-01-01T00:00:00Z%2F..",
+			pagination: &api.Pagination{Limit: 10, Offset: 10, NumberMatched: intPtr(25)},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/items?datetime=2023-01-01T00%3A00%3A00Z%2F..&limit=10&offset=10",
+				"next": "https://example.com/items?datetime=2023-01-01T00%3A00%3A00Z%2F..&limit=10&offset=20",
+				"prev": "https://example.com/items?datetime=2023-01-01T00%3A00%3A00Z%2F..&limit=10",
+			},
+		},
+		{
+			name:       "last page",
+			url:        "https://example.com/items?offset=20",
+			pagination: &api.Pagination{Limit: 10, Offset: 20, NumberMatched: intPtr(25)},
+			returned:   5,
+			expected: map[string]string{
+				"self": "https://example.com/items?limit=10&offset=20",
+				"prev": "https://example.com/items?limit=10&offset=10",
+			},
+		},
+		{
+			name:       "offset less than limit",
+			url:        "https://example.com/items?limit=10&offset=5",
+			pagination: &api.Pagination{Limit: 10, Offset: 5},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/items?limit=10&offset=5",
+				"next": "https://example.com/items?limit=10&offset=15",
+				"prev": "https://example.com/items?limit=10",
+			},
+		},
+		{
+			name:       "default limit",
+			url:        "https://example.com/items?offset=20",
+			pagination: &api.Pagination{Limit: 10, Offset: 20},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/items?limit=10&offset=20",
+				"next": "https://example.com/items?limit=10&offset=30",
+				"prev": "https://example.com/items?limit=10&offset=10",
+			},
+		},
+		{
+			name:       "no limit with matched",
+			url:        "https://example.com/items?offset=20",
+			pagination: &api.Pagination{Offset: 20, NumberMatched: intPtr(40)},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/items?offset=20",
+				"next": "https://example.com/items?offset=30",
+				"prev": "https://example.com/items",
+			},
+		},
+		{
+			name:       "limit from the request",
+			url:        "https://example.com/items?limit=10&offset=20",
+			pagination: &api.Pagination{Offset: 20},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/items?limit=10&offset=20",
+				"next": "https://example.com/items?limit=10&offset=30",
+				"prev": "https://example.com/items?limit=10&offset=10",
+			},
+		},
+		{
+			name:       "no limit",
+			url:        "https://example.com/items?offset=20",
+			pagination: &api.Pagination{Offset: 20},
+			returned:   10,
+			expected: map[string]string{
+				"self": "https://example.com/items?offset=20",
+				"prev": "https://example.com/items",
+			},
+		},
+		{
+			name:       "unknown matched with full page",
+			url:        "https://example.com/items",
+			pagination: &api.Pagination{Limit: 2},
+			returned:   2,
+			expected: map[string]string{
+				"self": "https://example.com/items?limit=2",
+				"next": "https://example.com/items?limit=2&offset=2",
+			},
+		},
+		{
+			name:       "unknown matched with partial page",
+			url:        "https://example.com/items",
+			pagination: &api.Pagination{Limit: 2},
+			returned:   1,
+			expected: map[string]string{
+				"self": "https://example.com/items?limit=2",
+			},
+		},
+		{
+			name:       "filter is preserved",
+			url:        "https://example.com/items?filter=name%20%3D%20%27a%27&filter-lang=cql2-text",
+			pagination: &api.Pagination{Limit: 1, NumberMatched: intPtr(2)},
+			returned:   1,
+			expected: map[string]string{
+				"self": "https://example.com/items?filter=name+%3D+%27a%27&filter-lang=cql2-text&limit=1",
+				"next": "https://example.com/items?filter=name+%3D+%27a%27&filter-lang=cql2-text&limit=1&offset=1",
+			},
+		},
+		{
+			name: "token paging",
+			url:  "https://example.com/items?limit=10&token=abc&bbox=1,2,3,4",
+			pagination: &api.Pagination{
+				Limit:      10,
+				TokenParam: "token",
+				NextToken:  "def",
+				PrevToken:  "xyz",
+			},
+			returned: 10,
+			expected: map[string]string{
+				"self": "https://example.com/items?bbox=1%2C2%2C3%2C4&limit=10&token=abc",
+				"next": "https://example.com/items?bbox=1%2C2%2C3%2C4&limit=10&token=def",
+				"prev": "https://example.com/items?bbox=1%2C2%2C3%2C4&limit=10&token=xyz",
+			},
+		},
+		{
+			name: "last token page",
+			url:  "https://example.com/items?token=abc",
+			pagination: &api.Pagination{
+				TokenParam: "token",
+				PrevToken:  "xyz",
+			},
+			returned: 3,
+			expected: map[string]string{
+				"self": "https://example.com/items?token=abc",
+				"prev": "https://example.com/items?token=xyz",
+			},
+		},
+		{
+			name: "alternates",
+			url:  "/items?limit=5",
+			pagination: &api.Pagination{
+				Limit:         5,
+				NumberMatched: intPtr(5),
+				Alternates: map[string]string{
+					"application/geo+json": "json",
+					"text/html":            "html",
+				},
+			},
+			returned: 5,
+			expected: map[string]string{
+				"self":                "/items?limit=5",
+				"alternate text/html": "/items?f=html&limit=5",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			requestURL, err := url.Parse(c.url)
+			require.NoError(t, err)
+
+			links := c.pagination.Links(requestURL, c.returned)
+			assert.Equal(t, c.expected, linkHrefs(links))
+		})
+	}
+}
+
+func TestPaginationApply(t *testing.T) {
+	requestURL, err := url.Parse("https://example.com/collections/a/items?limit=2&f=json")
+	require.NoError(t, err)
+
+	collection := &api.FeatureCollection{
+		Features: []*api.Feature{{Id: "1"}, {Id: "2"}},
+		Links: []*api.Link{
+			{Href: "https://example.com/collections/a", Rel: "collection", Type: "application/json"},
+			{Href: "https://example.com/old", Rel: "next"},
+		},
+	}
+
+	pagination := &api.Pagination{
+		Limit:         2,
+		NumberMatched: intPtr(3),
+		MediaType:     "application/geo+json",
+		Alternates:    map[string]string{"text/html": "html"},
+		TimeStamp:     time.Date(2023, 6, 1, 12, 0, 0, 0, time.FixedZone("", -7*60*60)),
+	}
+	pagination.Apply(requestURL, collection)
+
+	assert.Equal(t, 3, collection.NumberMatched)
+	assert.True(t, collection.CountsKnown)
+	assert.Equal(t, 2, collection.NumberReturned)
+	assert.Equal(t, "2023-06-01T19:00:00Z", collection.TimeStamp)
+	assert.Equal(t, []*api.Link{
+		{Href: "https://example.com/collections/a", Rel: "collection", Type: "application/json"},
+		{Href: "https://example.com/collections/a/items?f=json&limit=2", Rel: "self", Type: "application/geo+json"},
+		{Href: "https://example.com/collections/a/items?f=html&limit=2", Rel: "alternate", Type: "text/html"},
+		{Href: "https://example.com/collections/a/items?f=json&limit=2&offset=2", Rel: "next", Type: "application/geo+json"},
+	}, collection.Links)
+}
+
+func TestPaginationApplyEmptyPage(t *testing.T) {
+	requestURL, err := url.Parse("https://example.com/items?limit=10")
+	require.NoError(t, err)
+
+	collection := &api.FeatureCollection{Features: []*api.Feature{}}
+	pagination := &api.Pagination{
+		NumberMatched: intPtr(0),
+		TimeStamp:     time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+	pagination.Apply(requestURL, collection)
+
+	data, err := json.Marshal(collection)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "FeatureCollection",
+		"features": [],
+		"links": [{"href": "https://example.com/items?limit=10", "rel": "self", "type": "application/geo+json"}],
+		"timeStamp": "2023-06-01T12:00:00Z",
+		"numberMatched": 0,
+		"numberReturned": 0
+	}`, string(data))
+}
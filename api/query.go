@@ -0,0 +1,359 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/planetlabs/go-ogc/filter"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 10000
+)
+
+// InvalidParameterError is returned when a query parameter cannot be parsed or is not valid.
+type InvalidParameterError struct {
+	// Parameter is the name of the query parameter.
+	Parameter string
+
+	// Detail describes the problem with the value.
+	Detail string
+}
+
+func (e *InvalidParameterError) Error() string {
+	return fmt.Sprintf("invalid %s parameter: %s", e.Parameter, e.Detail)
+}
+
+func invalidParameter(name string, format string, args ...any) *InvalidParameterError {
+	return &InvalidParameterError{Parameter: name, Detail: fmt.Sprintf(format, args...)}
+}
+
+// FeaturesQueryOptions configures the parsing of feature query parameters.
+type FeaturesQueryOptions struct {
+	// DefaultLimit is used when there is no limit parameter.  Defaults to 10.
+	DefaultLimit int
+
+	// MinLimit is the smallest limit.  Smaller limits are increased to this value.  Defaults to 1.
+	MinLimit int
+
+	// MaxLimit is the largest limit.  Larger limits are reduced to this value.  Defaults to 10000.
+	MaxLimit int
+
+	// SupportedCrs lists the CRS that can be used with the crs and bbox-crs parameters.  CRS84
+	// is always supported.
+	SupportedCrs []string
+}
+
+// FeaturesQuery holds the standard query parameters for requests to the items of a collection.
+type FeaturesQuery struct {
+	// Bbox is the bbox parameter with 4 or 6 values in the BboxCrs.  For geographic CRS, the
+	// min x may be greater than the max x for boxes that cross the antimeridian.
+	Bbox []float64
+
+	// BboxCrs is the CRS of the bbox.
+	BboxCrs string
+
+	// Crs is the CRS requested for the response coordinates.
+	Crs string
+
+	// Datetime is the parsed datetime parameter.  For an instant, the start and end are the same.
+	// Dates are parsed as UTC and cover the whole day.
+	Datetime *TimeInterval
+
+	// Limit is the maximum number of features to return.
+	Limit int
+
+	// Properties lists the properties to include in the response.  It is nil if all properties
+	// are requested.
+	Properties []string
+
+	// SkipGeometry is true if geometries should be omitted from the response.
+	SkipGeometry bool
+}
+
+// Instant reports whether the interval is a single instant (e.g. from a datetime parameter with
+// a timestamp).
+func (i *TimeInterval) Instant() bool {
+	return i.Start != nil && i.End != nil && i.Start.Equal(*i.End)
+}
+
+// ParseFeaturesQuery parses the bbox, bbox-crs, crs, datetime, limit, properties, and
+// skipGeometry query parameters.  Errors are returned as an *InvalidParameterError.
+func ParseFeaturesQuery(query url.Values, options *FeaturesQueryOptions) (*FeaturesQuery, error) {
+	if options == nil {
+		options = &FeaturesQueryOptions{}
+	}
+
+	crsQuery, err := ParseCRSQuery(query, options.SupportedCrs)
+	if err != nil {
+		return nil, err
+	}
+	q := &FeaturesQuery{Crs: crsQuery.Crs, BboxCrs: crsQuery.BboxCrs}
+
+	if values, ok := query["bbox"]; ok {
+		b, err := parseBboxParam(first(values), q.BboxCrs)
+		if err != nil {
+			return nil, err
+		}
+		q.Bbox = b
+	}
+
+	if values, ok := query["datetime"]; ok {
+		datetime, err := parseDatetimeParam(first(values))
+		if err != nil {
+			return nil, err
+		}
+		q.Datetime = datetime
+	}
+
+	limit, err := parseLimitParam(query, options)
+	if err != nil {
+		return nil, err
+	}
+	q.Limit = limit
+
+	if values, ok := query["properties"]; ok {
+		q.Properties = []string{}
+		for _, name := range strings.Split(first(values), ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				q.Properties = append(q.Properties, name)
+			}
+		}
+	}
+
+	if values, ok := query["skipGeometry"]; ok {
+		skip, err := strconv.ParseBool(first(values))
+		if err != nil {
+			return nil, invalidParameter("skipGeometry", "expected true or false, got %q", first(values))
+		}
+		q.SkipGeometry = skip
+	}
+
+	return q, nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func parseBboxParam(value string, crs string) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	values := make([]float64, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, invalidParameter("bbox", "expected a number, got %q", part)
+		}
+		values[i] = v
+	}
+
+	b, err := parseBbox(values)
+	if err != nil {
+		return nil, invalidParameter("bbox", "%s", err)
+	}
+	if err := b.validate(isGeographicCRS(crs)); err != nil {
+		return nil, invalidParameter("bbox", "%s", err)
+	}
+	return values, nil
+}
+
+func parseDatetimeParam(value string) (*TimeInterval, error) {
+	if !strings.Contains(value, "/") {
+		start, end, err := parseDatetimeValue(value)
+		if err != nil {
+			return nil, err
+		}
+		if start.Equal(end) {
+			return &TimeInterval{Start: &start, End: &start}, nil
+		}
+		return &TimeInterval{Start: &start, End: &end}, nil
+	}
+
+	parts := strings.Split(value, "/")
+	if len(parts) != 2 {
+		return nil, invalidParameter("datetime", "expected an instant or an interval, got %q", value)
+	}
+
+	datetime := &TimeInterval{}
+	if parts[0] != ".." && parts[0] != "" {
+		start, _, err := parseDatetimeValue(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		datetime.Start = &start
+	}
+	if parts[1] != ".." && parts[1] != "" {
+		_, end, err := parseDatetimeValue(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		datetime.End = &end
+	}
+
+	if err := datetime.validate(); err != nil {
+		return nil, invalidParameter("datetime", "%s", err)
+	}
+	return datetime, nil
+}
+
+// parseDatetimeValue parses a timestamp or a date.  The returned start and end are the same for a
+// timestamp and are the first and last instants of the day for a date.
+func parseDatetimeValue(value string) (time.Time, time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed.UTC(), parsed.UTC(), nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, time.Time{}, invalidParameter("datetime", "expected an RFC 3339 timestamp or a date, got %q", value)
+	}
+	return parsed, parsed.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+func parseLimitParam(query url.Values, options *FeaturesQueryOptions) (int, error) {
+	minimum := options.MinLimit
+	if minimum <= 0 {
+		minimum = 1
+	}
+	maximum := options.MaxLimit
+	if maximum <= 0 {
+		maximum = maxLimit
+	}
+	limit := options.DefaultLimit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	if values, ok := query["limit"]; ok {
+		value, err := strconv.Atoi(first(values))
+		if err != nil || value < 1 {
+			return 0, invalidParameter("limit", "expected a positive integer, got %q", first(values))
+		}
+		limit = value
+	}
+
+	return min(max(limit, minimum), maximum), nil
+}
+
+// Filter returns a filter equivalent to the bbox and datetime parameters.  The bbox is compared
+// to the geometry property with s_intersects and the datetime is compared to the datetime property
+// with t_intersects.  Boxes that cross the antimeridian are split in two, and boxes in a bbox-crs
+// other than CRS84 are transformed to CRS84.  The filter is nil if neither parameter was provided.
+func (q *FeaturesQuery) Filter(geometryProperty string, datetimeProperty string) (*filter.Filter, error) {
+	args := []filter.BooleanExpression{}
+
+	if q.Bbox != nil {
+		expression, err := q.bboxExpression(geometryProperty)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, expression)
+	}
+
+	if q.Datetime != nil {
+		expression, err := q.datetimeExpression(datetimeProperty)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, expression)
+	}
+
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &filter.Filter{Expression: args[0]}, nil
+	}
+	return &filter.Filter{Expression: &filter.And{Args: args}}, nil
+}
+
+func (q *FeaturesQuery) bboxExpression(geometryProperty string) (filter.BooleanExpression, error) {
+	b, err := parseBbox(q.Bbox)
+	if err != nil {
+		return nil, invalidParameter("bbox", "%s", err)
+	}
+
+	bboxCrs := q.BboxCrs
+	if bboxCrs == "" {
+		bboxCrs = CRS84
+	}
+	transform, err := GetCoordinateTransform(bboxCrs, CRS84)
+	if err != nil {
+		return nil, invalidParameter("bbox-crs", "%s", err)
+	}
+	if transform != nil {
+		lower := transform([]float64{b.minX, b.minY})
+		upper := transform([]float64{b.maxX, b.maxY})
+		b.minX, b.minY, b.maxX, b.maxY = lower[0], lower[1], upper[0], upper[1]
+	}
+
+	compare := func(values []float64) filter.BooleanExpression {
+		return &filter.SpatialComparison{
+			Name:  filter.GeometryIntersects,
+			Left:  &filter.Property{Name: geometryProperty},
+			Right: &filter.BoundingBox{Extent: values},
+		}
+	}
+
+	if b.minX <= b.maxX {
+		return compare(b.values()), nil
+	}
+
+	west, east := *b, *b
+	west.maxX = 180
+	east.minX = -180
+	return &filter.Or{Args: []filter.BooleanExpression{compare(west.values()), compare(east.values())}}, nil
+}
+
+func (q *FeaturesQuery) datetimeExpression(datetimeProperty string) (filter.BooleanExpression, error) {
+	if datetimeProperty == "" {
+		return nil, invalidParameter("datetime", "the collection has no temporal property")
+	}
+
+	var right filter.TemporalExpression
+	if q.Datetime.Instant() {
+		right = &filter.Timestamp{Value: *q.Datetime.Start}
+	} else {
+		var start, end filter.InstantExpression
+		if q.Datetime.Start != nil {
+			start = &filter.Timestamp{Value: *q.Datetime.Start}
+		}
+		if q.Datetime.End != nil {
+			end = &filter.Timestamp{Value: *q.Datetime.End}
+		}
+		interval, err := filter.NewInterval(start, end)
+		if err != nil {
+			return nil, invalidParameter("datetime", "%s", err)
+		}
+		right = interval
+	}
+
+	return &filter.TemporalComparison{
+		Name:  filter.TimeIntersects,
+		Left:  &filter.Property{Name: datetimeProperty},
+		Right: right,
+	}, nil
+}
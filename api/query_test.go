@@ -0,0 +1,305 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeaturesQuery(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    string
+		options  *api.FeaturesQueryOptions
+		expected *api.FeaturesQuery
+		err      string
+	}{
+		{
+			name:     "defaults",
+			query:    "",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10},
+		},
+		{
+			name:     "bbox",
+			query:    "bbox=-120,30,-110,40",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Bbox: []float64{-120, 30, -110, 40}},
+		},
+		{
+			name:     "bbox with z",
+			query:    "bbox=-120,30,0,-110,40,100",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Bbox: []float64{-120, 30, 0, -110, 40, 100}},
+		},
+		{
+			name:     "bbox across the antimeridian",
+			query:    "bbox=170,-20,-170,-10",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Bbox: []float64{170, -20, -170, -10}},
+		},
+		{
+			name:    "bbox crs",
+			query:   "bbox=0,0,1000,1000&bbox-crs=http://www.opengis.net/def/crs/EPSG/0/3857",
+			options: &api.FeaturesQueryOptions{SupportedCrs: []string{api.EPSG3857}},
+			expected: &api.FeaturesQuery{
+				Crs:     api.CRS84,
+				BboxCrs: api.EPSG3857,
+				Limit:   10,
+				Bbox:    []float64{0, 0, 1000, 1000},
+			},
+		},
+		{
+			name:  "bbox with wrong count",
+			query: "bbox=1,2,3",
+			err:   "invalid bbox parameter: expected 4 or 6 values, got 3",
+		},
+		{
+			name:  "bbox with non-number",
+			query: "bbox=1,2,x,4",
+			err:   `invalid bbox parameter: expected a number, got "x"`,
+		},
+		{
+			name:  "bbox with min y greater than max y",
+			query: "bbox=0,10,1,5",
+			err:   "invalid bbox parameter: min y 10 is greater than max y 5",
+		},
+		{
+			name:  "bbox out of range",
+			query: "bbox=0,0,200,10",
+			err:   "invalid bbox parameter: longitude out of range [-180, 180]",
+		},
+		{
+			name:  "unsupported bbox crs",
+			query: "bbox=0,0,1,1&bbox-crs=[EPSG:3857]",
+			err:   `invalid bbox-crs parameter: unsupported CRS "http://www.opengis.net/def/crs/EPSG/0/3857"`,
+		},
+		{
+			name:  "datetime instant",
+			query: "datetime=2023-06-01T12:00:00-07:00",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Datetime: &api.TimeInterval{
+				Start: timePointer(time.Date(2023, 6, 1, 19, 0, 0, 0, time.UTC)),
+				End:   timePointer(time.Date(2023, 6, 1, 19, 0, 0, 0, time.UTC)),
+			}},
+		},
+		{
+			name:  "datetime date",
+			query: "datetime=2023-06-01",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Datetime: &api.TimeInterval{
+				Start: timePointer(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)),
+				End:   timePointer(time.Date(2023, 6, 1, 23, 59, 59, 999999999, time.UTC)),
+			}},
+		},
+		{
+			name:  "closed interval",
+			query: "datetime=2023-01-01T00:00:00Z/2023-02-01",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Datetime: &api.TimeInterval{
+				Start: timePointer(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+				End:   timePointer(time.Date(2023, 2, 1, 23, 59, 59, 999999999, time.UTC)),
+			}},
+		},
+		{
+			name:  "open start",
+			query: "datetime=../2023-01-01T00:00:00Z",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Datetime: &api.TimeInterval{
+				End: timePointer(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}},
+		},
+		{
+			name:  "open end",
+			query: "datetime=2023-01-01T00:00:00Z/",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Datetime: &api.TimeInterval{
+				Start: timePointer(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+			}},
+		},
+		{
+			name:  "open interval",
+			query: "datetime=../..",
+			err:   "invalid datetime parameter: interval start or end must be provided",
+		},
+		{
+			name:  "start after end",
+			query: "datetime=2023-02-01/2023-01-01",
+			err:   "invalid datetime parameter: interval start 2023-02-01T00:00:00Z is after end 2023-01-01T23:59:59.999999999Z",
+		},
+		{
+			name:  "invalid datetime",
+			query: "datetime=yesterday",
+			err:   `invalid datetime parameter: expected an RFC 3339 timestamp or a date, got "yesterday"`,
+		},
+		{
+			name:  "too many datetime parts",
+			query: "datetime=2023-01-01/2023-01-02/2023-01-03",
+			err:   `invalid datetime parameter: expected an instant or an interval, got "2023-01-01/2023-01-02/2023-01-03"`,
+		},
+		{
+			name:     "limit",
+			query:    "limit=50",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 50},
+		},
+		{
+			name:     "limit over the maximum",
+			query:    "limit=500",
+			options:  &api.FeaturesQueryOptions{MaxLimit: 100},
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 100},
+		},
+		{
+			name:     "limit under the minimum",
+			query:    "limit=2",
+			options:  &api.FeaturesQueryOptions{MinLimit: 5},
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 5},
+		},
+		{
+			name:     "default limit",
+			query:    "",
+			options:  &api.FeaturesQueryOptions{DefaultLimit: 25},
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 25},
+		},
+		{
+			name:  "zero limit",
+			query: "limit=0",
+			err:   `invalid limit parameter: expected a positive integer, got "0"`,
+		},
+		{
+			name:  "non-integer limit",
+			query: "limit=ten",
+			err:   `invalid limit parameter: expected a positive integer, got "ten"`,
+		},
+		{
+			name:     "properties",
+			query:    "properties=name,%20count,,",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Properties: []string{"name", "count"}},
+		},
+		{
+			name:     "no properties",
+			query:    "properties=",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, Properties: []string{}},
+		},
+		{
+			name:     "skip geometry",
+			query:    "skipGeometry=true",
+			expected: &api.FeaturesQuery{Crs: api.CRS84, BboxCrs: api.CRS84, Limit: 10, SkipGeometry: true},
+		},
+		{
+			name:  "invalid skip geometry",
+			query: "skipGeometry=maybe",
+			err:   `invalid skipGeometry parameter: expected true or false, got "maybe"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := url.ParseQuery(c.query)
+			require.NoError(t, err)
+
+			parsed, err := api.ParseFeaturesQuery(query, c.options)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				paramErr := &api.InvalidParameterError{}
+				assert.True(t, errors.As(err, &paramErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, parsed)
+		})
+	}
+}
+
+func TestFeaturesQueryFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		query    string
+		options  *api.FeaturesQueryOptions
+		expected string
+	}{
+		{
+			name:  "no bbox or datetime",
+			query: "limit=5",
+		},
+		{
+			name:     "bbox",
+			query:    "bbox=-120,30,-110,40",
+			expected: `{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [-120, 30, -110, 40]}]}`,
+		},
+		{
+			name:  "bbox across the antimeridian",
+			query: "bbox=170,-20,0,-170,-10,10",
+			expected: `{"op": "or", "args": [
+				{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [170, -20, 0, 180, -10, 10]}]},
+				{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [-180, -20, 0, -170, -10, 10]}]}
+			]}`,
+		},
+		{
+			name:     "bbox in another crs",
+			query:    "bbox=0,0,20037508.342789244,20037508.342789244&bbox-crs=[EPSG:3857]",
+			options:  &api.FeaturesQueryOptions{SupportedCrs: []string{api.EPSG3857}},
+			expected: `{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [0, 0, 180, 85.05112877980659]}]}`,
+		},
+		{
+			name:     "datetime instant",
+			query:    "datetime=2023-06-01T12:00:00Z",
+			expected: `{"op": "t_intersects", "args": [{"property": "time"}, {"timestamp": "2023-06-01T12:00:00Z"}]}`,
+		},
+		{
+			name:     "datetime date",
+			query:    "datetime=2023-06-01",
+			expected: `{"op": "t_intersects", "args": [{"property": "time"}, {"interval": ["2023-06-01T00:00:00Z", "2023-06-01T23:59:59.999999999Z"]}]}`,
+		},
+		{
+			name:     "open interval",
+			query:    "datetime=../2023-06-01T12:00:00Z",
+			expected: `{"op": "t_intersects", "args": [{"property": "time"}, {"interval": ["..", "2023-06-01T12:00:00Z"]}]}`,
+		},
+		{
+			name:  "bbox and datetime",
+			query: "bbox=1,2,3,4&datetime=2023-06-01T12:00:00Z/..",
+			expected: `{"op": "and", "args": [
+				{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [1, 2, 3, 4]}]},
+				{"op": "t_intersects", "args": [{"property": "time"}, {"interval": ["2023-06-01T12:00:00Z", ".."]}]}
+			]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := url.ParseQuery(c.query)
+			require.NoError(t, err)
+			parsed, err := api.ParseFeaturesQuery(query, c.options)
+			require.NoError(t, err)
+
+			f, err := parsed.Filter("geom", "time")
+			require.NoError(t, err)
+			if c.expected == "" {
+				assert.Nil(t, f)
+				return
+			}
+			require.NotNil(t, f)
+			assert.JSONEq(t, c.expected, f.String())
+		})
+	}
+}
+
+func TestFeaturesQueryFilterWithoutTemporalProperty(t *testing.T) {
+	query, err := api.ParseFeaturesQuery(url.Values{"datetime": {"2023-06-01T12:00:00Z"}}, nil)
+	require.NoError(t, err)
+
+	_, err = query.Filter("geom", "")
+	assert.EqualError(t, err, "invalid datetime parameter: the collection has no temporal property")
+}
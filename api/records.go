@@ -17,28 +17,223 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 )
 
+// RecordCore is an extension for features that are OGC API - Records records.  The Id, Type,
+// Title, and time are required.  The time is null unless Time or TimeInterval is set.
 type RecordCore struct {
 	Id          string
 	Type        string
 	Title       string
 	Description string
-	Time        time.Time
-	Created     time.Time
-	Updated     time.Time
+
+	// Time is the instant associated with the record.  It is ignored if TimeInterval is set.
+	Time time.Time
+
+	// TimeInterval is the interval associated with the record.
+	TimeInterval *TimeInterval
+
+	Created       time.Time
+	Updated       time.Time
+	Keywords      []string
+	Themes        []*Theme
+	Contacts      []*Contact
+	Language      *Language
+	Languages     []*Language
+	Formats       []*Format
+	Rights        string
+	License       string
+	LinkTemplates []*Link
 }
 
 var (
 	_ Extension = (*RecordCore)(nil)
 )
 
+// Theme is a knowledge organization system used to classify a record.
+type Theme struct {
+	Concepts []*Concept `json:"concepts"`
+	Scheme   string     `json:"scheme"`
+}
+
+func (t *Theme) validate() error {
+	if len(t.Concepts) == 0 {
+		return errors.New("missing concepts")
+	}
+	if t.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+	for i, concept := range t.Concepts {
+		if concept == nil || concept.Id == "" {
+			return fmt.Errorf("missing id for concept %d", i)
+		}
+	}
+	return nil
+}
+
+// Concept is an entry from a theme's scheme.
+type Concept struct {
+	Id          string `json:"id"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// Contact is a person or organization associated with a record.  A name or organization is
+// required.
+type Contact struct {
+	Identifier          string          `json:"identifier,omitempty"`
+	Name                string          `json:"name,omitempty"`
+	Position            string          `json:"position,omitempty"`
+	Organization        string          `json:"organization,omitempty"`
+	Logo                *Link           `json:"logo,omitempty"`
+	Phones              []*ContactPoint `json:"phones,omitempty"`
+	Emails              []*ContactPoint `json:"emails,omitempty"`
+	Addresses           []*Address      `json:"addresses,omitempty"`
+	Links               []*Link         `json:"links,omitempty"`
+	HoursOfService      string          `json:"hoursOfService,omitempty"`
+	ContactInstructions string          `json:"contactInstructions,omitempty"`
+	Roles               []string        `json:"roles,omitempty"`
+}
+
+func (c *Contact) validate() error {
+	if c.Name == "" && c.Organization == "" {
+		return errors.New("missing name or organization")
+	}
+	for i, phone := range c.Phones {
+		if phone == nil || phone.Value == "" {
+			return fmt.Errorf("missing value for phone %d", i)
+		}
+	}
+	for i, email := range c.Emails {
+		if email == nil || email.Value == "" {
+			return fmt.Errorf("missing value for email %d", i)
+		}
+	}
+	return nil
+}
+
+// ContactPoint is a phone number or email address for a contact.
+type ContactPoint struct {
+	Value string   `json:"value"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Address is a physical address for a contact.
+type Address struct {
+	DeliveryPoint      []string `json:"deliveryPoint,omitempty"`
+	City               string   `json:"city,omitempty"`
+	AdministrativeArea string   `json:"administrativeArea,omitempty"`
+	PostalCode         string   `json:"postalCode,omitempty"`
+	Country            string   `json:"country,omitempty"`
+	Roles              []string `json:"roles,omitempty"`
+}
+
+// Language is identified by an RFC 5646 language tag.
+type Language struct {
+	Code      string `json:"code"`
+	Name      string `json:"name,omitempty"`
+	Alternate string `json:"alternate,omitempty"`
+	Dir       string `json:"dir,omitempty"`
+}
+
+func (l *Language) validate() error {
+	if l.Code == "" {
+		return errors.New("missing code")
+	}
+	return nil
+}
+
+// Format is a format in which the resource described by a record is available.  A name or media
+// type is required.
+type Format struct {
+	Name      string `json:"name,omitempty"`
+	MediaType string `json:"mediaType,omitempty"`
+}
+
+func (f *Format) validate() error {
+	if f.Name == "" && f.MediaType == "" {
+		return errors.New("missing name or media type")
+	}
+	return nil
+}
+
 func (r *RecordCore) URI() string {
 	return "http://www.opengis.net/spec/ogcapi-records-1/1.0/req/record-core"
 }
 
+// validate checks the required record properties other than the id.
+func (r *RecordCore) validate() error {
+	if r.Type == "" {
+		return errors.New("missing type")
+	}
+	if r.Title == "" {
+		return errors.New("missing title")
+	}
+	if r.TimeInterval != nil {
+		if err := r.TimeInterval.validate(); err != nil {
+			return fmt.Errorf("invalid time: %w", err)
+		}
+	}
+	if err := validateRecordMetadata(r.Themes, r.Contacts, r.Language, r.Languages, r.Formats); err != nil {
+		return err
+	}
+	for i, link := range r.LinkTemplates {
+		if link == nil || link.Href == "" || link.Rel == "" {
+			return fmt.Errorf("invalid link template %d: missing href or rel", i)
+		}
+	}
+	return nil
+}
+
+// validateRecordMetadata checks the metadata shared by records and catalogs.
+func validateRecordMetadata(themes []*Theme, contacts []*Contact, language *Language, languages []*Language, formats []*Format) error {
+	for i, theme := range themes {
+		if theme == nil {
+			return fmt.Errorf("invalid theme %d: missing theme", i)
+		}
+		if err := theme.validate(); err != nil {
+			return fmt.Errorf("invalid theme %d: %w", i, err)
+		}
+	}
+	for i, contact := range contacts {
+		if contact == nil {
+			return fmt.Errorf("invalid contact %d: missing contact", i)
+		}
+		if err := contact.validate(); err != nil {
+			return fmt.Errorf("invalid contact %d: %w", i, err)
+		}
+	}
+	if language != nil {
+		if err := language.validate(); err != nil {
+			return fmt.Errorf("invalid language: %w", err)
+		}
+	}
+	for i, l := range languages {
+		if l == nil {
+			return fmt.Errorf("invalid language %d: missing language", i)
+		}
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("invalid language %d: %w", i, err)
+		}
+	}
+	for i, format := range formats {
+		if format == nil {
+			return fmt.Errorf("invalid format %d: missing format", i)
+		}
+		if err := format.validate(); err != nil {
+			return fmt.Errorf("invalid format %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
 func (r *RecordCore) Encode(featureMap map[string]any) error {
 	propertiesMap, ok := featureMap["properties"].(map[string]any)
 	if !ok {
@@ -55,23 +250,30 @@ func (r *RecordCore) Encode(featureMap map[string]any) error {
 	}
 	featureMap["id"] = id
 
+	if err := r.validate(); err != nil {
+		return err
+	}
+
 	// required time
-	if r.Time.IsZero() {
+	switch {
+	case r.TimeInterval != nil:
+		featureMap["time"] = []string{
+			formatIntervalTime(r.TimeInterval.Start),
+			formatIntervalTime(r.TimeInterval.End),
+		}
+	case r.Time.IsZero():
 		featureMap["time"] = nil
-	} else {
+	default:
 		featureMap["time"] = r.Time.Format(time.RFC3339Nano)
 	}
 
-	// required type
-	if r.Type == "" {
-		return errors.New("missing type")
+	// optional link templates
+	if len(r.LinkTemplates) > 0 {
+		featureMap["linkTemplates"] = r.LinkTemplates
 	}
-	propertiesMap["type"] = r.Type
 
-	// required title
-	if r.Title == "" {
-		return errors.New("missing title")
-	}
+	// required type and title
+	propertiesMap["type"] = r.Type
 	propertiesMap["title"] = r.Title
 
 	// optional description
@@ -89,10 +291,181 @@ func (r *RecordCore) Encode(featureMap map[string]any) error {
 		propertiesMap["updated"] = r.Updated.Format(time.RFC3339Nano)
 	}
 
+	// optional lists
+	if len(r.Keywords) > 0 {
+		propertiesMap["keywords"] = r.Keywords
+	}
+	if len(r.Themes) > 0 {
+		propertiesMap["themes"] = r.Themes
+	}
+	if len(r.Contacts) > 0 {
+		propertiesMap["contacts"] = r.Contacts
+	}
+	if r.Language != nil {
+		propertiesMap["language"] = r.Language
+	}
+	if len(r.Languages) > 0 {
+		propertiesMap["languages"] = r.Languages
+	}
+	if len(r.Formats) > 0 {
+		propertiesMap["formats"] = r.Formats
+	}
+
+	// optional rights and license
+	if r.Rights != "" {
+		propertiesMap["rights"] = r.Rights
+	}
+	if r.License != "" {
+		propertiesMap["license"] = r.License
+	}
+
 	return nil
 }
 
+type decodedRecord struct {
+	Id            any                      `json:"id"`
+	Time          json.RawMessage          `json:"time"`
+	LinkTemplates []*Link                  `json:"linkTemplates"`
+	Properties    *decodedRecordProperties `json:"properties"`
+}
+
+type decodedRecordProperties struct {
+	Type        string      `json:"type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Created     string      `json:"created"`
+	Updated     string      `json:"updated"`
+	Keywords    []string    `json:"keywords"`
+	Themes      []*Theme    `json:"themes"`
+	Contacts    []*Contact  `json:"contacts"`
+	Language    *Language   `json:"language"`
+	Languages   []*Language `json:"languages"`
+	Formats     []*Format   `json:"formats"`
+	Rights      string      `json:"rights"`
+	License     string      `json:"license"`
+}
+
+// Decode reads the record properties from feature JSON.  An error is returned if required
+// properties are missing or invalid.
 func (r *RecordCore) Decode(data []byte) error {
-	// TODO: implement this
+	d := &decodedRecord{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return err
+	}
+
+	decoded := &RecordCore{LinkTemplates: d.LinkTemplates}
+
+	switch id := d.Id.(type) {
+	case string:
+		decoded.Id = id
+	case float64:
+		decoded.Id = strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	if decoded.Id == "" {
+		return errors.New("missing id")
+	}
+
+	if d.Properties == nil {
+		return errors.New("missing properties")
+	}
+	p := d.Properties
+	decoded.Type = p.Type
+	decoded.Title = p.Title
+	decoded.Description = p.Description
+	decoded.Keywords = p.Keywords
+	decoded.Themes = p.Themes
+	decoded.Contacts = p.Contacts
+	decoded.Language = p.Language
+	decoded.Languages = p.Languages
+	decoded.Formats = p.Formats
+	decoded.Rights = p.Rights
+	decoded.License = p.License
+
+	if err := decodeRecordTime(d.Time, decoded); err != nil {
+		return err
+	}
+
+	if p.Created != "" {
+		created, err := parseRecordTime(p.Created)
+		if err != nil {
+			return fmt.Errorf("invalid created time: %w", err)
+		}
+		decoded.Created = created
+	}
+	if p.Updated != "" {
+		updated, err := parseRecordTime(p.Updated)
+		if err != nil {
+			return fmt.Errorf("invalid updated time: %w", err)
+		}
+		decoded.Updated = updated
+	}
+
+	if err := decoded.validate(); err != nil {
+		return err
+	}
+
+	*r = *decoded
 	return nil
 }
+
+// decodeRecordTime decodes the required time member.  The time may be null, an instant, or a
+// two item interval where ".." or null means unbounded.
+func decodeRecordTime(data json.RawMessage, r *RecordCore) error {
+	if len(data) == 0 {
+		return errors.New("missing time")
+	}
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		instant, err := parseRecordTime(v)
+		if err != nil {
+			return fmt.Errorf("invalid time: %w", err)
+		}
+		r.Time = instant
+		return nil
+	case []any:
+		if len(v) != 2 {
+			return fmt.Errorf("invalid time: expected 2 items in interval, got %d", len(v))
+		}
+		interval := &TimeInterval{}
+		for i, target := range []**time.Time{&interval.Start, &interval.End} {
+			switch item := v[i].(type) {
+			case nil:
+			case string:
+				if item == openBound {
+					continue
+				}
+				parsed, err := parseRecordTime(item)
+				if err != nil {
+					return fmt.Errorf("invalid time: %w", err)
+				}
+				*target = &parsed
+			default:
+				return fmt.Errorf("invalid time: unexpected interval item %v", item)
+			}
+		}
+		r.TimeInterval = interval
+		return nil
+	}
+	return fmt.Errorf("invalid time: expected a string, an interval, or null, got %s", data)
+}
+
+// parseRecordTime parses an RFC 3339 timestamp or a date.
+func parseRecordTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC 3339 timestamp or a date, got %q", value)
+	}
+	return parsed, nil
+}
@@ -26,6 +26,10 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func timePointer(value time.Time) *time.Time {
+	return &value
+}
+
 func TestRecordCore(t *testing.T) {
 	origin := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -113,3 +117,238 @@ func TestRecordCore(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordCoreRoundTrip(t *testing.T) {
+	data := `{
+		"type": "Feature",
+		"id": "scene-1",
+		"geometry": null,
+		"time": ["2020-01-01T00:00:00Z", ".."],
+		"linkTemplates": [
+			{"href": "https://example.com/tiles/{z}/{x}/{y}.png", "rel": "item", "templated": true}
+		],
+		"properties": {
+			"type": "dataset",
+			"title": "Scene 1",
+			"description": "A scene",
+			"created": "2020-01-02T00:00:00Z",
+			"updated": "2020-01-03T00:00:00.5Z",
+			"keywords": ["imagery", "optical"],
+			"themes": [
+				{
+					"concepts": [{"id": "imageryBaseMapsEarthCover", "title": "Imagery"}],
+					"scheme": "https://www.eionet.europa.eu/gemet/en/inspire-themes/"
+				}
+			],
+			"contacts": [
+				{
+					"name": "Jane Doe",
+					"organization": "Example Org",
+					"phones": [{"value": "+1-555-0100", "roles": ["main"]}],
+					"emails": [{"value": "jane@example.com"}],
+					"addresses": [{"deliveryPoint": ["1 Main St"], "city": "Springfield", "country": "US", "roles": ["office"]}],
+					"links": [{"href": "https://example.com", "rel": "about"}],
+					"roles": ["pointOfContact"]
+				}
+			],
+			"language": {"code": "en"},
+			"languages": [{"code": "en", "name": "English"}, {"code": "fr", "name": "Français"}],
+			"formats": [{"name": "GeoTIFF", "mediaType": "image/tiff; application=geotiff"}],
+			"rights": "Copyright Example Org",
+			"license": "CC-BY-4.0"
+		},
+		"conformsTo": [
+			"http://www.opengis.net/spec/ogcapi-records-1/1.0/req/record-core"
+		]
+	}`
+
+	record := &api.RecordCore{}
+	feature := &api.Feature{Extensions: []api.Extension{record}}
+	require.NoError(t, json.Unmarshal([]byte(data), feature))
+
+	assert.Equal(t, "scene-1", record.Id)
+	assert.Equal(t, "dataset", record.Type)
+	assert.Equal(t, "Scene 1", record.Title)
+	assert.Equal(t, &api.TimeInterval{Start: timePointer(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))}, record.TimeInterval)
+	assert.Equal(t, time.Date(2020, 1, 3, 0, 0, 0, 500000000, time.UTC), record.Updated)
+	assert.Equal(t, []string{"imagery", "optical"}, record.Keywords)
+	require.Len(t, record.Themes, 1)
+	assert.Equal(t, "imageryBaseMapsEarthCover", record.Themes[0].Concepts[0].Id)
+	require.Len(t, record.Contacts, 1)
+	assert.Equal(t, "Springfield", record.Contacts[0].Addresses[0].City)
+	assert.Equal(t, []string{"main"}, record.Contacts[0].Phones[0].Roles)
+	assert.Equal(t, "en", record.Language.Code)
+	assert.Len(t, record.Languages, 2)
+	assert.Equal(t, "GeoTIFF", record.Formats[0].Name)
+	assert.Equal(t, "CC-BY-4.0", record.License)
+	require.Len(t, record.LinkTemplates, 1)
+	assert.True(t, record.LinkTemplates[0].Templated)
+
+	encoded, err := json.Marshal(feature)
+	require.NoError(t, err)
+	assert.JSONEq(t, data, string(encoded))
+}
+
+func TestRecordCoreDecodeTime(t *testing.T) {
+	cases := []struct {
+		name     string
+		time     string
+		instant  time.Time
+		interval *api.TimeInterval
+	}{
+		{
+			name: "null",
+			time: `null`,
+		},
+		{
+			name:    "timestamp",
+			time:    `"2020-01-01T12:00:00Z"`,
+			instant: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "date",
+			time:    `"2020-01-01"`,
+			instant: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "interval",
+			time:     `["2020-01-01", "2020-12-31T00:00:00Z"]`,
+			interval: &api.TimeInterval{Start: timePointer(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), End: timePointer(time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC))},
+		},
+		{
+			name:     "open start",
+			time:     `[null, "2020-12-31T00:00:00Z"]`,
+			interval: &api.TimeInterval{End: timePointer(time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC))},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := `{"type": "Feature", "id": "a", "geometry": null, "time": ` + tc.time + `, "properties": {"type": "t", "title": "T"}}`
+			record := &api.RecordCore{}
+			require.NoError(t, record.Decode([]byte(data)))
+			assert.Equal(t, tc.instant, record.Time)
+			assert.Equal(t, tc.interval, record.TimeInterval)
+		})
+	}
+}
+
+func TestRecordCoreDecodeNumericId(t *testing.T) {
+	cases := []struct {
+		id       string
+		expected string
+	}{
+		{id: `42`, expected: "42"},
+		{id: `1000000`, expected: "1000000"},
+		{id: `12345678901234`, expected: "12345678901234"},
+		{id: `1.5`, expected: "1.5"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.id, func(t *testing.T) {
+			data := `{"type": "Feature", "id": ` + tc.id + `, "geometry": null, "time": null, "properties": {"type": "t", "title": "T"}}`
+			record := &api.RecordCore{}
+			require.NoError(t, record.Decode([]byte(data)))
+			assert.Equal(t, tc.expected, record.Id)
+		})
+	}
+}
+
+func TestRecordCoreDecodeErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "missing id",
+			data: `{"type": "Feature", "geometry": null, "time": null, "properties": {"type": "t", "title": "T"}}`,
+			err:  "missing id",
+		},
+		{
+			name: "missing time",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "properties": {"type": "t", "title": "T"}}`,
+			err:  "missing time",
+		},
+		{
+			name: "missing type",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": null, "properties": {"title": "T"}}`,
+			err:  "missing type",
+		},
+		{
+			name: "missing title",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": null, "properties": {"type": "t"}}`,
+			err:  "missing title",
+		},
+		{
+			name: "invalid time",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": 42, "properties": {"type": "t", "title": "T"}}`,
+			err:  "invalid time: expected a string, an interval, or null, got 42",
+		},
+		{
+			name: "reversed interval",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": ["2021-01-01", "2020-01-01"], "properties": {"type": "t", "title": "T"}}`,
+			err:  "invalid time: interval start 2021-01-01T00:00:00Z is after end 2020-01-01T00:00:00Z",
+		},
+		{
+			name: "theme without scheme",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": null, "properties": {"type": "t", "title": "T", "themes": [{"concepts": [{"id": "c"}]}]}}`,
+			err:  "invalid theme 0: missing scheme",
+		},
+		{
+			name: "contact without name",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": null, "properties": {"type": "t", "title": "T", "contacts": [{"position": "Lead"}]}}`,
+			err:  "invalid contact 0: missing name or organization",
+		},
+		{
+			name: "language without code",
+			data: `{"type": "Feature", "id": "a", "geometry": null, "time": null, "properties": {"type": "t", "title": "T", "language": {"name": "English"}}}`,
+			err:  "invalid language: missing code",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			record := &api.RecordCore{}
+			assert.EqualError(t, record.Decode([]byte(tc.data)), tc.err)
+		})
+	}
+}
+
+func TestRecordCoreEncodeErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		record *api.RecordCore
+		err    string
+	}{
+		{
+			name:   "missing id",
+			record: &api.RecordCore{Type: "t", Title: "T"},
+			err:    "missing id",
+		},
+		{
+			name:   "missing title",
+			record: &api.RecordCore{Id: "a", Type: "t"},
+			err:    "missing title",
+		},
+		{
+			name:   "empty interval",
+			record: &api.RecordCore{Id: "a", Type: "t", Title: "T", TimeInterval: &api.TimeInterval{}},
+			err:    "invalid time: interval start or end must be provided",
+		},
+		{
+			name:   "format without name",
+			record: &api.RecordCore{Id: "a", Type: "t", Title: "T", Formats: []*api.Format{{}}},
+			err:    "invalid format 0: missing name or media type",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			feature := &api.Feature{Properties: map[string]any{}, Extensions: []api.Extension{tc.record}}
+			_, err := json.Marshal(feature)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/planetlabs/go-ogc/filter"
+)
+
+// recordSeparator precedes each feature in RFC 8142 GeoJSON text sequences.
+const recordSeparator = 0x1e
+
+// FeatureFilter writes the features from GeoJSON input that match a filter.
+//
+// The input may be a FeatureCollection or a sequence of features (newline-delimited or
+// RFC 8142 GeoJSON text sequences).  Features are decoded one at a time, so the input is
+// never loaded fully.  Matching features are written as they were read (with insignificant
+// whitespace removed).
+type FeatureFilter struct {
+	// Filter selects the features to write.  All features match if the filter is nil.
+	Filter *filter.Filter
+
+	// Limit is the maximum number of features to write.  There is no limit if it is zero.  The
+	// whole input is still read so all matching features are counted.
+	Limit int
+
+	// Sequence determines the output format.  By default, matching features are written as a
+	// FeatureCollection.  If Sequence is true, features are written one per line instead.
+	Sequence bool
+}
+
+// FeatureFilterCounts holds the number of features handled by a FeatureFilter.
+type FeatureFilterCounts struct {
+	// Matched is the number of features that matched the filter.
+	Matched int
+
+	// Returned is the number of features written to the output.
+	Returned int
+}
+
+// Apply reads features from the input and writes matching features to the output.  The counts
+// reflect the features handled before any error.  Reading stops with the context error if the
+// context is canceled.
+func (f *FeatureFilter) Apply(ctx context.Context, input io.Reader, output io.Writer) (*FeatureFilterCounts, error) {
+	writer := &featureWriter{
+		writer:   bufio.NewWriter(output),
+		sequence: f.Sequence,
+	}
+	counts := &FeatureFilterCounts{}
+	read := 0
+
+	handle := func(data json.RawMessage) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		read += 1
+		feature := &Feature{}
+		if err := json.Unmarshal(data, feature); err != nil {
+			return fmt.Errorf("trouble decoding feature %d: %w", read, err)
+		}
+		if f.Filter != nil && f.Filter.Expression != nil {
+			matched, err := filter.Evaluate(f.Filter, feature)
+			if err != nil {
+				return fmt.Errorf("trouble evaluating filter: %w", err)
+			}
+			if !matched {
+				return nil
+			}
+		}
+
+		counts.Matched += 1
+		if f.Limit > 0 && counts.Returned >= f.Limit {
+			return nil
+		}
+		if err := writer.write(data); err != nil {
+			return err
+		}
+		counts.Returned += 1
+		return nil
+	}
+
+	if err := readFeatures(input, handle); err != nil {
+		return counts, err
+	}
+	if err := writer.close(counts); err != nil {
+		return counts, err
+	}
+	return counts, nil
+}
+
+// sequenceReader replaces record separators with whitespace so text sequences can be decoded
+// as a stream of JSON values.
+type sequenceReader struct {
+	reader io.Reader
+}
+
+func (r *sequenceReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == recordSeparator {
+			p[i] = '\n'
+		}
+	}
+	return n, err
+}
+
+// peekSize is the amount of input examined when detecting the input format.
+const peekSize = 64 * 1024
+
+// isCollection reports whether the input starts with a FeatureCollection.  The top-level
+// members of the first object are examined until a "type" or "features" member is found.
+func isCollection(reader *bufio.Reader) bool {
+	peeked, _ := reader.Peek(peekSize)
+	decoder := json.NewDecoder(&sequenceReader{reader: bytes.NewReader(peeked)})
+
+	token, err := decoder.Token()
+	if delim, ok := token.(json.Delim); err != nil || !ok || delim != '{' {
+		return false
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch token {
+		case "features":
+			return true
+		case "type":
+			value := ""
+			err := decoder.Decode(&value)
+			return err == nil && value == "FeatureCollection"
+		}
+		skipped := json.RawMessage{}
+		if err := decoder.Decode(&skipped); err != nil {
+			return false
+		}
+	}
+	return false
+}
+
+// readFeatures calls handle with each feature from a FeatureCollection or a feature sequence.
+func readFeatures(input io.Reader, handle func(json.RawMessage) error) error {
+	reader := bufio.NewReaderSize(input, peekSize)
+	collection := isCollection(reader)
+	decoder := json.NewDecoder(&sequenceReader{reader: reader})
+
+	if !collection {
+		for {
+			data := json.RawMessage{}
+			err := decoder.Decode(&data)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("trouble reading features: %w", err)
+			}
+			if err := handle(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("trouble reading features: %w", err)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("trouble reading features: %w", err)
+		}
+		if token == "features" {
+			if err := readFeatureArray(decoder, handle); err != nil {
+				return err
+			}
+			continue
+		}
+		skipped := json.RawMessage{}
+		if err := decoder.Decode(&skipped); err != nil {
+			return fmt.Errorf("trouble reading features: %w", err)
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("trouble reading features: %w", err)
+	}
+	return nil
+}
+
+func readFeatureArray(decoder *json.Decoder, handle func(json.RawMessage) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("trouble reading features: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("trouble reading features: expected an array of features, got %v", token)
+	}
+	for decoder.More() {
+		data := json.RawMessage{}
+		if err := decoder.Decode(&data); err != nil {
+			return fmt.Errorf("trouble reading features: %w", err)
+		}
+		if err := handle(data); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("trouble reading features: %w", err)
+	}
+	return nil
+}
+
+// featureWriter writes features as a FeatureCollection or a sequence.
+type featureWriter struct {
+	writer   *bufio.Writer
+	sequence bool
+	started  bool
+}
+
+func (w *featureWriter) write(data json.RawMessage) error {
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, data); err != nil {
+		return fmt.Errorf("trouble writing feature: %w", err)
+	}
+
+	switch {
+	case w.sequence:
+	case !w.started:
+		w.writer.WriteString(`{"type":"FeatureCollection","features":[`)
+	default:
+		w.writer.WriteString(",")
+	}
+	w.started = true
+
+	if _, err := w.writer.Write(compacted.Bytes()); err != nil {
+		return fmt.Errorf("trouble writing feature: %w", err)
+	}
+	if w.sequence {
+		w.writer.WriteString("\n")
+	}
+	return nil
+}
+
+func (w *featureWriter) close(counts *FeatureFilterCounts) error {
+	if !w.sequence {
+		if !w.started {
+			w.writer.WriteString(`{"type":"FeatureCollection","features":[`)
+		}
+		fmt.Fprintf(w.writer, `],"numberMatched":%d,"numberReturned":%d}`+"\n", counts.Matched, counts.Returned)
+	}
+	if err := w.writer.Flush(); err != nil {
+		return fmt.Errorf("trouble writing features: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const streamFeatures = `
+	{"type": "Feature", "id": "a", "geometry": {"type": "Point", "coordinates": [1, 1]}, "properties": {"count": 1}, "extra": true}
+	{"type": "Feature", "id": 2, "geometry": {"type": "Point", "coordinates": [20, 20]}, "properties": {"count": 2}}
+	{"type": "Feature", "id": "c", "geometry": null, "properties": {"count": 3}}
+`
+
+const streamCollection = `{
+	"type": "FeatureCollection",
+	"links": [],
+	"features": [
+		{"type": "Feature", "id": "a", "geometry": {"type": "Point", "coordinates": [1, 1]}, "properties": {"count": 1}, "extra": true},
+		{"type": "Feature", "id": 2, "geometry": {"type": "Point", "coordinates": [20, 20]}, "properties": {"count": 2}},
+		{"type": "Feature", "id": "c", "geometry": null, "properties": {"count": 3}}
+	]
+}`
+
+func mustParseStreamFilter(t *testing.T, data string) *filter.Filter {
+	f := &filter.Filter{}
+	require.NoError(t, f.UnmarshalJSON([]byte(data)))
+	return f
+}
+
+func TestFeatureFilter(t *testing.T) {
+	featureA := `{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[1,1]},"properties":{"count":1},"extra":true}`
+	feature2 := `{"type":"Feature","id":2,"geometry":{"type":"Point","coordinates":[20,20]},"properties":{"count":2}}`
+	featureC := `{"type":"Feature","id":"c","geometry":null,"properties":{"count":3}}`
+
+	cases := []struct {
+		name     string
+		input    string
+		filter   string
+		limit    int
+		sequence bool
+		output   string
+		matched  int
+		returned int
+	}{
+		{
+			name:     "collection",
+			input:    streamCollection,
+			filter:   `{"op": ">", "args": [{"property": "count"}, 1]}`,
+			output:   `{"type":"FeatureCollection","features":[` + feature2 + `,` + featureC + `],"numberMatched":2,"numberReturned":2}` + "\n",
+			matched:  2,
+			returned: 2,
+		},
+		{
+			name:     "collection with large leading member",
+			input:    `{"name": "` + strings.Repeat("x", 5000) + `",` + strings.TrimPrefix(streamCollection, "{"),
+			filter:   `{"op": ">", "args": [{"property": "count"}, 1]}`,
+			output:   `{"type":"FeatureCollection","features":[` + feature2 + `,` + featureC + `],"numberMatched":2,"numberReturned":2}` + "\n",
+			matched:  2,
+			returned: 2,
+		},
+		{
+			name:     "sequence",
+			input:    streamFeatures,
+			filter:   `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			sequence: true,
+			output:   featureA + "\n",
+			matched:  1,
+			returned: 1,
+		},
+		{
+			name:     "text sequence",
+			input:    "\x1e" + strings.TrimSpace(strings.ReplaceAll(streamFeatures, "\n\t{", "\n\x1e{")),
+			filter:   `{"op": "=", "args": [{"property": "id"}, "2"]}`,
+			sequence: true,
+			output:   feature2 + "\n",
+			matched:  1,
+			returned: 1,
+		},
+		{
+			name:     "limit",
+			input:    streamFeatures,
+			limit:    1,
+			output:   `{"type":"FeatureCollection","features":[` + featureA + `],"numberMatched":3,"numberReturned":1}` + "\n",
+			matched:  3,
+			returned: 1,
+		},
+		{
+			name:     "no matches",
+			input:    streamCollection,
+			filter:   `{"op": "isNull", "args": [{"property": "count"}]}`,
+			output:   `{"type":"FeatureCollection","features":[],"numberMatched":0,"numberReturned":0}` + "\n",
+			matched:  0,
+			returned: 0,
+		},
+		{
+			name:     "empty input",
+			input:    "",
+			sequence: true,
+			output:   "",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			featureFilter := &api.FeatureFilter{Limit: c.limit, Sequence: c.sequence}
+			if c.filter != "" {
+				featureFilter.Filter = mustParseStreamFilter(t, c.filter)
+			}
+
+			output := &bytes.Buffer{}
+			counts, err := featureFilter.Apply(context.Background(), strings.NewReader(c.input), output)
+			require.NoError(t, err)
+			assert.Equal(t, c.output, output.String())
+			assert.Equal(t, c.matched, counts.Matched)
+			assert.Equal(t, c.returned, counts.Returned)
+		})
+	}
+}
+
+func TestFeatureFilterErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{
+			name:  "not a feature",
+			input: `{"type": "Feature", "properties": {}} {"type": "Point", "coordinates": [1, 2]}`,
+			err:   `trouble decoding feature 2: expected a Feature, got type "Point"`,
+		},
+		{
+			name:  "invalid geometry",
+			input: `{"type": "Feature", "geometry": {"type": "LineString", "coordinates": [[1, 2]]}, "properties": {}}`,
+			err:   "trouble decoding feature 1: trouble decoding geometry: invalid linestring: expected at least 2 positions in line",
+		},
+		{
+			name:  "invalid json",
+			input: `{"type": "FeatureCollection", "features": [{"type": "Feature"},`,
+			err:   "trouble reading features: unexpected end of JSON input",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			featureFilter := &api.FeatureFilter{}
+			_, err := featureFilter.Apply(context.Background(), strings.NewReader(c.input), &bytes.Buffer{})
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestFeatureFilterCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	featureFilter := &api.FeatureFilter{}
+	counts, err := featureFilter.Apply(ctx, strings.NewReader(streamFeatures), &bytes.Buffer{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, counts.Matched)
+}
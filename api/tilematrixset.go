@@ -204,7 +204,7 @@ var tmsLookup = map[string]*tileMatrixSetConfig{
 		cornerOfOrigin:      TopLeft,
 		tileWidth:           256,
 		tileHeight:          256,
-		crs:                 crsEPSG3857,
+		crs:                 EPSG3857,
 		orderedAxes:         forwardsAxisOrder,
 		wellKnownScaleSet:   wkssGoogle,
 	},
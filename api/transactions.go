@@ -0,0 +1,220 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Conformance classes from OGC API - Features - Part 4: Create, Replace, Update and Delete.
+const (
+	CreateReplaceDeleteConformance = "http://www.opengis.net/spec/ogcapi-features-4/1.0/conf/create-replace-delete"
+	UpdateConformance              = "http://www.opengis.net/spec/ogcapi-features-4/1.0/conf/update"
+	FeaturesTransactionConformance = "http://www.opengis.net/spec/ogcapi-features-4/1.0/conf/features"
+)
+
+// MergePatchMediaType is the media type of JSON Merge Patch (RFC 7396) request bodies.
+const MergePatchMediaType = "application/merge-patch+json"
+
+// ErrPreconditionFailed is returned when an If-Match header does not match the current ETag.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// DecodeCreateBody decodes the body of a request to create features.  The body may be a single
+// feature or a feature collection.  Each feature is validated, including its geometry.
+func DecodeCreateBody(data []byte) ([]*Feature, error) {
+	header := &struct {
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal(data, header); err != nil {
+		return nil, fmt.Errorf("trouble decoding request body: %w", err)
+	}
+
+	switch header.Type {
+	case "Feature":
+		feature, err := DecodeReplaceBody(data)
+		if err != nil {
+			return nil, err
+		}
+		return []*Feature{feature}, nil
+	case "FeatureCollection":
+		collection := &struct {
+			Features []json.RawMessage `json:"features"`
+		}{}
+		if err := json.Unmarshal(data, collection); err != nil {
+			return nil, fmt.Errorf("trouble decoding request body: %w", err)
+		}
+		if collection.Features == nil {
+			return nil, errors.New("expected a features array in the feature collection")
+		}
+		features := make([]*Feature, len(collection.Features))
+		for i, featureData := range collection.Features {
+			feature, err := decodeFeatureInput(featureData)
+			if err != nil {
+				return nil, fmt.Errorf("invalid feature %d: %w", i, err)
+			}
+			features[i] = feature
+		}
+		return features, nil
+	}
+	return nil, fmt.Errorf("expected a Feature or FeatureCollection, got type %q", header.Type)
+}
+
+// DecodeReplaceBody decodes the body of a request to replace a feature.  The body must be a
+// single feature.
+func DecodeReplaceBody(data []byte) (*Feature, error) {
+	feature, err := decodeFeatureInput(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feature: %w", err)
+	}
+	return feature, nil
+}
+
+// decodeFeatureInput decodes a feature and checks that it has the required members.
+func decodeFeatureInput(data []byte) (*Feature, error) {
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"geometry", "properties"} {
+		if _, ok := members[name]; !ok {
+			return nil, fmt.Errorf("missing %s", name)
+		}
+	}
+	properties := bytes.TrimSpace(members["properties"])
+	if !bytes.Equal(properties, []byte("null")) && !bytes.HasPrefix(properties, []byte("{")) {
+		return nil, errors.New("expected properties to be an object or null")
+	}
+
+	feature := &Feature{}
+	if err := json.Unmarshal(data, feature); err != nil {
+		return nil, err
+	}
+	return feature, nil
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to a feature and returns the patched feature.
+// The original feature is not modified.  Members with a null value in the patch are removed, and
+// objects (like the properties) are merged recursively.  Arrays and geometries are replaced.
+// The patched feature is validated and has no extensions; members written by extensions are kept
+// in its properties and foreign members.
+func MergePatch(feature *Feature, patch []byte) (*Feature, error) {
+	var patchValue any
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("trouble decoding merge patch: %w", err)
+	}
+	patchObject, ok := patchValue.(map[string]any)
+	if !ok {
+		return nil, errors.New("expected a JSON object for the merge patch")
+	}
+
+	data, err := json.Marshal(feature)
+	if err != nil {
+		return nil, fmt.Errorf("trouble encoding feature: %w", err)
+	}
+	target := map[string]any{}
+	if err := json.Unmarshal(data, &target); err != nil {
+		return nil, fmt.Errorf("trouble encoding feature: %w", err)
+	}
+
+	patched, err := json.Marshal(mergePatch(target, patchObject))
+	if err != nil {
+		return nil, fmt.Errorf("trouble encoding patched feature: %w", err)
+	}
+	result := &Feature{}
+	if err := json.Unmarshal(patched, result); err != nil {
+		return nil, fmt.Errorf("invalid patched feature: %w", err)
+	}
+	return result, nil
+}
+
+// mergePatch implements the RFC 7396 merge algorithm.
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+	return targetObject
+}
+
+// FeatureETag returns a strong entity tag for the JSON encoding of a feature.  The tag changes if
+// any member of the feature changes.
+func FeatureETag(feature *Feature) (string, error) {
+	data, err := json.Marshal(feature)
+	if err != nil {
+		return "", fmt.Errorf("trouble encoding feature: %w", err)
+	}
+
+	// round trip through a generic value so members are sorted
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", fmt.Errorf("trouble encoding feature: %w", err)
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("trouble encoding feature: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// CheckIfMatch checks an If-Match request header against the current entity tag of a resource.
+// ErrPreconditionFailed is returned if the header is present and none of its tags match.  Tags
+// are compared with the strong comparison required for If-Match, so weak tags never match.  A
+// "*" header matches any current tag.
+func CheckIfMatch(header string, etag string) error {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil
+	}
+	if header == "*" {
+		if etag == "" {
+			return ErrPreconditionFailed
+		}
+		return nil
+	}
+	if strings.HasPrefix(etag, "W/") {
+		return ErrPreconditionFailed
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return nil
+		}
+	}
+	return ErrPreconditionFailed
+}
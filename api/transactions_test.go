@@ -0,0 +1,341 @@
+/**
+ * Copyright 2023 Planet Labs PBC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCreateBody(t *testing.T) {
+	cases := []struct {
+		name  string
+		body  string
+		count int
+		err   string
+	}{
+		{
+			name:  "feature",
+			body:  `{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"name": "a"}}`,
+			count: 1,
+		},
+		{
+			name:  "null geometry and properties",
+			body:  `{"type": "Feature", "geometry": null, "properties": null}`,
+			count: 1,
+		},
+		{
+			name: "feature collection",
+			body: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {}},
+				{"type": "Feature", "geometry": null, "properties": {"name": "b"}}
+			]}`,
+			count: 2,
+		},
+		{
+			name:  "empty feature collection",
+			body:  `{"type": "FeatureCollection", "features": []}`,
+			count: 0,
+		},
+		{
+			name: "missing features",
+			body: `{"type": "FeatureCollection"}`,
+			err:  "expected a features array in the feature collection",
+		},
+		{
+			name: "invalid feature in collection",
+			body: `{"type": "FeatureCollection", "features": [
+				{"type": "Feature", "geometry": null, "properties": {}},
+				{"type": "Feature", "properties": {}}
+			]}`,
+			err: "invalid feature 1: missing geometry",
+		},
+		{
+			name: "missing properties",
+			body: `{"type": "Feature", "geometry": null}`,
+			err:  "invalid feature: missing properties",
+		},
+		{
+			name: "invalid properties",
+			body: `{"type": "Feature", "geometry": null, "properties": [1, 2]}`,
+			err:  "invalid feature: expected properties to be an object or null",
+		},
+		{
+			name: "invalid geometry",
+			body: `{"type": "Feature", "geometry": {"type": "Point", "coordinates": "nope"}, "properties": {}}`,
+			err:  "invalid feature: trouble decoding geometry",
+		},
+		{
+			name: "unexpected type",
+			body: `{"type": "Point", "coordinates": [1, 2]}`,
+			err:  `expected a Feature or FeatureCollection, got type "Point"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			features, err := api.DecodeCreateBody([]byte(c.body))
+			if c.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, features, c.count)
+		})
+	}
+}
+
+func TestDecodeReplaceBody(t *testing.T) {
+	feature, err := api.DecodeReplaceBody([]byte(`{"type": "Feature", "id": 42, "geometry": {"type": "Point", "coordinates": [1, 2]}, "properties": {"name": "a"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "42", feature.Id)
+	assert.Equal(t, map[string]any{"name": "a"}, feature.Properties)
+
+	_, err = api.DecodeReplaceBody([]byte(`{"type": "FeatureCollection", "features": []}`))
+	assert.Error(t, err)
+}
+
+func TestMergePatch(t *testing.T) {
+	original := `{
+		"type": "Feature",
+		"id": "a",
+		"geometry": {"type": "Point", "coordinates": [1, 2]},
+		"properties": {
+			"name": "original",
+			"count": 1,
+			"tags": ["x", "y"],
+			"address": {
+				"street": "Main",
+				"city": "Springfield",
+				"location": {"floor": 2, "room": "b"}
+			}
+		}
+	}`
+
+	cases := []struct {
+		name     string
+		patch    string
+		expected string
+		err      string
+	}{
+		{
+			name:  "replace a property",
+			patch: `{"properties": {"name": "patched"}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [1, 2]},
+				"properties": {
+					"name": "patched",
+					"count": 1,
+					"tags": ["x", "y"],
+					"address": {"street": "Main", "city": "Springfield", "location": {"floor": 2, "room": "b"}}
+				}
+			}`,
+		},
+		{
+			name:  "merge nested properties",
+			patch: `{"properties": {"address": {"city": "Shelbyville", "location": {"floor": 3}, "zip": "12345"}}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [1, 2]},
+				"properties": {
+					"name": "original",
+					"count": 1,
+					"tags": ["x", "y"],
+					"address": {
+						"street": "Main",
+						"city": "Shelbyville",
+						"zip": "12345",
+						"location": {"floor": 3, "room": "b"}
+					}
+				}
+			}`,
+		},
+		{
+			name:  "remove nested properties",
+			patch: `{"properties": {"count": null, "address": {"street": null, "location": {"room": null}}}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [1, 2]},
+				"properties": {
+					"name": "original",
+					"tags": ["x", "y"],
+					"address": {"city": "Springfield", "location": {"floor": 2}}
+				}
+			}`,
+		},
+		{
+			name:  "remove an object",
+			patch: `{"properties": {"address": null}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [1, 2]},
+				"properties": {"name": "original", "count": 1, "tags": ["x", "y"]}
+			}`,
+		},
+		{
+			name:  "replace an object with a scalar",
+			patch: `{"properties": {"address": "unknown"}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [1, 2]},
+				"properties": {"name": "original", "count": 1, "tags": ["x", "y"], "address": "unknown"}
+			}`,
+		},
+		{
+			name:  "arrays are replaced",
+			patch: `{"properties": {"tags": ["z"]}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "Point", "coordinates": [1, 2]},
+				"properties": {
+					"name": "original",
+					"count": 1,
+					"tags": ["z"],
+					"address": {"street": "Main", "city": "Springfield", "location": {"floor": 2, "room": "b"}}
+				}
+			}`,
+		},
+		{
+			name:  "geometry is replaced",
+			patch: `{"geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}}`,
+			expected: `{
+				"type": "Feature",
+				"id": "a",
+				"geometry": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]},
+				"properties": {
+					"name": "original",
+					"count": 1,
+					"tags": ["x", "y"],
+					"address": {"street": "Main", "city": "Springfield", "location": {"floor": 2, "room": "b"}}
+				}
+			}`,
+		},
+		{
+			name:     "empty patch",
+			patch:    `{}`,
+			expected: original,
+		},
+		{
+			name:  "not an object",
+			patch: `["properties"]`,
+			err:   "expected a JSON object for the merge patch",
+		},
+		{
+			name:  "invalid geometry",
+			patch: `{"geometry": {"type": "Point", "coordinates": "nope"}}`,
+			err:   "invalid patched feature: trouble decoding geometry",
+		},
+		{
+			name:  "invalid type",
+			patch: `{"type": "FeatureCollection"}`,
+			err:   "invalid patched feature",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			feature := &api.Feature{}
+			require.NoError(t, json.Unmarshal([]byte(original), feature))
+
+			patched, err := api.MergePatch(feature, []byte(c.patch))
+			if c.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := json.Marshal(patched)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(data))
+
+			// the original is not modified
+			data, err = json.Marshal(feature)
+			require.NoError(t, err)
+			assert.JSONEq(t, original, string(data))
+		})
+	}
+}
+
+func TestFeatureETag(t *testing.T) {
+	feature := &api.Feature{
+		Id:         "a",
+		Geometry:   &geometry.Point{Coordinates: []float64{1, 2}},
+		Properties: map[string]any{"name": "a", "count": 1},
+	}
+
+	etag, err := api.FeatureETag(feature)
+	require.NoError(t, err)
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	same, err := api.FeatureETag(&api.Feature{
+		Id:         "a",
+		Geometry:   &geometry.Point{Coordinates: []float64{1, 2}},
+		Properties: map[string]any{"count": 1, "name": "a"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, etag, same)
+
+	patched, err := api.MergePatch(feature, []byte(`{"properties": {"count": 2}}`))
+	require.NoError(t, err)
+	changed, err := api.FeatureETag(patched)
+	require.NoError(t, err)
+	assert.NotEqual(t, etag, changed)
+}
+
+func TestCheckIfMatch(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		etag   string
+		match  bool
+	}{
+		{name: "no header", header: "", etag: `"abc"`, match: true},
+		{name: "same tag", header: `"abc"`, etag: `"abc"`, match: true},
+		{name: "different tag", header: `"def"`, etag: `"abc"`, match: false},
+		{name: "list with tag", header: `"def", "abc"`, etag: `"abc"`, match: true},
+		{name: "list without tag", header: `"def", "ghi"`, etag: `"abc"`, match: false},
+		{name: "any", header: "*", etag: `"abc"`, match: true},
+		{name: "any without resource", header: "*", etag: "", match: false},
+		{name: "weak header tag", header: `W/"abc"`, etag: `"abc"`, match: false},
+		{name: "weak current tag", header: `"abc"`, etag: `W/"abc"`, match: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := api.CheckIfMatch(c.header, c.etag)
+			if c.match {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, api.ErrPreconditionFailed)
+			}
+		})
+	}
+}
@@ -15,9 +15,8 @@
 package api
 
 const (
-	crsEPSG3857 = "http://www.opengis.net/def/crs/EPSG/0/3857"
-	wkssGoogle  = "http://www.opengis.net/def/wkss/OGC/1.0/GoogleMapsCompatible"
-	tmsURIRoot  = "http://www.opengis.net/def/tilematrixset/OGC/1.0/"
+	wkssGoogle = "http://www.opengis.net/def/wkss/OGC/1.0/GoogleMapsCompatible"
+	tmsURIRoot = "http://www.opengis.net/def/tilematrixset/OGC/1.0/"
 )
 
 func getTileMatrixSetURI(id string) string {
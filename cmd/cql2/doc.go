@@ -0,0 +1,36 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Usage: cql2 <command>
+//
+// Flags:
+//
+//	-h, --help    Show context-sensitive help.
+//
+// Commands:
+//
+//	convert [<filter>] [flags]
+//	  Convert a filter between CQL2 Text and CQL2 JSON.
+//
+//	validate [<filter>] [flags]
+//	  Validate a filter.
+//
+//	normalize [<filter>] [flags]
+//	  Write the canonical form of a filter.
+//
+//	eval <features> [<filter>] [flags]
+//	  Write the features from a GeoJSON file that match a filter.
+//
+// Run "cql2 <command> --help" for more information on a command.
+package main
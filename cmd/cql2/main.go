@@ -0,0 +1,282 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/planetlabs/go-ogc/api"
+	"github.com/planetlabs/go-ogc/filter"
+)
+
+var CLI struct {
+	Convert   ConvertCmd   `cmd:"" help:"Convert a filter between CQL2 Text and CQL2 JSON."`
+	Validate  ValidateCmd  `cmd:"" help:"Validate a filter."`
+	Normalize NormalizeCmd `cmd:"" help:"Write the canonical form of a filter."`
+	Eval      EvalCmd      `cmd:"" help:"Write the features from a GeoJSON file that match a filter."`
+}
+
+const (
+	formatJSON = "json"
+	formatText = "text"
+)
+
+type ConvertCmd struct {
+	Filter string `arg:"" optional:"" help:"CQL2 Text or JSON filter.  Read from stdin if not provided or \"-\"."`
+	To     string `help:"Output format (json or text).  Defaults to the format that is not the input format." enum:"json,text," default:""`
+}
+
+func (c *ConvertCmd) Run() error {
+	return c.run(os.Stdin, os.Stdout)
+}
+
+func (c *ConvertCmd) run(stdin io.Reader, stdout io.Writer) error {
+	f, format, err := readFilter(c.Filter, stdin)
+	if err != nil {
+		return err
+	}
+
+	to := c.To
+	if to == "" {
+		to = formatJSON
+		if format == formatJSON {
+			to = formatText
+		}
+	}
+	return writeFilter(stdout, f, to)
+}
+
+type ValidateCmd struct {
+	Filter     string `arg:"" optional:"" help:"CQL2 Text or JSON filter.  Read from stdin if not provided or \"-\"."`
+	Queryables string `help:"Queryables document (a JSON schema) listing the properties that can be referenced." type:"existingfile"`
+}
+
+func (c *ValidateCmd) Run() error {
+	return c.run(os.Stdin, os.Stdout)
+}
+
+func (c *ValidateCmd) run(stdin io.Reader, stdout io.Writer) error {
+	f, _, err := readFilter(c.Filter, stdin)
+	if err != nil {
+		return err
+	}
+
+	if c.Queryables != "" {
+		queryables, err := readQueryables(c.Queryables)
+		if err != nil {
+			return err
+		}
+		if err := checkQueryables(f, queryables); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(stdout, "valid")
+	return err
+}
+
+type NormalizeCmd struct {
+	Filter string `arg:"" optional:"" help:"CQL2 Text or JSON filter.  Read from stdin if not provided or \"-\"."`
+	Format string `help:"Output format (json or text).  Defaults to the input format." enum:"json,text," default:""`
+}
+
+func (c *NormalizeCmd) Run() error {
+	return c.run(os.Stdin, os.Stdout)
+}
+
+func (c *NormalizeCmd) run(stdin io.Reader, stdout io.Writer) error {
+	f, format, err := readFilter(c.Filter, stdin)
+	if err != nil {
+		return err
+	}
+
+	if c.Format != "" {
+		format = c.Format
+	}
+	return writeFilter(stdout, filter.Normalize(f).(*filter.Filter), format)
+}
+
+type EvalCmd struct {
+	Features string `arg:"" help:"GeoJSON file with a FeatureCollection or a sequence of features.  Use \"-\" to read from stdin." type:"path"`
+	Filter   string `arg:"" optional:"" help:"CQL2 Text or JSON filter.  Read from stdin if not provided."`
+	Limit    int    `help:"Maximum number of features to write."`
+	Sequence bool   `help:"Write newline-delimited features instead of a FeatureCollection."`
+}
+
+func (c *EvalCmd) Run() error {
+	if c.Features == "-" && (c.Filter == "" || c.Filter == "-") {
+		return fmt.Errorf("the features and the filter cannot both be read from stdin")
+	}
+
+	f, _, err := readFilter(c.Filter, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	var input io.Reader = os.Stdin
+	if c.Features != "-" {
+		file, err := os.Open(c.Features)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	featureFilter := &api.FeatureFilter{
+		Filter:   f,
+		Limit:    c.Limit,
+		Sequence: c.Sequence,
+	}
+	counts, err := featureFilter.Apply(ctx, input, os.Stdout)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "matched %d features, returned %d\n", counts.Matched, counts.Returned)
+	return nil
+}
+
+// readFilter parses a CQL2 Text or JSON filter from an argument or from stdin.  The format of
+// the input is also returned.
+func readFilter(arg string, stdin io.Reader) (*filter.Filter, string, error) {
+	input := arg
+	if arg == "" || arg == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read filter from stdin: %w", err)
+		}
+		input = string(data)
+	}
+
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, "", fmt.Errorf("missing filter")
+	}
+
+	if strings.HasPrefix(input, "{") {
+		f := &filter.Filter{}
+		if err := f.UnmarshalJSON([]byte(input)); err != nil {
+			return nil, "", fmt.Errorf("failed to parse filter JSON: %w", err)
+		}
+		return f, formatJSON, nil
+	}
+
+	f, err := filter.ParseText(input)
+	if err != nil {
+		return nil, "", err
+	}
+	return f, formatText, nil
+}
+
+func writeFilter(w io.Writer, f *filter.Filter, format string) error {
+	if format == formatText {
+		text, err := filter.EncodeText(f)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, text)
+		return err
+	}
+
+	data, err := f.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode filter JSON: %w", err)
+	}
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, data); err != nil {
+		return fmt.Errorf("failed to encode filter JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, compacted.String())
+	return err
+}
+
+// queryables is the subset of a queryables document used for validation.
+type queryables struct {
+	Properties           map[string]any `json:"properties"`
+	AdditionalProperties *bool          `json:"additionalProperties"`
+}
+
+func readQueryables(path string) (*queryables, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	q := &queryables{}
+	if err := json.Unmarshal(data, q); err != nil {
+		return nil, fmt.Errorf("failed to parse queryables %q: %w", path, err)
+	}
+	return q, nil
+}
+
+// checkQueryables returns an error if the filter references properties that are not listed in
+// the queryables.  A property is listed if its full name or the first segment of its path is a
+// queryable.  Any property can be referenced if the queryables allow additional properties.
+func checkQueryables(f *filter.Filter, q *queryables) error {
+	if q.AdditionalProperties == nil || *q.AdditionalProperties {
+		return nil
+	}
+
+	unknown := map[string]bool{}
+	var pathErr error
+	filter.Walk(f, func(expr filter.Expression) bool {
+		property, ok := expr.(*filter.Property)
+		if !ok {
+			return true
+		}
+		if _, ok := q.Properties[property.Name]; ok {
+			return true
+		}
+		path, err := property.Path()
+		if err != nil {
+			pathErr = err
+			return false
+		}
+		if _, ok := q.Properties[path[0]]; !ok {
+			unknown[property.Name] = true
+		}
+		return true
+	})
+	if pathErr != nil {
+		return pathErr
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(unknown))
+	for name := range unknown {
+		names = append(names, fmt.Sprintf("%q", name))
+	}
+	sort.Strings(names)
+	return fmt.Errorf("filter references properties that are not queryable: %s", strings.Join(names, ", "))
+}
+
+func main() {
+	ctx := kong.Parse(&CLI, kong.UsageOnError())
+	err := ctx.Run()
+	ctx.FatalIfErrorf(err)
+}
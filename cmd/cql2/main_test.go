@@ -0,0 +1,193 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter string
+		stdin  string
+		to     string
+		output string
+		err    string
+	}{
+		{
+			name:   "text to json",
+			filter: "a = 1 AND b LIKE 'x%'",
+			output: `{"op":"and","args":[{"op":"=","args":[{"property":"a"},1]},{"op":"like","args":[{"property":"b"},"x%"]}]}` + "\n",
+		},
+		{
+			name:   "json to text",
+			filter: `{"op": "and", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "like", "args": [{"property": "b"}, "x%"]}]}`,
+			output: "a = 1 AND b LIKE 'x%'\n",
+		},
+		{
+			name:   "arithmetic",
+			filter: "a - 1 = b * 2",
+			output: `{"op":"=","args":[{"op":"-","args":[{"property":"a"},1]},{"op":"*","args":[{"property":"b"},2]}]}` + "\n",
+		},
+		{
+			name:   "text to text",
+			filter: "a=1",
+			to:     formatText,
+			output: "a = 1\n",
+		},
+		{
+			name:   "stdin",
+			filter: "-",
+			stdin:  "  a = 'b'\n",
+			output: `{"op":"=","args":[{"property":"a"},"b"]}` + "\n",
+		},
+		{
+			name:  "missing filter",
+			stdin: " \n",
+			err:   "missing filter",
+		},
+		{
+			name:   "invalid json",
+			filter: `{"op": "="`,
+			err:    "failed to parse filter JSON",
+		},
+		{
+			name:   "invalid text",
+			filter: "a = ",
+			err:    "trouble parsing CQL2 text",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := &ConvertCmd{Filter: c.filter, To: c.to}
+			output := &bytes.Buffer{}
+			err := cmd.run(strings.NewReader(c.stdin), output)
+			if c.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.output, output.String())
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter string
+		format string
+		output string
+	}{
+		{
+			name:   "text",
+			filter: "b = 2 AND a = 1",
+			output: "a = 1 AND b = 2\n",
+		},
+		{
+			name:   "json",
+			filter: `{"op": "or", "args": [{"op": "=", "args": [{"property": "b"}, 2]}, {"op": "=", "args": [{"property": "a"}, 1]}]}`,
+			output: `{"op":"or","args":[{"op":"=","args":[{"property":"a"},1]},{"op":"=","args":[{"property":"b"},2]}]}` + "\n",
+		},
+		{
+			name:   "text to json",
+			filter: "b = 2 AND a = 1",
+			format: formatJSON,
+			output: `{"op":"and","args":[{"op":"=","args":[{"property":"a"},1]},{"op":"=","args":[{"property":"b"},2]}]}` + "\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := &NormalizeCmd{Filter: c.filter, Format: c.format}
+			output := &bytes.Buffer{}
+			require.NoError(t, cmd.run(strings.NewReader(""), output))
+			assert.Equal(t, c.output, output.String())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	closed := filepath.Join(dir, "closed.json")
+	require.NoError(t, os.WriteFile(closed, []byte(`{
+		"properties": {"eo:cloud_cover": {"type": "number"}, "platform": {"type": "string"}},
+		"additionalProperties": false
+	}`), 0o600))
+	open := filepath.Join(dir, "open.json")
+	require.NoError(t, os.WriteFile(open, []byte(`{"properties": {}}`), 0o600))
+
+	cases := []struct {
+		name       string
+		filter     string
+		queryables string
+		err        string
+	}{
+		{
+			name:   "valid",
+			filter: "eo:cloud_cover < 10",
+		},
+		{
+			name:       "queryable",
+			filter:     "eo:cloud_cover < 10 AND platform = 'landsat-8'",
+			queryables: closed,
+		},
+		{
+			name:       "nested queryable",
+			filter:     "platform.name = 'landsat-8'",
+			queryables: closed,
+		},
+		{
+			name:       "additional properties",
+			filter:     "anything = 1",
+			queryables: open,
+		},
+		{
+			name:       "not queryable",
+			filter:     "size > 1 OR eo:cloud_cover < 10 OR color = 'red'",
+			queryables: closed,
+			err:        `filter references properties that are not queryable: "color", "size"`,
+		},
+		{
+			name:   "invalid",
+			filter: "eo:cloud_cover <",
+			err:    "trouble parsing CQL2 text: unexpected end of input at position 16",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := &ValidateCmd{Filter: c.filter, Queryables: c.queryables}
+			output := &bytes.Buffer{}
+			err := cmd.run(strings.NewReader(""), output)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "valid\n", output.String())
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package serve
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -23,6 +24,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/phayes/freeport"
+	"github.com/planetlabs/go-ogc/api"
 	"github.com/planetlabs/go-ogc/cmd/xyz2ogc/internal/common"
 	"github.com/sirupsen/logrus"
 )
@@ -44,6 +46,7 @@ func New(options *Options) (*Server, error) {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	e.HTTPErrorHandler = handleError
 
 	port := options.Port
 	if port == 0 {
@@ -177,5 +180,27 @@ func (s *Server) getProxyBase(c echo.Context) *url.URL {
 }
 
 func notFound(message string) error {
-	return echo.NewHTTPError(http.StatusNotFound, message)
+	return api.NotFound(message)
+}
+
+// handleError writes errors as OGC API exception documents.
+func handleError(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	exception := *api.ExceptionFromError(err)
+	httpErr := &echo.HTTPError{}
+	if errors.As(err, &httpErr) {
+		detail := fmt.Sprint(httpErr.Message)
+		if detail == http.StatusText(httpErr.Code) {
+			detail = ""
+		}
+		exception = *api.NewException(httpErr.Code, detail)
+	}
+	exception.Instance = c.Request().URL.Path
+
+	if writeErr := api.WriteException(c.Response(), c.Request(), &exception); writeErr != nil {
+		c.Logger().Error(writeErr)
+	}
 }
@@ -0,0 +1,289 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Normalize returns a canonical copy of an expression.  Logically equivalent expressions
+// that differ only in the order of commutative arguments, the nesting of logical operators,
+// the order of items in an in list, or the time zone of timestamps will have the same
+// canonical form.
+//
+// The canonical form applies these rules:
+//   - timestamps are converted to UTC and dates have no time of day
+//   - nested and/or expressions are flattened, and repeated arguments are removed
+//   - the arguments of and/or expressions and the items in in lists are sorted
+//   - the arguments of symmetric comparisons (e.g. "=" or "s_intersects") are sorted with
+//     property references first
+//   - comparisons with a converse (e.g. "<" and ">" or "t_before" and "t_after") are
+//     written so that the arguments are sorted in the same way
+//   - double negation is removed
+func Normalize(expr Expression) Expression {
+	switch e := expr.(type) {
+	case *Filter:
+		return &Filter{Expression: normalizeAs(e.Expression)}
+	case *Not:
+		arg := normalizeAs(e.Arg)
+		if not, ok := arg.(*Not); ok {
+			return not.Arg
+		}
+		return &Not{Arg: arg}
+	case *And:
+		args := normalizeLogical(e.Args, func(arg BooleanExpression) ([]BooleanExpression, bool) {
+			if and, ok := arg.(*And); ok {
+				return and.Args, true
+			}
+			return nil, false
+		})
+		if len(args) == 1 {
+			return args[0]
+		}
+		return &And{Args: args}
+	case *Or:
+		args := normalizeLogical(e.Args, func(arg BooleanExpression) ([]BooleanExpression, bool) {
+			if or, ok := arg.(*Or); ok {
+				return or.Args, true
+			}
+			return nil, false
+		})
+		if len(args) == 1 {
+			return args[0]
+		}
+		return &Or{Args: args}
+	case *Comparison:
+		name, left, right := normalizeBinary(e.Name, normalizeAs(e.Left), normalizeAs(e.Right), comparisonConverse)
+		return &Comparison{Name: name, Left: left, Right: right}
+	case *ArrayComparison:
+		name, left, right := normalizeBinary(e.Name, normalizeAs(e.Left), normalizeAs(e.Right), arrayConverse)
+		return &ArrayComparison{Name: name, Left: left, Right: right}
+	case *SpatialComparison:
+		name, left, right := normalizeBinary(e.Name, normalizeAs(e.Left), normalizeAs(e.Right), spatialConverse)
+		return &SpatialComparison{Name: name, Left: left, Right: right}
+	case *TemporalComparison:
+		name, left, right := normalizeBinary(e.Name, normalizeAs(e.Left), normalizeAs(e.Right), temporalConverse)
+		return &TemporalComparison{Name: name, Left: left, Right: right}
+	case *Like:
+		return &Like{Value: normalizeAs(e.Value), Pattern: normalizeAs(e.Pattern)}
+	case *Between:
+		return &Between{Value: normalizeAs(e.Value), Low: normalizeAs(e.Low), High: normalizeAs(e.High)}
+	case *In:
+		list := make(ScalarList, len(e.List))
+		for i, item := range e.List {
+			list[i] = normalizeAs(item)
+		}
+		return &In{Item: normalizeAs(e.Item), List: sortUnique(list)}
+	case ScalarList:
+		list := make(ScalarList, len(e))
+		for i, item := range e {
+			list[i] = normalizeAs(item)
+		}
+		return list
+	case *IsNull:
+		return &IsNull{Value: Normalize(e.Value)}
+	case *CaseInsensitive:
+		return &CaseInsensitive{Value: normalizeAs(e.Value)}
+	case *AccentInsensitive:
+		return &AccentInsensitive{Value: normalizeAs(e.Value)}
+	case *Function:
+		function := &Function{Op: e.Op}
+		if e.Args != nil {
+			function.Args = make([]Expression, len(e.Args))
+			for i, arg := range e.Args {
+				function.Args[i] = Normalize(arg)
+			}
+		}
+		return function
+	case Array:
+		array := make(Array, len(e))
+		for i, item := range e {
+			array[i] = normalizeAs(item)
+		}
+		return array
+	case *Interval:
+		return &Interval{Start: normalizeAs(e.Start), End: normalizeAs(e.End)}
+	case *Timestamp:
+		return &Timestamp{Value: e.Value.UTC()}
+	case *Date:
+		year, month, day := e.Value.Date()
+		return &Date{Value: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+	case *Number:
+		// adding zero turns negative zero into zero
+		return &Number{Value: e.Value + 0}
+	case *String:
+		return &String{Value: e.Value}
+	case *Boolean:
+		return &Boolean{Value: e.Value}
+	case *Property:
+		return &Property{Name: e.Name}
+	case *BoundingBox:
+		return &BoundingBox{Extent: slices.Clone(e.Extent)}
+	case *Geometry:
+		return &Geometry{Value: e.Value}
+	}
+	return expr
+}
+
+// Equal reports whether two expressions have the same canonical form.  See Normalize for details.
+func Equal(a, b Expression) bool {
+	return canonicalKey(a) == canonicalKey(b)
+}
+
+// Hash returns a stable hash of the canonical form of an expression.  Expressions that are
+// Equal have the same hash, so the result is suitable for use as a cache key.
+func Hash(expr Expression) string {
+	sum := sha256.Sum256([]byte(canonicalKey(expr)))
+	return hex.EncodeToString(sum[:])
+}
+
+func canonicalKey(expr Expression) string {
+	if f, ok := expr.(*Filter); ok {
+		return canonicalKey(f.Expression)
+	}
+	return expressionKey(Normalize(expr))
+}
+
+func expressionKey(expr Expression) string {
+	if expr == nil {
+		return "null"
+	}
+	data, err := json.Marshal(expr)
+	if err != nil {
+		return fmt.Sprintf("%#v", expr)
+	}
+	return string(data)
+}
+
+func normalizeAs[T Expression](expr T) T {
+	if any(expr) == nil {
+		return expr
+	}
+	normalized, ok := Normalize(expr).(T)
+	if !ok {
+		return expr
+	}
+	return normalized
+}
+
+func normalizeLogical(args []BooleanExpression, nested func(BooleanExpression) ([]BooleanExpression, bool)) []BooleanExpression {
+	flattened := []BooleanExpression{}
+	for _, arg := range args {
+		normalized := normalizeAs(arg)
+		if children, ok := nested(normalized); ok {
+			flattened = append(flattened, children...)
+			continue
+		}
+		flattened = append(flattened, normalized)
+	}
+	return sortUnique(flattened)
+}
+
+func sortUnique[T Expression](items []T) []T {
+	keys := make(map[string]T, len(items))
+	for _, item := range items {
+		keys[expressionKey(item)] = item
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	slices.Sort(sorted)
+
+	result := make([]T, len(sorted))
+	for i, key := range sorted {
+		result[i] = keys[key]
+	}
+	return result
+}
+
+// normalizeBinary orders the arguments of a binary operator so that property references come
+// first.  The converse table maps operator names to the name of the operator with swapped
+// arguments.  Symmetric operators map to themselves, and operators without a converse are not
+// in the table.
+func normalizeBinary[T Expression](name string, left T, right T, converse map[string]string) (string, T, T) {
+	swapped, ok := converse[name]
+	if !ok {
+		return name, left, right
+	}
+	if compareArgs(left, right) > 0 {
+		return swapped, right, left
+	}
+	return name, left, right
+}
+
+func compareArgs(left Expression, right Expression) int {
+	_, leftProperty := left.(*Property)
+	_, rightProperty := right.(*Property)
+	if leftProperty != rightProperty {
+		if leftProperty {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(expressionKey(left), expressionKey(right))
+}
+
+var comparisonConverse = map[string]string{
+	Equals:              Equals,
+	NotEquals:           NotEquals,
+	LessThan:            GreaterThan,
+	GreaterThan:         LessThan,
+	LessThanOrEquals:    GreaterThanOrEquals,
+	GreaterThanOrEquals: LessThanOrEquals,
+}
+
+var arrayConverse = map[string]string{
+	ArrayEquals:      ArrayEquals,
+	ArrayOverlaps:    ArrayOverlaps,
+	ArrayContains:    ArrayContainedBy,
+	ArrayContainedBy: ArrayContains,
+}
+
+var spatialConverse = map[string]string{
+	GeometryCrosses:    GeometryCrosses,
+	GeometryDisjoint:   GeometryDisjoint,
+	GeometryEquals:     GeometryEquals,
+	GeometryIntersects: GeometryIntersects,
+	GeometryOverlaps:   GeometryOverlaps,
+	GeometryTouches:    GeometryTouches,
+	GeometryContains:   GeometryWithin,
+	GeometryWithin:     GeometryContains,
+}
+
+var temporalConverse = map[string]string{
+	TimeDisjoint:     TimeDisjoint,
+	TimeEquals:       TimeEquals,
+	TimeIntersects:   TimeIntersects,
+	TimeAfter:        TimeBefore,
+	TimeBefore:       TimeAfter,
+	TimeContains:     TimeDuring,
+	TimeDuring:       TimeContains,
+	TimeFinishedBy:   TimeFinishes,
+	TimeFinishes:     TimeFinishedBy,
+	TimeMeets:        TimeMetBy,
+	TimeMetBy:        TimeMeets,
+	TimeOverlappedBy: TimeOverlaps,
+	TimeOverlaps:     TimeOverlappedBy,
+	TimeStartedBy:    TimeStarts,
+	TimeStarts:       TimeStartedBy,
+}
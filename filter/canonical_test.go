@@ -0,0 +1,215 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParseFilter(t *testing.T, data string) *filter.Filter {
+	f := &filter.Filter{}
+	require.NoError(t, json.Unmarshal([]byte(data), f))
+	return f
+}
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		name  string
+		a     string
+		b     string
+		equal bool
+	}{
+		{
+			name:  "key order",
+			a:     `{"op": "=", "args": [{"property": "a"}, 1]}`,
+			b:     `{"args": [{"property": "a"}, 1], "op": "="}`,
+			equal: true,
+		},
+		{
+			name:  "number formatting",
+			a:     `{"op": "=", "args": [{"property": "a"}, 1.50]}`,
+			b:     `{"op": "=", "args": [{"property": "a"}, 15e-1]}`,
+			equal: true,
+		},
+		{
+			name:  "negative zero",
+			a:     `{"op": "=", "args": [{"property": "a"}, -0]}`,
+			b:     `{"op": "=", "args": [{"property": "a"}, 0]}`,
+			equal: true,
+		},
+		{
+			name:  "timestamp zone",
+			a:     `{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-01-01T02:00:00+02:00"}]}`,
+			b:     `{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-01-01T00:00:00Z"}]}`,
+			equal: true,
+		},
+		{
+			name:  "interval timestamp zone",
+			a:     `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2023-01-01T02:00:00+02:00", ".."]}]}`,
+			b:     `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2023-01-01T00:00:00Z", ".."]}]}`,
+			equal: true,
+		},
+		{
+			name:  "symmetric comparison",
+			a:     `{"op": "=", "args": [{"property": "a"}, "x"]}`,
+			b:     `{"op": "=", "args": ["x", {"property": "a"}]}`,
+			equal: true,
+		},
+		{
+			name:  "converse comparison",
+			a:     `{"op": "<", "args": [{"property": "a"}, 10]}`,
+			b:     `{"op": ">", "args": [10, {"property": "a"}]}`,
+			equal: true,
+		},
+		{
+			name:  "converse spatial comparison",
+			a:     `{"op": "s_within", "args": [{"property": "geom"}, {"bbox": [0, 0, 1, 1]}]}`,
+			b:     `{"op": "s_contains", "args": [{"bbox": [0, 0, 1, 1]}, {"property": "geom"}]}`,
+			equal: true,
+		},
+		{
+			name:  "converse temporal comparison",
+			a:     `{"op": "t_before", "args": [{"property": "t"}, {"date": "2023-01-01"}]}`,
+			b:     `{"op": "t_after", "args": [{"date": "2023-01-01"}, {"property": "t"}]}`,
+			equal: true,
+		},
+		{
+			name:  "and order",
+			a:     `{"op": "and", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "=", "args": [{"property": "b"}, 2]}]}`,
+			b:     `{"op": "and", "args": [{"op": "=", "args": [{"property": "b"}, 2]}, {"op": "=", "args": [{"property": "a"}, 1]}]}`,
+			equal: true,
+		},
+		{
+			name: "nested and",
+			a: `{"op": "and", "args": [
+				{"op": "=", "args": [{"property": "a"}, 1]},
+				{"op": "and", "args": [
+					{"op": "=", "args": [{"property": "b"}, 2]},
+					{"op": "=", "args": [{"property": "c"}, 3]}
+				]}
+			]}`,
+			b: `{"op": "and", "args": [
+				{"op": "=", "args": [{"property": "c"}, 3]},
+				{"op": "=", "args": [{"property": "b"}, 2]},
+				{"op": "=", "args": [{"property": "a"}, 1]}
+			]}`,
+			equal: true,
+		},
+		{
+			name:  "repeated or args",
+			a:     `{"op": "or", "args": [{"op": "isNull", "args": [{"property": "a"}]}, {"op": "isNull", "args": [{"property": "a"}]}]}`,
+			b:     `{"op": "isNull", "args": [{"property": "a"}]}`,
+			equal: true,
+		},
+		{
+			name:  "double negation",
+			a:     `{"op": "not", "args": [{"op": "not", "args": [{"op": "isNull", "args": [{"property": "a"}]}]}]}`,
+			b:     `{"op": "isNull", "args": [{"property": "a"}]}`,
+			equal: true,
+		},
+		{
+			name:  "in list order",
+			a:     `{"op": "in", "args": [{"property": "a"}, ["x", "y", "z"]]}`,
+			b:     `{"op": "in", "args": [{"property": "a"}, ["z", "x", "y", "x"]]}`,
+			equal: true,
+		},
+		{
+			name:  "different values",
+			a:     `{"op": "=", "args": [{"property": "a"}, 1]}`,
+			b:     `{"op": "=", "args": [{"property": "a"}, 2]}`,
+			equal: false,
+		},
+		{
+			name:  "non-commutative order",
+			a:     `{"op": "<", "args": [{"property": "a"}, 10]}`,
+			b:     `{"op": "<", "args": [10, {"property": "a"}]}`,
+			equal: false,
+		},
+		{
+			name:  "function args order",
+			a:     `{"op": "f", "args": [1, 2]}`,
+			b:     `{"op": "f", "args": [2, 1]}`,
+			equal: false,
+		},
+		{
+			name:  "array order",
+			a:     `{"op": "a_equals", "args": [{"property": "a"}, [1, 2]]}`,
+			b:     `{"op": "a_equals", "args": [{"property": "a"}, [2, 1]]}`,
+			equal: false,
+		},
+		{
+			name:  "and versus or",
+			a:     `{"op": "and", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "=", "args": [{"property": "b"}, 2]}]}`,
+			b:     `{"op": "or", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "=", "args": [{"property": "b"}, 2]}]}`,
+			equal: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := mustParseFilter(t, c.a)
+			b := mustParseFilter(t, c.b)
+
+			assert.Equal(t, c.equal, filter.Equal(a, b))
+			assert.Equal(t, c.equal, filter.Equal(a.Expression, b.Expression))
+			if c.equal {
+				assert.Equal(t, filter.Hash(a), filter.Hash(b))
+			} else {
+				assert.NotEqual(t, filter.Hash(a), filter.Hash(b))
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	f := mustParseFilter(t, `{"op": "=", "args": [{"property": "a"}, 1]}`)
+	hash := filter.Hash(f)
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, filter.Hash(f.Expression))
+	assert.Equal(t, hash, filter.Hash(mustParseFilter(t, f.String())))
+}
+
+func TestNormalize(t *testing.T) {
+	f := mustParseFilter(t, `{"op": "and", "args": [
+		{"op": ">=", "args": [100, {"property": "b"}]},
+		{"op": "and", "args": [
+			{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-06-01T12:00:00-07:00"}]},
+			{"op": "in", "args": [{"property": "a"}, [3, 1, 2]]}
+		]}
+	]}`)
+
+	normalized := filter.Normalize(f)
+	require.IsType(t, &filter.Filter{}, normalized)
+
+	expected := `{"op": "and", "args": [
+		{"op": "<=", "args": [{"property": "b"}, 100]},
+		{"op": "in", "args": [{"property": "a"}, [1, 2, 3]]},
+		{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-06-01T19:00:00Z"}]}
+	]}`
+	assert.JSONEq(t, expected, normalized.String())
+
+	// timestamps are normalized to UTC when decoded
+	assert.Contains(t, f.String(), `{"timestamp":"2023-06-01T19:00:00Z"}`)
+	assert.NotContains(t, f.String(), "-07:00")
+
+	// the original is not modified
+	assert.Contains(t, f.String(), "[3,1,2]")
+}
@@ -41,6 +41,7 @@ var (
 	_ Expression          = (*CaseInsensitive)(nil)
 	_ CharacterExpression = (*CaseInsensitive)(nil)
 	_ PatternExpression   = (*CaseInsensitive)(nil)
+	_ ArrayItemExpression = (*CaseInsensitive)(nil)
 	_ json.Marshaler      = (*CaseInsensitive)(nil)
 )
 
@@ -48,6 +49,7 @@ func (*CaseInsensitive) expression()          {}
 func (*CaseInsensitive) scalarExpression()    {}
 func (*CaseInsensitive) characterExpression() {}
 func (*CaseInsensitive) patternExpression()   {}
+func (*CaseInsensitive) arrayItemExpression() {}
 
 func (e *CaseInsensitive) MarshalJSON() ([]byte, error) {
 	m := map[string]any{
@@ -70,6 +72,7 @@ var (
 	_ Expression          = (*AccentInsensitive)(nil)
 	_ CharacterExpression = (*AccentInsensitive)(nil)
 	_ PatternExpression   = (*AccentInsensitive)(nil)
+	_ ArrayItemExpression = (*AccentInsensitive)(nil)
 	_ json.Marshaler      = (*AccentInsensitive)(nil)
 )
 
@@ -77,6 +80,7 @@ func (*AccentInsensitive) expression()          {}
 func (*AccentInsensitive) scalarExpression()    {}
 func (*AccentInsensitive) characterExpression() {}
 func (*AccentInsensitive) patternExpression()   {}
+func (*AccentInsensitive) arrayItemExpression() {}
 
 func (e *AccentInsensitive) MarshalJSON() ([]byte, error) {
 	m := map[string]any{
@@ -62,6 +62,24 @@ func TestCharacter(t *testing.T) {
 				"args": [{"property": "soup"}, {"op": "accenti", "args": ["Chícken"]}]
 			}`,
 		},
+		{
+			filter: &filter.Filter{
+				Expression: &filter.In{
+					Item: &filter.CaseInsensitive{&filter.Property{"road"}},
+					List: filter.ScalarList{
+						&filter.CaseInsensitive{&filter.String{"Οδος"}},
+						&filter.AccentInsensitive{&filter.String{"Straße"}},
+					},
+				},
+			},
+			data: `{
+				"op": "in",
+				"args": [
+					{"op": "casei", "args": [{"property": "road"}]},
+					[{"op": "casei", "args": ["Οδος"]}, {"op": "accenti", "args": ["Straße"]}]
+				]
+			}`,
+		},
 	}
 
 	for i, c := range cases {
@@ -0,0 +1,666 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/planetlabs/go-ogc/geometry"
+)
+
+// ParseECQL parses a filter written in the Extended Common Query Language (ECQL) or the
+// older CQL1 syntax used by GeoServer and GeoTools.  The filter is mapped onto the CQL2
+// expression types:
+//   - BBOX(geom, minx, miny, maxx, maxy) is an s_intersects comparison with a bounding box
+//   - INTERSECTS, DISJOINT, CONTAINS, WITHIN, TOUCHES, CROSSES, OVERLAPS, and EQUALS are
+//     the corresponding spatial comparisons
+//   - BEFORE, AFTER, DURING, and TEQUALS are the corresponding temporal comparisons, and
+//     BEFORE OR DURING and DURING OR AFTER are combinations of these
+//   - ILIKE is a case-insensitive like comparison
+//   - IN ('a', 'b') without an attribute is an in comparison with the "id" property
+//   - INCLUDE and EXCLUDE are true and false
+//   - arithmetic expressions are functions named "+", "-", "*", and "/"
+//
+// Periods are written as start/end, start/duration, or duration/end, where durations are
+// ISO 8601 durations like P1D or PT12H.  DWITHIN, BEYOND, RELATE, EXISTS, and DOES-NOT-EXIST
+// are not supported.
+func ParseECQL(text string) (*Filter, error) {
+	p := &ecqlParser{}
+	p.parser = parser{
+		lexer:         &lexer{input: text, times: true},
+		grammar:       p,
+		comparisons:   ecqlComparisons,
+		continuations: ecqlPredicateContinuations,
+	}
+
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("trouble parsing ECQL: %w", err)
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("trouble parsing ECQL: %w", err)
+	}
+	if t.kind != tokenEOF {
+		return nil, fmt.Errorf("trouble parsing ECQL: %w", unexpectedToken(t))
+	}
+
+	return &Filter{Expression: expression}, nil
+}
+
+type ecqlParser struct {
+	parser
+}
+
+var ecqlComparisons = map[string]string{
+	"=":  Equals,
+	"<>": NotEquals,
+	"!=": NotEquals,
+	"<":  LessThan,
+	"<=": LessThanOrEquals,
+	">":  GreaterThan,
+	">=": GreaterThanOrEquals,
+}
+
+var ecqlSpatialComparisons = map[string]string{
+	"CONTAINS":   GeometryContains,
+	"CROSSES":    GeometryCrosses,
+	"DISJOINT":   GeometryDisjoint,
+	"EQUALS":     GeometryEquals,
+	"INTERSECTS": GeometryIntersects,
+	"OVERLAPS":   GeometryOverlaps,
+	"TOUCHES":    GeometryTouches,
+	"WITHIN":     GeometryWithin,
+}
+
+var ecqlGeometryTypes = map[string]bool{
+	"POINT":              true,
+	"LINESTRING":         true,
+	"POLYGON":            true,
+	"MULTIPOINT":         true,
+	"MULTILINESTRING":    true,
+	"MULTIPOLYGON":       true,
+	"GEOMETRYCOLLECTION": true,
+}
+
+var ecqlBBoxCRS = map[string]bool{
+	"EPSG:4326": true,
+	"CRS:84":    true,
+	"http://www.opengis.net/def/crs/OGC/1.3/CRS84": true,
+	"urn:ogc:def:crs:OGC:1.3:CRS84":                true,
+}
+
+// ecqlPredicateContinuations are tokens that can follow the first expression in a predicate.
+var ecqlPredicateContinuations = []string{
+	"=", "<>", "!=", "<", "<=", ">", ">=", "+", "-", "*", "/",
+	"NOT", "BETWEEN", "LIKE", "ILIKE", "IN", "IS", "BEFORE", "AFTER", "DURING", "TEQUALS", "EXISTS",
+}
+
+func (p *ecqlParser) parseKeywordPredicate(t token) (BooleanExpression, error) {
+	if t.kind != tokenIdentifier {
+		return nil, nil
+	}
+
+	keyword := strings.ToUpper(t.value)
+	switch keyword {
+	case "INCLUDE", "EXCLUDE":
+		_, _ = p.next()
+		return &Boolean{Value: keyword == "INCLUDE"}, nil
+	case "BBOX":
+		return p.parseBBox()
+	case "DWITHIN", "BEYOND", "RELATE":
+		return nil, fmt.Errorf("unsupported predicate %s at position %d", keyword, t.pos)
+	case "IN":
+		_, _ = p.next()
+		list, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		return &In{Item: &Property{Name: "id"}, List: list}, nil
+	}
+
+	if name, ok := ecqlSpatialComparisons[keyword]; ok {
+		state := p.state()
+		_, _ = p.next()
+		if open, err := p.accept("("); err == nil && open {
+			return p.parseSpatialComparison(name)
+		}
+		p.restore(state)
+	}
+	return nil, nil
+}
+
+func (p *ecqlParser) parseKeywordComparison(keyword string, left Expression, t token) (BooleanExpression, error) {
+	switch keyword {
+	case "BEFORE", "AFTER", "DURING", "TEQUALS":
+		_, _ = p.next()
+		return p.parseTemporalComparison(keyword, left)
+
+	case "EXISTS", "DOES":
+		return nil, fmt.Errorf("unsupported predicate %s at position %d", keyword, t.pos)
+	}
+	return nil, nil
+}
+
+// parseLike parses a pattern string.  ILIKE is a case-insensitive like comparison.
+func (p *ecqlParser) parseLike(keyword string, value CharacterExpression, t token) (BooleanExpression, error) {
+	patternToken, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if patternToken.kind != tokenString {
+		return nil, fmt.Errorf("expected a pattern string, found %s at position %d", patternToken, patternToken.pos)
+	}
+	pattern := &String{Value: patternToken.value}
+	if keyword == "ILIKE" {
+		return &Like{Value: &CaseInsensitive{Value: value}, Pattern: &CaseInsensitive{Value: pattern}}, nil
+	}
+	return &Like{Value: value, Pattern: pattern}, nil
+}
+
+func (p *ecqlParser) parseInList() (ScalarList, error) {
+	return p.parseList()
+}
+
+func (p *ecqlParser) parseList() (ScalarList, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	list := ScalarList{}
+	for {
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		item, ok := expression.(ScalarExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected a scalar expression for item %d of list", len(list))
+		}
+		list = append(list, item)
+
+		more, err := p.accept(",")
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (p *ecqlParser) parseTemporalComparison(keyword string, left Expression) (BooleanExpression, error) {
+	value, ok := left.(TemporalExpression)
+	if !ok {
+		return nil, fmt.Errorf("expected a temporal expression before %s", keyword)
+	}
+
+	// BEFORE OR DURING and DURING OR AFTER
+	var second string
+	switch keyword {
+	case "BEFORE", "DURING":
+		state := p.state()
+		or, err := p.accept("OR")
+		if err != nil {
+			return nil, err
+		}
+		if or {
+			next := "DURING"
+			if keyword == "DURING" {
+				next = "AFTER"
+			}
+			if ok, err := p.accept(next); err == nil && ok {
+				second = next
+			} else {
+				p.restore(state)
+			}
+		}
+	}
+
+	operand, err := p.parseTemporalOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{
+		"BEFORE":  TimeBefore,
+		"AFTER":   TimeAfter,
+		"DURING":  TimeDuring,
+		"TEQUALS": TimeEquals,
+	}
+	first := &TemporalComparison{Name: names[keyword], Left: value, Right: operand}
+	if second == "" {
+		return first, nil
+	}
+	return &Or{Args: []BooleanExpression{
+		first,
+		&TemporalComparison{Name: names[second], Left: value, Right: operand},
+	}}, nil
+}
+
+// parseTemporalOperand parses an instant, a period, or another temporal expression.
+func (p *ecqlParser) parseTemporalOperand() (TemporalExpression, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.kind == tokenTime {
+		_, _ = p.next()
+		start, err := parseECQLInstant(t)
+		if err != nil {
+			return nil, err
+		}
+		period, err := p.accept("/")
+		if err != nil {
+			return nil, err
+		}
+		if !period {
+			return start, nil
+		}
+
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenTime {
+			end, err := parseECQLInstant(t)
+			if err != nil {
+				return nil, err
+			}
+			return NewInterval(start, end)
+		}
+		if t.kind == tokenIdentifier && isECQLDuration(t.value) {
+			end, err := addECQLDuration(start, t, 1)
+			if err != nil {
+				return nil, err
+			}
+			return NewInterval(start, end)
+		}
+		return nil, fmt.Errorf("expected period end, found %s at position %d", t, t.pos)
+	}
+
+	if t.kind == tokenIdentifier && isECQLDuration(t.value) {
+		_, _ = p.next()
+		if err := p.expect("/"); err != nil {
+			return nil, err
+		}
+		endToken, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if endToken.kind != tokenTime {
+			return nil, fmt.Errorf("expected period end, found %s at position %d", endToken, endToken.pos)
+		}
+		end, err := parseECQLInstant(endToken)
+		if err != nil {
+			return nil, err
+		}
+		start, err := addECQLDuration(end, t, -1)
+		if err != nil {
+			return nil, err
+		}
+		return NewInterval(start, end)
+	}
+
+	expression, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	temporal, ok := expression.(TemporalExpression)
+	if !ok {
+		return nil, fmt.Errorf("expected a temporal expression at position %d", t.pos)
+	}
+	return temporal, nil
+}
+
+func (p *ecqlParser) parseSpatialComparison(name string) (BooleanExpression, error) {
+	left, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	right, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	spatialArgs, err := toSpatialArgs(name, []Expression{left, right})
+	if err != nil {
+		return nil, err
+	}
+	return &SpatialComparison{Name: name, Left: spatialArgs[0], Right: spatialArgs[1]}, nil
+}
+
+func (p *ecqlParser) parseBBox() (BooleanExpression, error) {
+	_, _ = p.next()
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	expression, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	value, ok := expression.(SpatialExpression)
+	if !ok {
+		return nil, errors.New("expected a spatial expression for arg 0 of BBOX")
+	}
+
+	extent := make([]float64, 4)
+	for i := range extent {
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		extent[i], err = p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	crs, err := p.accept(",")
+	if err != nil {
+		return nil, err
+	}
+	if crs {
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind != tokenString {
+			return nil, fmt.Errorf("expected a CRS string, found %s at position %d", t, t.pos)
+		}
+		if !ecqlBBoxCRS[t.value] {
+			return nil, fmt.Errorf("unsupported BBOX CRS %q", t.value)
+		}
+	}
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return &SpatialComparison{Name: GeometryIntersects, Left: value, Right: &BoundingBox{Extent: extent}}, nil
+}
+
+func (p *ecqlParser) parseNumber() (float64, error) {
+	t, err := p.peek()
+	if err != nil {
+		return 0, err
+	}
+	expression, err := p.parseFactor()
+	if err != nil {
+		return 0, err
+	}
+	n, ok := expression.(*Number)
+	if !ok {
+		return 0, fmt.Errorf("expected a number at position %d", t.pos)
+	}
+	return n.Value, nil
+}
+
+func (p *ecqlParser) parseExpression() (Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !t.is("+") && !t.is("-") {
+			return left, nil
+		}
+		_, _ = p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Function{Op: t.value, Args: []Expression{left, right}}
+	}
+}
+
+func (p *ecqlParser) parseTerm() (Expression, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !t.is("*") && !t.is("/") {
+			return left, nil
+		}
+		_, _ = p.next()
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &Function{Op: t.value, Args: []Expression{left, right}}
+	}
+}
+
+func (p *ecqlParser) parseFactor() (Expression, error) {
+	negative, err := p.accept("-")
+	if err != nil {
+		return nil, err
+	}
+	if !negative {
+		return p.parsePrimary()
+	}
+
+	expression, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	if n, ok := expression.(*Number); ok {
+		return &Number{Value: -n.Value}, nil
+	}
+	return &Function{Op: "*", Args: []Expression{&Number{Value: -1}, expression}}, nil
+}
+
+func (p *ecqlParser) parsePrimary() (Expression, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return &Number{Value: value}, nil
+
+	case tokenString:
+		return &String{Value: t.value}, nil
+
+	case tokenTime:
+		return parseECQLInstant(t)
+
+	case tokenQuotedIdentifier:
+		return &Property{Name: t.value}, nil
+
+	case tokenPunctuation:
+		if !t.is("(") {
+			break
+		}
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expression, nil
+
+	case tokenIdentifier:
+		return p.parseIdentifier(t)
+	}
+
+	return nil, unexpectedToken(t)
+}
+
+func (p *ecqlParser) parseIdentifier(t token) (Expression, error) {
+	keyword := strings.ToUpper(t.value)
+	switch keyword {
+	case "TRUE", "FALSE":
+		return &Boolean{Value: keyword == "TRUE"}, nil
+	}
+
+	next, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if ecqlGeometryTypes[keyword] && (next.is("(") || next.is("EMPTY") || next.is("Z") || next.is("M") || next.is("ZM")) {
+		text, err := p.lexer.scanGeometry(t.pos)
+		if err != nil {
+			return nil, err
+		}
+		p.peeked = nil
+		g, err := geometry.UnmarshalWKT(text)
+		if err != nil {
+			return nil, fmt.Errorf("trouble parsing geometry at position %d: %w", t.pos, err)
+		}
+		return &Geometry{Value: g}, nil
+	}
+
+	if !next.is("(") {
+		return &Property{Name: t.value}, nil
+	}
+	_, _ = p.next()
+
+	if keyword == "ENVELOPE" {
+		// ENVELOPE(west, east, north, south)
+		values := make([]float64, 4)
+		for i := range values {
+			if i > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			values[i], err = p.parseNumber()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &BoundingBox{Extent: []float64{values[0], values[3], values[1], values[2]}}, nil
+	}
+
+	function := &Function{Op: t.value}
+	closed, err := p.accept(")")
+	if err != nil {
+		return nil, err
+	}
+	for !closed {
+		arg, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		function.Args = append(function.Args, arg)
+
+		more, err := p.accept(",")
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			closed = true
+		}
+	}
+	return function, nil
+}
+
+func parseECQLInstant(t token) (InstantExpression, error) {
+	if len(t.value) == len(time.DateOnly) {
+		date, err := time.Parse(time.DateOnly, t.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q at position %d", t.value, t.pos)
+		}
+		return &Date{Value: date}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, t.value)
+	if err != nil {
+		// timestamps without an offset are UTC
+		timestamp, err = time.Parse("2006-01-02T15:04:05.999999999", t.value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q at position %d", t.value, t.pos)
+	}
+	return &Timestamp{Value: timestamp.UTC()}, nil
+}
+
+var ecqlDurationPattern = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+func isECQLDuration(value string) bool {
+	return value != "P" && !strings.HasSuffix(value, "T") && ecqlDurationPattern.MatchString(value)
+}
+
+// addECQLDuration adds (sign 1) or subtracts (sign -1) an ISO 8601 duration.  The result is
+// a date if the instant is a date and the duration has no time part.
+func addECQLDuration(instant InstantExpression, t token, sign int) (InstantExpression, error) {
+	parts := ecqlDurationPattern.FindStringSubmatch(t.value)
+	values := make([]float64, len(parts)-1)
+	for i, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q at position %d", t.value, t.pos)
+		}
+		values[i] = value * float64(sign)
+	}
+	years, months, weeks, days, hours, minutes, seconds := values[0], values[1], values[2], values[3], values[4], values[5], values[6]
+	hasTime := strings.Contains(t.value, "T")
+
+	var start time.Time
+	switch i := instant.(type) {
+	case *Date:
+		start = i.Value
+	case *Timestamp:
+		start = i.Value
+	}
+
+	end := start.AddDate(int(years), int(months), int(weeks)*7+int(days))
+	end = end.Add(time.Duration(hours*float64(time.Hour) + minutes*float64(time.Minute) + seconds*float64(time.Second)))
+
+	if _, ok := instant.(*Date); ok && !hasTime {
+		return &Date{Value: end}, nil
+	}
+	return &Timestamp{Value: end}, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseECQL(t *testing.T) {
+	cases := []struct {
+		ecql string
+		json string
+	}{
+		{
+			ecql: `BBOX(the_geom, -10, -10.5, 10, 10)`,
+			json: `{"op": "s_intersects", "args": [{"property": "the_geom"}, {"bbox": [-10, -10.5, 10, 10]}]}`,
+		},
+		{
+			ecql: `BBOX(the_geom, -10, -10, 10, 10, 'EPSG:4326')`,
+			json: `{"op": "s_intersects", "args": [{"property": "the_geom"}, {"bbox": [-10, -10, 10, 10]}]}`,
+		},
+		{
+			ecql: `INTERSECTS(geom, POINT(1 2))`,
+			json: `{"op": "s_intersects", "args": [{"property": "geom"}, {"type": "Point", "coordinates": [1, 2]}]}`,
+		},
+		{
+			ecql: `within(geom, POLYGON((0 0, 1 0, 1 1, 0 0)))`,
+			json: `{"op": "s_within", "args": [{"property": "geom"}, {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}]}`,
+		},
+		{
+			ecql: `DISJOINT(geom, ENVELOPE(-10, 10, 20, -20))`,
+			json: `{"op": "s_disjoint", "args": [{"property": "geom"}, {"bbox": [-10, -20, 10, 20]}]}`,
+		},
+		{
+			ecql: `dt DURING 2020-01-01T00:00:00Z/2021-01-01T00:00:00Z`,
+			json: `{"op": "t_during", "args": [{"property": "dt"}, {"interval": ["2020-01-01T00:00:00Z", "2021-01-01T00:00:00Z"]}]}`,
+		},
+		{
+			ecql: `dt DURING 2020-01-01T00:00:00Z/P1Y2M`,
+			json: `{"op": "t_during", "args": [{"property": "dt"}, {"interval": ["2020-01-01T00:00:00Z", "2021-03-01T00:00:00Z"]}]}`,
+		},
+		{
+			ecql: `dt DURING PT36H/2020-01-02T00:00:00Z`,
+			json: `{"op": "t_during", "args": [{"property": "dt"}, {"interval": ["2019-12-31T12:00:00Z", "2020-01-02T00:00:00Z"]}]}`,
+		},
+		{
+			ecql: `dt BEFORE 2006-11-30T01:30:00`,
+			json: `{"op": "t_before", "args": [{"property": "dt"}, {"timestamp": "2006-11-30T01:30:00Z"}]}`,
+		},
+		{
+			ecql: `dt AFTER 2006-11-30`,
+			json: `{"op": "t_after", "args": [{"property": "dt"}, {"date": "2006-11-30"}]}`,
+		},
+		{
+			ecql: `dt TEQUALS 2006-11-30T01:30:00+02:00`,
+			json: `{"op": "t_equals", "args": [{"property": "dt"}, {"timestamp": "2006-11-29T23:30:00Z"}]}`,
+		},
+		{
+			ecql: `dt BEFORE OR DURING 2020-01-01/2020-02-01`,
+			json: `{"op": "or", "args": [
+				{"op": "t_before", "args": [{"property": "dt"}, {"interval": ["2020-01-01", "2020-02-01"]}]},
+				{"op": "t_during", "args": [{"property": "dt"}, {"interval": ["2020-01-01", "2020-02-01"]}]}
+			]}`,
+		},
+		{
+			ecql: `dt DURING OR AFTER 2020-01-01/2020-02-01`,
+			json: `{"op": "or", "args": [
+				{"op": "t_during", "args": [{"property": "dt"}, {"interval": ["2020-01-01", "2020-02-01"]}]},
+				{"op": "t_after", "args": [{"property": "dt"}, {"interval": ["2020-01-01", "2020-02-01"]}]}
+			]}`,
+		},
+		{
+			ecql: `dt BEFORE 2020-01-01 OR dt AFTER 2021-01-01`,
+			json: `{"op": "or", "args": [
+				{"op": "t_before", "args": [{"property": "dt"}, {"date": "2020-01-01"}]},
+				{"op": "t_after", "args": [{"property": "dt"}, {"date": "2021-01-01"}]}
+			]}`,
+		},
+		{
+			ecql: `name = 'O''Brien' AND (cloud < 10 OR cloud IS NULL)`,
+			json: `{"op": "and", "args": [
+				{"op": "=", "args": [{"property": "name"}, "O'Brien"]},
+				{"op": "or", "args": [
+					{"op": "<", "args": [{"property": "cloud"}, 10]},
+					{"op": "isNull", "args": [{"property": "cloud"}]}
+				]}
+			]}`,
+		},
+		{
+			ecql: `NOT a <> 1 AND b != 2 OR c >= 3`,
+			json: `{"op": "or", "args": [
+				{"op": "and", "args": [
+					{"op": "not", "args": [{"op": "<>", "args": [{"property": "a"}, 1]}]},
+					{"op": "<>", "args": [{"property": "b"}, 2]}
+				]},
+				{"op": ">=", "args": [{"property": "c"}, 3]}
+			]}`,
+		},
+		{
+			ecql: `(a + 1) * 2 > b / 4`,
+			json: `{"op": ">", "args": [
+				{"op": "*", "args": [{"op": "+", "args": [{"property": "a"}, 1]}, 2]},
+				{"op": "/", "args": [{"property": "b"}, 4]}
+			]}`,
+		},
+		{
+			ecql: `a - -1.5e2 = 0`,
+			json: `{"op": "=", "args": [{"op": "-", "args": [{"property": "a"}, -150]}, 0]}`,
+		},
+		{
+			ecql: `depth BETWEEN 100 AND 200 AND depth NOT BETWEEN 120 AND 130`,
+			json: `{"op": "and", "args": [
+				{"op": "between", "args": [{"property": "depth"}, 100, 200]},
+				{"op": "not", "args": [{"op": "between", "args": [{"property": "depth"}, 120, 130]}]}
+			]}`,
+		},
+		{
+			ecql: `name LIKE 'abc%' OR name NOT ILIKE '%xyz'`,
+			json: `{"op": "or", "args": [
+				{"op": "like", "args": [{"property": "name"}, "abc%"]},
+				{"op": "not", "args": [{"op": "like", "args": [
+					{"op": "casei", "args": [{"property": "name"}]},
+					{"op": "casei", "args": ["%xyz"]}
+				]}]}
+			]}`,
+		},
+		{
+			ecql: `platform IN ('landsat-8', 'landsat-9') AND path NOT IN (1, 2)`,
+			json: `{"op": "and", "args": [
+				{"op": "in", "args": [{"property": "platform"}, ["landsat-8", "landsat-9"]]},
+				{"op": "not", "args": [{"op": "in", "args": [{"property": "path"}, [1, 2]]}]}
+			]}`,
+		},
+		{
+			ecql: `IN ('roads.1', 'roads.2')`,
+			json: `{"op": "in", "args": [{"property": "id"}, ["roads.1", "roads.2"]]}`,
+		},
+		{
+			ecql: `"my attribute" IS NOT NULL`,
+			json: `{"op": "not", "args": [{"op": "isNull", "args": [{"property": "my attribute"}]}]}`,
+		},
+		{
+			ecql: `strToUpperCase(ns:name) = 'ABC' AND flag = true`,
+			json: `{"op": "and", "args": [
+				{"op": "=", "args": [{"op": "strToUpperCase", "args": [{"property": "ns:name"}]}, "ABC"]},
+				{"op": "=", "args": [{"property": "flag"}, true]}
+			]}`,
+		},
+		{
+			ecql: `INCLUDE`,
+			json: `true`,
+		},
+		{
+			ecql: `EXCLUDE OR isActive()`,
+			json: `{"op": "or", "args": [false, {"op": "isActive", "args": []}]}`,
+		},
+	}
+
+	schema := getSchema(t)
+	for _, c := range cases {
+		t.Run(c.ecql, func(t *testing.T) {
+			f, err := filter.ParseECQL(c.ecql)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.json, f.String())
+
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(f.String()), &v))
+			assert.NoError(t, schema.Validate(v))
+		})
+	}
+}
+
+func TestParseECQLErrors(t *testing.T) {
+	cases := []struct {
+		ecql string
+		err  string
+	}{
+		{
+			ecql: `name = 'abc`,
+			err:  "trouble parsing ECQL: unterminated quote starting at position 7",
+		},
+		{
+			ecql: `a = 1 b`,
+			err:  "trouble parsing ECQL: unexpected b at position 6",
+		},
+		{
+			ecql: `a =`,
+			err:  "trouble parsing ECQL: unexpected end of input at position 3",
+		},
+		{
+			ecql: `(a = 1`,
+			err:  `trouble parsing ECQL: expected ")", found end of input at position 6`,
+		},
+		{
+			ecql: `DWITHIN(geom, POINT(1 2), 10, meters)`,
+			err:  "trouble parsing ECQL: unsupported predicate DWITHIN at position 0",
+		},
+		{
+			ecql: `BBOX(geom, 0, 0, 1, 1, 'EPSG:3857')`,
+			err:  `trouble parsing ECQL: unsupported BBOX CRS "EPSG:3857"`,
+		},
+		{
+			ecql: `INTERSECTS(geom, POLYGON((0 0, 1 0, 1 1, 0 1)))`,
+			err:  "trouble parsing ECQL: trouble parsing geometry at position 17: invalid polygon ring 0: ring is not closed",
+		},
+		{
+			ecql: `name LIKE other`,
+			err:  "trouble parsing ECQL: expected a pattern string, found other at position 10",
+		},
+		{
+			ecql: `a = 1 # 2`,
+			err:  "trouble parsing ECQL: unexpected character '#' at position 6",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.ecql, func(t *testing.T) {
+			_, err := filter.ParseECQL(c.ecql)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
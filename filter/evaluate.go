@@ -0,0 +1,768 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/planetlabs/go-ogc/geometry"
+)
+
+// GeometryProvider is implemented by values that can be used in spatial comparisons.
+type GeometryProvider interface {
+	Geometry() geometry.Geometry
+}
+
+// Evaluate reports whether the data provided by the resolver matches the expression.
+//
+// Property values may be strings, numbers, booleans, time.Time values, slices, geometries
+// (or values implementing GeometryProvider), and generic JSON (as produced by json.Unmarshal).
+// Strings are parsed as dates or timestamps when they are compared with temporal values,
+// and GeoJSON objects are decoded when they are used in spatial comparisons.
+//
+// A comparison with a missing or null property value is unknown, as in SQL.  It does not match,
+// and neither does its negation, so "NOT (x = 5)" and "x <> 5" both exclude items without x.
+// Values of different types are never equal.  An error is returned for expressions that cannot be evaluated
+// (e.g. unsupported functions or invalid property names).
+func Evaluate(expr BooleanExpression, resolver PropertyResolver) (bool, error) {
+	e := &evaluator{resolver: resolver}
+	return e.boolean(expr)
+}
+
+type evaluator struct {
+	resolver PropertyResolver
+}
+
+// date is a calendar date.  It covers the whole day when used as a temporal value.
+type date struct {
+	value time.Time
+}
+
+// interval is a temporal value with inclusive bounds.  A zero bound is unbounded.
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// truth is the result of a boolean expression.  Comparisons with a null or missing operand
+// are unknown, and unknown results propagate through the logical operators as in SQL.
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(value bool) truth {
+	if value {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func (e *evaluator) boolean(expr BooleanExpression) (bool, error) {
+	result, err := e.logical(expr)
+	return result == truthTrue, err
+}
+
+func (e *evaluator) logical(expr BooleanExpression) (truth, error) {
+	switch t := expr.(type) {
+	case *Filter:
+		return e.logical(t.Expression)
+	case *Boolean:
+		return truthOf(t.Value), nil
+	case *Not:
+		result, err := e.logical(t.Arg)
+		switch result {
+		case truthTrue:
+			return truthFalse, err
+		case truthFalse:
+			return truthTrue, err
+		}
+		return result, err
+	case *And:
+		result := truthTrue
+		for _, arg := range t.Args {
+			argResult, err := e.logical(arg)
+			if err != nil || argResult == truthFalse {
+				return truthFalse, err
+			}
+			if argResult == truthUnknown {
+				result = truthUnknown
+			}
+		}
+		return result, nil
+	case *Or:
+		result := truthFalse
+		for _, arg := range t.Args {
+			argResult, err := e.logical(arg)
+			if err != nil || argResult == truthTrue {
+				return argResult, err
+			}
+			if argResult == truthUnknown {
+				result = truthUnknown
+			}
+		}
+		return result, nil
+	case *Comparison:
+		return e.comparison(t)
+	case *Like:
+		return e.like(t)
+	case *Between:
+		return e.between(t)
+	case *In:
+		return e.in(t)
+	case *IsNull:
+		value, err := e.value(t.Value)
+		return truthOf(value == nil), err
+	case *ArrayComparison:
+		return e.arrayComparison(t)
+	case *SpatialComparison:
+		return e.spatialComparison(t)
+	case *TemporalComparison:
+		return e.temporalComparison(t)
+	case *Function:
+		return truthFalse, fmt.Errorf("unsupported function %q", t.Op)
+	}
+	return truthFalse, fmt.Errorf("cannot evaluate %s", describe(expr))
+}
+
+// value returns the normalized value of an expression.  The result is nil for null or missing values.
+func (e *evaluator) value(expr Expression) (any, error) {
+	switch t := expr.(type) {
+	case *Property:
+		path, err := t.Path()
+		if err != nil {
+			return nil, err
+		}
+		value, ok := e.resolver.ResolveProperty(path)
+		if !ok {
+			return nil, nil
+		}
+		return normalizeValue(value), nil
+	case *String:
+		return t.Value, nil
+	case *Number:
+		return t.Value, nil
+	case *Boolean:
+		return t.Value, nil
+	case *Date:
+		return date{value: t.Value}, nil
+	case *Timestamp:
+		return t.Value, nil
+	case *Interval:
+		return e.interval(t)
+	case *Geometry:
+		return t.Value, nil
+	case *BoundingBox:
+		return boundingBoxGeometry(t.Extent)
+	case Array:
+		values := make([]any, len(t))
+		for i, item := range t {
+			value, err := e.value(item)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	case *CaseInsensitive:
+		return e.fold(t.Value, FoldCase)
+	case *AccentInsensitive:
+		return e.fold(t.Value, FoldAccents)
+	case *Function:
+		return e.function(t)
+	case BooleanExpression:
+		result, err := e.logical(t)
+		if err != nil || result == truthUnknown {
+			return nil, err
+		}
+		return result == truthTrue, nil
+	}
+	return nil, fmt.Errorf("cannot evaluate %s", describe(expr))
+}
+
+func (e *evaluator) fold(expr Expression, fold func(string) string) (any, error) {
+	value, err := e.value(expr)
+	if err != nil {
+		return nil, err
+	}
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	return fold(str), nil
+}
+
+func (e *evaluator) function(f *Function) (any, error) {
+	var operation func(a, b float64) float64
+	switch f.Op {
+	case "+":
+		operation = func(a, b float64) float64 { return a + b }
+	case "-":
+		operation = func(a, b float64) float64 { return a - b }
+	case "*":
+		operation = func(a, b float64) float64 { return a * b }
+	case "/":
+		operation = func(a, b float64) float64 { return a / b }
+	case "%":
+		operation = math.Mod
+	case "^":
+		operation = math.Pow
+	case "div":
+		operation = func(a, b float64) float64 { return math.Trunc(a / b) }
+	default:
+		return nil, fmt.Errorf("unsupported function %q", f.Op)
+	}
+	if len(f.Args) != 2 {
+		return nil, fmt.Errorf("expected 2 arguments for %q, got %d", f.Op, len(f.Args))
+	}
+
+	left, err := e.value(f.Args[0])
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.value(f.Args[1])
+	if err != nil {
+		return nil, err
+	}
+	a, aOk := left.(float64)
+	b, bOk := right.(float64)
+	if !aOk || !bOk {
+		return nil, nil
+	}
+	return operation(a, b), nil
+}
+
+// normalizeValue converts numbers to float64 and slices to []any.
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string, float64, bool, time.Time, geometry.Geometry, map[string]any:
+		return v
+	case json.Number:
+		number, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+		return number
+	case GeometryProvider:
+		return v.Geometry()
+	case []any:
+		values := make([]any, len(v))
+		for i, item := range v {
+			values[i] = normalizeValue(item)
+		}
+		return values
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(reflected.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(reflected.Uint())
+	case reflect.Float32, reflect.Float64:
+		return reflected.Float()
+	case reflect.String:
+		return reflected.String()
+	case reflect.Bool:
+		return reflected.Bool()
+	case reflect.Pointer, reflect.Interface:
+		if reflected.IsNil() {
+			return nil
+		}
+		return normalizeValue(reflected.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if reflected.Kind() == reflect.Slice && reflected.IsNil() {
+			return nil
+		}
+		values := make([]any, reflected.Len())
+		for i := range values {
+			values[i] = normalizeValue(reflected.Index(i).Interface())
+		}
+		return values
+	}
+	return value
+}
+
+// instant returns a time for a scalar comparison.  Strings are parsed as dates or timestamps.
+func instant(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, true
+	case date:
+		return v.value, true
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, true
+		}
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compareValues compares two values of the same type.  The boolean result is false if the
+// values cannot be compared.  If ordered is false, only equality is meaningful.
+func compareValues(a, b any) (result int, ordered bool, ok bool) {
+	switch x := a.(type) {
+	case float64:
+		if y, isNumber := b.(float64); isNumber {
+			return compareOrdered(x, y), true, true
+		}
+	case string:
+		if y, isString := b.(string); isString {
+			return strings.Compare(x, y), true, true
+		}
+	case bool:
+		if y, isBool := b.(bool); isBool {
+			if x == y {
+				return 0, false, true
+			}
+			return 1, false, true
+		}
+	}
+
+	x, aOk := instant(a)
+	y, bOk := instant(b)
+	if aOk && bOk {
+		return x.Compare(y), true, true
+	}
+	return 0, false, false
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func valuesEqual(a, b any) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if x, ok := a.([]any); ok {
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !valuesEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	result, _, ok := compareValues(a, b)
+	return ok && result == 0
+}
+
+func (e *evaluator) comparison(c *Comparison) (truth, error) {
+	left, err := e.value(c.Left)
+	if err != nil {
+		return truthFalse, err
+	}
+	right, err := e.value(c.Right)
+	if err != nil {
+		return truthFalse, err
+	}
+	if left == nil || right == nil {
+		return truthUnknown, nil
+	}
+
+	result, ordered, ok := compareValues(left, right)
+	switch c.Name {
+	case Equals:
+		return truthOf(ok && result == 0), nil
+	case NotEquals:
+		return truthOf(!ok || result != 0), nil
+	}
+	if !ok || !ordered {
+		return truthFalse, nil
+	}
+	switch c.Name {
+	case LessThan:
+		return truthOf(result < 0), nil
+	case LessThanOrEquals:
+		return truthOf(result <= 0), nil
+	case GreaterThan:
+		return truthOf(result > 0), nil
+	case GreaterThanOrEquals:
+		return truthOf(result >= 0), nil
+	}
+	return truthFalse, fmt.Errorf("unsupported comparison %q", c.Name)
+}
+
+func (e *evaluator) like(l *Like) (truth, error) {
+	// casei and accenti are applied by the pattern so that each character is folded separately
+	valueExpr, valueCase, valueAccent := unwrapInsensitive(l.Value)
+	patternExpr, patternCase, patternAccent := unwrapInsensitive(l.Pattern)
+
+	value, err := e.value(valueExpr)
+	if err != nil {
+		return truthFalse, err
+	}
+	pattern, err := e.value(patternExpr)
+	if err != nil {
+		return truthFalse, err
+	}
+	if value == nil || pattern == nil {
+		return truthUnknown, nil
+	}
+	str, ok := value.(string)
+	if !ok {
+		return truthFalse, nil
+	}
+	patternStr, ok := pattern.(string)
+	if !ok {
+		return truthFalse, nil
+	}
+	compiled := NewLikePattern(patternStr, valueCase || patternCase, valueAccent || patternAccent)
+	return truthOf(compiled.Match(str)), nil
+}
+
+func (e *evaluator) between(b *Between) (truth, error) {
+	values := make([]float64, 3)
+	for i, expr := range []Expression{b.Value, b.Low, b.High} {
+		value, err := e.value(expr)
+		if err != nil {
+			return truthFalse, err
+		}
+		if value == nil {
+			return truthUnknown, nil
+		}
+		number, ok := value.(float64)
+		if !ok {
+			return truthFalse, nil
+		}
+		values[i] = number
+	}
+	return truthOf(values[1] <= values[0] && values[0] <= values[2]), nil
+}
+
+func (e *evaluator) in(in *In) (truth, error) {
+	item, err := e.value(in.Item)
+	if err != nil {
+		return truthFalse, err
+	}
+	if item == nil {
+		return truthUnknown, nil
+	}
+	for _, expr := range in.List {
+		value, err := e.value(expr)
+		if err != nil {
+			return truthFalse, err
+		}
+		if valuesEqual(item, value) {
+			return truthTrue, nil
+		}
+	}
+	return truthFalse, nil
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if valuesEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *evaluator) arrayComparison(c *ArrayComparison) (truth, error) {
+	left, err := e.value(c.Left)
+	if err != nil {
+		return truthFalse, err
+	}
+	right, err := e.value(c.Right)
+	if err != nil {
+		return truthFalse, err
+	}
+	if left == nil || right == nil {
+		return truthUnknown, nil
+	}
+	a, aOk := left.([]any)
+	b, bOk := right.([]any)
+	if !aOk || !bOk {
+		return truthFalse, nil
+	}
+
+	switch c.Name {
+	case ArrayEquals:
+		return truthOf(valuesEqual(a, b)), nil
+	case ArrayContains:
+		a, b = b, a
+		fallthrough
+	case ArrayContainedBy:
+		for _, item := range a {
+			if !containsValue(b, item) {
+				return truthFalse, nil
+			}
+		}
+		return truthTrue, nil
+	case ArrayOverlaps:
+		for _, item := range a {
+			if containsValue(b, item) {
+				return truthTrue, nil
+			}
+		}
+		return truthFalse, nil
+	}
+	return truthFalse, fmt.Errorf("unsupported array comparison %q", c.Name)
+}
+
+func boundingBoxGeometry(extent []float64) (geometry.Geometry, error) {
+	var minX, minY, maxX, maxY float64
+	switch len(extent) {
+	case 4:
+		minX, minY, maxX, maxY = extent[0], extent[1], extent[2], extent[3]
+	case 6:
+		minX, minY, maxX, maxY = extent[0], extent[1], extent[3], extent[4]
+	default:
+		return nil, fmt.Errorf("expected 4 or 6 values for a bounding box, got %d", len(extent))
+	}
+
+	rectangle := func(minX, maxX float64) [][][]float64 {
+		return [][][]float64{{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}, {minX, minY}}}
+	}
+	if minX > maxX {
+		// the box crosses the antimeridian
+		return &geometry.MultiPolygon{Coordinates: [][][][]float64{rectangle(minX, 180), rectangle(-180, maxX)}}, nil
+	}
+	return &geometry.Polygon{Coordinates: rectangle(minX, maxX)}, nil
+}
+
+func (e *evaluator) geometry(expr SpatialExpression) (geometry.Geometry, error) {
+	value, err := e.value(expr)
+	if err != nil {
+		return nil, err
+	}
+	switch v := value.(type) {
+	case geometry.Geometry:
+		return v, nil
+	case map[string]any:
+		g, err := geometry.Decode(v)
+		if err != nil {
+			return nil, nil
+		}
+		return g, nil
+	case string:
+		g, err := geometry.UnmarshalWKT(v)
+		if err != nil {
+			return nil, nil
+		}
+		return g, nil
+	}
+	return nil, nil
+}
+
+var spatialPredicates = map[string]func(a, b geometry.Geometry) bool{
+	GeometryContains:   geometry.Contains,
+	GeometryCrosses:    geometry.Crosses,
+	GeometryDisjoint:   geometry.Disjoint,
+	GeometryEquals:     geometry.Equals,
+	GeometryIntersects: geometry.Intersects,
+	GeometryOverlaps:   geometry.Overlaps,
+	GeometryTouches:    geometry.Touches,
+	GeometryWithin:     geometry.Within,
+}
+
+func (e *evaluator) spatialComparison(c *SpatialComparison) (truth, error) {
+	predicate, ok := spatialPredicates[c.Name]
+	if !ok {
+		return truthFalse, fmt.Errorf("unsupported spatial comparison %q", c.Name)
+	}
+	left, err := e.geometry(c.Left)
+	if err != nil {
+		return truthFalse, err
+	}
+	right, err := e.geometry(c.Right)
+	if err != nil {
+		return truthFalse, err
+	}
+	if left == nil || right == nil {
+		return truthUnknown, nil
+	}
+	return truthOf(predicate(left, right)), nil
+}
+
+// temporalValue converts a value into an interval.  Instants have equal bounds, and dates
+// cover the whole day.
+func temporalValue(value any) (interval, bool) {
+	switch v := value.(type) {
+	case interval:
+		return v, true
+	case time.Time:
+		return interval{start: v, end: v}, true
+	case date:
+		return interval{start: v.value, end: v.value.AddDate(0, 0, 1).Add(-time.Nanosecond)}, true
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return interval{start: t, end: t}, true
+		}
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			return temporalValue(date{value: t})
+		}
+	case []any:
+		if len(v) != 2 {
+			return interval{}, false
+		}
+		start, startOk := intervalBound(v[0])
+		end, endOk := intervalBound(v[1])
+		if !startOk || !endOk {
+			return interval{}, false
+		}
+		return interval{start: start.start, end: end.end}, true
+	}
+	return interval{}, false
+}
+
+func intervalBound(value any) (interval, bool) {
+	if value == nil || value == ".." {
+		return interval{}, true
+	}
+	return temporalValue(value)
+}
+
+func (e *evaluator) interval(i *Interval) (any, error) {
+	result := interval{}
+	for _, bound := range []struct {
+		expr  InstantExpression
+		start bool
+	}{{i.Start, true}, {i.End, false}} {
+		if bound.expr == nil {
+			continue
+		}
+		value, err := e.value(bound.expr)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil || value == ".." {
+			continue
+		}
+		t, ok := temporalValue(value)
+		if !ok {
+			return nil, nil
+		}
+		if bound.start {
+			result.start = t.start
+		} else {
+			result.end = t.end
+		}
+	}
+	return result, nil
+}
+
+// compareStart compares interval starts, where an unbounded start is earliest.
+func compareStart(a, b time.Time) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return -1
+	case b.IsZero():
+		return 1
+	}
+	return a.Compare(b)
+}
+
+// compareEnd compares interval ends, where an unbounded end is latest.
+func compareEnd(a, b time.Time) int {
+	switch {
+	case a.IsZero() && b.IsZero():
+		return 0
+	case a.IsZero():
+		return 1
+	case b.IsZero():
+		return -1
+	}
+	return a.Compare(b)
+}
+
+// compareEndStart compares the end of one interval with the start of another.
+func compareEndStart(end, start time.Time) int {
+	if end.IsZero() || start.IsZero() {
+		return 1
+	}
+	return end.Compare(start)
+}
+
+func (e *evaluator) temporalComparison(c *TemporalComparison) (truth, error) {
+	left, err := e.value(c.Left)
+	if err != nil {
+		return truthFalse, err
+	}
+	right, err := e.value(c.Right)
+	if err != nil {
+		return truthFalse, err
+	}
+	if left == nil || right == nil {
+		return truthUnknown, nil
+	}
+	a, aOk := temporalValue(left)
+	b, bOk := temporalValue(right)
+	if !aOk || !bOk {
+		return truthFalse, nil
+	}
+
+	starts := compareStart(a.start, b.start)
+	ends := compareEnd(a.end, b.end)
+	switch c.Name {
+	case TimeAfter:
+		return truthOf(compareEndStart(b.end, a.start) < 0), nil
+	case TimeBefore:
+		return truthOf(compareEndStart(a.end, b.start) < 0), nil
+	case TimeContains:
+		return truthOf(starts < 0 && ends > 0), nil
+	case TimeDisjoint:
+		return truthOf(compareEndStart(a.end, b.start) < 0 || compareEndStart(b.end, a.start) < 0), nil
+	case TimeDuring:
+		return truthOf(starts > 0 && ends < 0), nil
+	case TimeEquals:
+		return truthOf(starts == 0 && ends == 0), nil
+	case TimeFinishedBy:
+		return truthOf(starts < 0 && ends == 0), nil
+	case TimeFinishes:
+		return truthOf(starts > 0 && ends == 0), nil
+	case TimeIntersects:
+		return truthOf(compareEndStart(a.end, b.start) >= 0 && compareEndStart(b.end, a.start) >= 0), nil
+	case TimeMeets:
+		return truthOf(compareEndStart(a.end, b.start) == 0), nil
+	case TimeMetBy:
+		return truthOf(compareEndStart(b.end, a.start) == 0), nil
+	case TimeOverlappedBy:
+		return truthOf(starts > 0 && compareEndStart(b.end, a.start) > 0 && ends > 0), nil
+	case TimeOverlaps:
+		return truthOf(starts < 0 && compareEndStart(a.end, b.start) > 0 && ends < 0), nil
+	case TimeStartedBy:
+		return truthOf(starts == 0 && ends > 0), nil
+	case TimeStarts:
+		return truthOf(starts == 0 && ends < 0), nil
+	}
+	return truthFalse, fmt.Errorf("unsupported temporal comparison %q", c.Name)
+}
@@ -0,0 +1,179 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonResolver resolves properties from generic JSON data.
+type jsonResolver map[string]any
+
+func (r jsonResolver) ResolveProperty(path filter.PropertyPath) (any, bool) {
+	return path.Resolve(map[string]any(r))
+}
+
+func TestEvaluate(t *testing.T) {
+	data := jsonResolver{
+		"name":     "Hello World",
+		"city":     "Chiça",
+		"road":     "STRASSE",
+		"count":    float64(42),
+		"ratio":    0.5,
+		"enabled":  true,
+		"nothing":  nil,
+		"created":  "2020-06-15T12:00:00Z",
+		"day":      "2020-06-15",
+		"tags":     []any{"a", "b", "c"},
+		"interval": []any{"2020-01-01T00:00:00Z", ".."},
+		"geometry": map[string]any{"type": "Point", "coordinates": []any{float64(5), float64(5)}},
+		"bands":    []any{map[string]any{"name": "red"}, map[string]any{"name": "green"}},
+	}
+
+	cases := []struct {
+		filter   string
+		expected bool
+	}{
+		{`{"op": "=", "args": [{"property": "name"}, "Hello World"]}`, true},
+		{`{"op": "=", "args": [{"property": "name"}, "hello world"]}`, false},
+		{`{"op": "=", "args": [{"op": "casei", "args": [{"property": "name"}]}, {"op": "casei", "args": ["hello WORLD"]}]}`, true},
+		{`{"op": "=", "args": [{"property": "city"}, "Chica"]}`, false},
+		{`{"op": "=", "args": [{"op": "accenti", "args": [{"property": "city"}]}, {"op": "accenti", "args": ["Chica"]}]}`, true},
+		{`{"op": "=", "args": [{"op": "accenti", "args": [{"property": "city"}]}, {"op": "accenti", "args": ["chica"]}]}`, false},
+		{`{"op": "=", "args": [{"op": "casei", "args": [{"op": "accenti", "args": [{"property": "city"}]}]}, {"op": "casei", "args": [{"op": "accenti", "args": ["CHICA"]}]}]}`, true},
+		{`{"op": "in", "args": [{"op": "casei", "args": [{"property": "road"}]}, [{"op": "casei", "args": ["Οδος"]}, {"op": "casei", "args": ["Straße"]}]]}`, true},
+		{`{"op": "like", "args": [{"op": "accenti", "args": [{"property": "city"}]}, {"op": "accenti", "args": ["Chi_a"]}]}`, true},
+		{`{"op": "like", "args": [{"op": "casei", "args": [{"property": "name"}]}, {"op": "casei", "args": ["hello%"]}]}`, true},
+		{`{"op": "like", "args": [{"op": "casei", "args": [{"property": "road"}]}, {"op": "casei", "args": ["stra_e"]}]}`, false},
+		{`{"op": "like", "args": [{"op": "casei", "args": [{"property": "road"}]}, {"op": "casei", "args": ["stra__e"]}]}`, true},
+		{`{"op": "<>", "args": [{"property": "name"}, 42]}`, true},
+		{`{"op": "=", "args": [{"property": "name"}, 42]}`, false},
+		{`{"op": ">", "args": [{"property": "count"}, 41.5]}`, true},
+		{`{"op": "<=", "args": [{"property": "count"}, 41]}`, false},
+		{`{"op": ">", "args": [{"property": "count"}, "41"]}`, false},
+		{`{"op": "=", "args": [{"property": "enabled"}, true]}`, true},
+		{`{"op": "<", "args": [{"property": "enabled"}, true]}`, false},
+		{`{"op": "=", "args": [{"property": "missing"}, 1]}`, false},
+		{`{"op": "not", "args": [{"op": "=", "args": [{"property": "missing"}, 1]}]}`, false},
+		{`{"op": "<>", "args": [{"property": "missing"}, 1]}`, false},
+		{`{"op": "not", "args": [{"op": "like", "args": [{"property": "missing"}, "a%"]}]}`, false},
+		{`{"op": "not", "args": [{"op": "between", "args": [{"property": "missing"}, 1, 2]}]}`, false},
+		{`{"op": "not", "args": [{"op": "in", "args": [{"property": "missing"}, [1, 2]]}]}`, false},
+		{`{"op": "not", "args": [{"op": "t_after", "args": [{"property": "missing"}, {"date": "2023-01-01"}]}]}`, false},
+		{`{"op": "not", "args": [{"op": "s_intersects", "args": [{"property": "missing"}, {"bbox": [0, 0, 10, 10]}]}]}`, false},
+		{`{"op": "not", "args": [{"op": "or", "args": [{"op": "=", "args": [{"property": "missing"}, 1]}, false]}]}`, false},
+		{`{"op": "not", "args": [{"op": "and", "args": [{"op": "=", "args": [{"property": "missing"}, 1]}, false]}]}`, true},
+		{`{"op": "or", "args": [{"op": "=", "args": [{"property": "missing"}, 1]}, true]}`, true},
+		{`{"op": "not", "args": [{"op": "isNull", "args": [{"property": "missing"}]}]}`, false},
+		{`{"op": "isNull", "args": [{"property": "missing"}]}`, true},
+		{`{"op": "isNull", "args": [{"property": "nothing"}]}`, true},
+		{`{"op": "isNull", "args": [{"property": "name"}]}`, false},
+		{`{"op": "between", "args": [{"property": "count"}, 40, 42]}`, true},
+		{`{"op": "between", "args": [{"property": "ratio"}, 0.6, 1]}`, false},
+		{`{"op": "in", "args": [{"property": "count"}, [1, 42]]}`, true},
+		{`{"op": "in", "args": [{"property": "name"}, ["a", "b"]]}`, false},
+		{`{"op": "like", "args": [{"property": "name"}, "Hello%"]}`, true},
+		{`{"op": "like", "args": [{"property": "name"}, "H_llo W%d"]}`, true},
+		{`{"op": "like", "args": [{"property": "name"}, "hello%"]}`, false},
+		{`{"op": ">", "args": [{"op": "+", "args": [{"property": "count"}, 1]}, 42]}`, true},
+		{`{"op": "=", "args": [{"op": "div", "args": [{"property": "count"}, 5]}, 8]}`, true},
+		{`{"op": "and", "args": [{"op": "=", "args": [{"property": "enabled"}, true]}, {"op": "<", "args": [{"property": "ratio"}, 1]}]}`, true},
+		{`{"op": "or", "args": [{"op": "isNull", "args": [{"property": "name"}]}, {"op": "=", "args": [{"property": "count"}, 1]}]}`, false},
+		{`{"op": "a_contains", "args": [{"property": "tags"}, ["a", "c"]]}`, true},
+		{`{"op": "a_contains", "args": [{"property": "tags"}, ["a", "d"]]}`, false},
+		{`{"op": "a_containedBy", "args": [{"property": "tags"}, ["a", "b", "c", "d"]]}`, true},
+		{`{"op": "a_equals", "args": [{"property": "tags"}, ["a", "b", "c"]]}`, true},
+		{`{"op": "a_equals", "args": [{"property": "tags"}, ["c", "b", "a"]]}`, false},
+		{`{"op": "a_overlaps", "args": [{"property": "tags"}, ["d", "c"]]}`, true},
+		{`{"op": "a_overlaps", "args": [{"property": "bands.name"}, ["blue"]]}`, false},
+		{`{"op": "a_contains", "args": [{"property": "bands.name"}, ["green"]]}`, true},
+		{`{"op": ">", "args": [{"property": "created"}, {"timestamp": "2020-01-01T00:00:00Z"}]}`, true},
+		{`{"op": "=", "args": [{"property": "day"}, {"date": "2020-06-15"}]}`, true},
+		{`{"op": "t_after", "args": [{"property": "created"}, {"timestamp": "2020-01-01T00:00:00Z"}]}`, true},
+		{`{"op": "t_before", "args": [{"property": "created"}, {"date": "2020-06-15"}]}`, false},
+		{`{"op": "t_during", "args": [{"property": "created"}, {"interval": ["2020-06-01", "2020-06-30"]}]}`, true},
+		{`{"op": "t_during", "args": [{"property": "created"}, {"interval": ["2020-06-01", ".."]}]}`, true},
+		{`{"op": "t_intersects", "args": [{"property": "created"}, {"date": "2020-06-15"}]}`, true},
+		{`{"op": "t_intersects", "args": [{"property": "day"}, {"timestamp": "2020-06-15T23:00:00Z"}]}`, true},
+		{`{"op": "t_disjoint", "args": [{"property": "day"}, {"timestamp": "2020-06-16T00:00:00Z"}]}`, true},
+		{`{"op": "t_contains", "args": [{"property": "interval"}, {"property": "created"}]}`, true},
+		{`{"op": "t_starts", "args": [{"interval": ["2020-01-01T00:00:00Z", "2020-02-01T00:00:00Z"]}, {"property": "interval"}]}`, true},
+		{`{"op": "t_meets", "args": [{"interval": ["2019-01-01T00:00:00Z", "2020-01-01T00:00:00Z"]}, {"property": "interval"}]}`, true},
+		{`{"op": "t_overlaps", "args": [{"interval": ["2019-01-01T00:00:00Z", "2020-02-01T00:00:00Z"]}, {"property": "interval"}]}`, true},
+		{`{"op": "t_equals", "args": [{"property": "created"}, {"timestamp": "2020-06-15T12:00:00Z"}]}`, true},
+		{`{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`, true},
+		{`{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [170, 0, -170, 10]}]}`, false},
+		{`{"op": "s_within", "args": [{"property": "geometry"}, {"type": "Polygon", "coordinates": [[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]]]}]}`, true},
+		{`{"op": "s_disjoint", "args": [{"property": "geometry"}, {"type": "Point", "coordinates": [1, 1]}]}`, true},
+		{`{"op": "s_intersects", "args": [{"property": "missing"}, {"bbox": [0, 0, 10, 10]}]}`, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			result, err := filter.Evaluate(mustParseFilter(t, c.filter), data)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	cases := []struct {
+		filter string
+		err    string
+	}{
+		{
+			filter: `{"op": "=", "args": [{"op": "upper", "args": [{"property": "name"}]}, "A"]}`,
+			err:    `unsupported function "upper"`,
+		},
+		{
+			filter: `{"op": "=", "args": [{"property": "a..b"}, "A"]}`,
+			err:    `empty segment in property path "a..b"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			_, err := filter.Evaluate(mustParseFilter(t, c.filter), jsonResolver{})
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+// TestEvaluateNegatedComparison checks that a negated equality comparison matches the same items
+// as its complement, including items without the property.
+func TestEvaluateNegatedComparison(t *testing.T) {
+	notEquals := mustParseFilter(t, `{"op": "not", "args": [{"op": "=", "args": [{"property": "x"}, 5]}]}`)
+	notEqual := mustParseFilter(t, `{"op": "<>", "args": [{"property": "x"}, 5]}`)
+
+	records := []jsonResolver{
+		{},
+		{"x": nil},
+		{"x": float64(5)},
+		{"x": float64(6)},
+		{"x": "five"},
+	}
+	for _, record := range records {
+		a, err := filter.Evaluate(notEquals, record)
+		require.NoError(t, err)
+		b, err := filter.Evaluate(notEqual, record)
+		require.NoError(t, err)
+		assert.Equal(t, a, b, "record %v", record)
+	}
+}
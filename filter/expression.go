@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/planetlabs/go-ogc/geometry"
 )
 
 type Expression interface {
@@ -66,7 +68,7 @@ func decodeExpression(value any) (Expression, error) {
 		}
 
 		if t, ok := v["type"].(string); ok {
-			if geometryTypes[t] {
+			if geometry.IsType(t) {
 				return decodeGeometry(v)
 			}
 			return nil, fmt.Errorf("unexpected expression type: %s", t)
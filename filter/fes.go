@@ -0,0 +1,1005 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/planetlabs/go-ogc/geometry"
+)
+
+// FESNamespace is the OGC Filter Encoding 2.0 namespace URI.
+const FESNamespace = "http://www.opengis.net/fes/2.0"
+
+var fesComparisons = map[string]string{
+	Equals:              "PropertyIsEqualTo",
+	NotEquals:           "PropertyIsNotEqualTo",
+	LessThan:            "PropertyIsLessThan",
+	LessThanOrEquals:    "PropertyIsLessThanOrEqualTo",
+	GreaterThan:         "PropertyIsGreaterThan",
+	GreaterThanOrEquals: "PropertyIsGreaterThanOrEqualTo",
+}
+
+var fesSpatialOps = map[string]string{
+	GeometryContains:   "Contains",
+	GeometryCrosses:    "Crosses",
+	GeometryDisjoint:   "Disjoint",
+	GeometryEquals:     "Equals",
+	GeometryIntersects: "Intersects",
+	GeometryOverlaps:   "Overlaps",
+	GeometryTouches:    "Touches",
+	GeometryWithin:     "Within",
+}
+
+var fesTemporalOps = map[string]string{
+	TimeAfter:        "After",
+	TimeBefore:       "Before",
+	TimeContains:     "TContains",
+	TimeDuring:       "During",
+	TimeEquals:       "TEquals",
+	TimeFinishedBy:   "EndedBy",
+	TimeFinishes:     "Ends",
+	TimeIntersects:   "AnyInteracts",
+	TimeMeets:        "Meets",
+	TimeMetBy:        "MetBy",
+	TimeOverlappedBy: "OverlappedBy",
+	TimeOverlaps:     "TOverlaps",
+	TimeStartedBy:    "BegunBy",
+	TimeStarts:       "Begins",
+}
+
+func invertMap(m map[string]string) map[string]string {
+	inverted := make(map[string]string, len(m))
+	for k, v := range m {
+		inverted[v] = k
+	}
+	return inverted
+}
+
+var (
+	fesComparisonNames = invertMap(fesComparisons)
+	fesSpatialNames    = invertMap(fesSpatialOps)
+	fesTemporalNames   = invertMap(fesTemporalOps)
+)
+
+// fesDefaultGeometry is the property used when a fes:BBOX has no value reference.
+const fesDefaultGeometry = "geometry"
+
+// EncodeFES encodes a boolean expression as an OGC Filter Encoding 2.0 fes:Filter document.
+// Geometry and temporal literals are encoded as GML 3.2.
+//
+// FES has no equivalent for some CQL2 expressions.  An "in" predicate is encoded as a
+// disjunction of fes:PropertyIsEqualTo elements, t_disjoint as a negated fes:AnyInteracts,
+// and an s_intersects predicate with a bounding box as fes:BBOX.  An open interval bound is
+// encoded as a gml:TimePeriod without that position.  Case-insensitive comparisons are
+// encoded with matchCase="false" when both arguments are wrapped with casei.
+// An error is returned for expressions that cannot be encoded (e.g. array predicates,
+// accent-insensitive comparisons, and boolean literals or functions used as predicates).
+func EncodeFES(expr BooleanExpression) ([]byte, error) {
+	w := &fesWriter{builder: &strings.Builder{}}
+	fmt.Fprintf(w.builder, `<fes:Filter xmlns:fes="%s" xmlns:gml="%s">`, FESNamespace, geometry.GMLNamespace)
+	if err := w.writePredicate(expr); err != nil {
+		return nil, fmt.Errorf("trouble encoding FES: %w", err)
+	}
+	w.builder.WriteString("</fes:Filter>")
+	return []byte(w.builder.String()), nil
+}
+
+type fesWriter struct {
+	builder *strings.Builder
+}
+
+func (w *fesWriter) start(name string, attrs ...string) {
+	w.builder.WriteString("<fes:")
+	w.builder.WriteString(name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		fmt.Fprintf(w.builder, ` %s="`, attrs[i])
+		_ = xml.EscapeText(w.builder, []byte(attrs[i+1]))
+		w.builder.WriteString(`"`)
+	}
+	w.builder.WriteString(">")
+}
+
+func (w *fesWriter) end(name string) {
+	fmt.Fprintf(w.builder, "</fes:%s>", name)
+}
+
+func (w *fesWriter) text(name string, value string) {
+	w.start(name)
+	_ = xml.EscapeText(w.builder, []byte(value))
+	w.end(name)
+}
+
+func (w *fesWriter) writePredicate(expr BooleanExpression) error {
+	switch e := expr.(type) {
+	case *Filter:
+		return w.writePredicate(e.Expression)
+
+	case *And:
+		return w.writeLogical("And", e.Args)
+
+	case *Or:
+		return w.writeLogical("Or", e.Args)
+
+	case *Not:
+		w.start("Not")
+		if err := w.writePredicate(e.Arg); err != nil {
+			return err
+		}
+		w.end("Not")
+		return nil
+
+	case *Comparison:
+		name, ok := fesComparisons[e.Name]
+		if !ok {
+			return fmt.Errorf("unsupported comparison %q", e.Name)
+		}
+		left, right, matchCase := unwrapCaseInsensitive(e.Left, e.Right)
+		return w.writeOp(name, matchCase, left, right)
+
+	case *Like:
+		value, pattern, matchCase := unwrapCaseInsensitive(e.Value, e.Pattern)
+		if _, ok := pattern.(*String); !ok {
+			return errors.New("like pattern must be a string")
+		}
+		attrs := []string{"wildCard", "%", "singleChar", "_", "escapeChar", `\`}
+		if !matchCase {
+			attrs = append(attrs, "matchCase", "false")
+		}
+		w.start("PropertyIsLike", attrs...)
+		if err := w.writeExpression(value); err != nil {
+			return err
+		}
+		if err := w.writeExpression(pattern); err != nil {
+			return err
+		}
+		w.end("PropertyIsLike")
+		return nil
+
+	case *Between:
+		w.start("PropertyIsBetween")
+		if err := w.writeExpression(e.Value); err != nil {
+			return err
+		}
+		w.start("LowerBoundary")
+		if err := w.writeExpression(e.Low); err != nil {
+			return err
+		}
+		w.end("LowerBoundary")
+		w.start("UpperBoundary")
+		if err := w.writeExpression(e.High); err != nil {
+			return err
+		}
+		w.end("UpperBoundary")
+		w.end("PropertyIsBetween")
+		return nil
+
+	case *In:
+		args := make([]BooleanExpression, len(e.List))
+		for i, item := range e.List {
+			args[i] = &Comparison{Name: Equals, Left: e.Item, Right: item}
+		}
+		return w.writeLogical("Or", args)
+
+	case *IsNull:
+		w.start("PropertyIsNull")
+		if err := w.writeExpression(e.Value); err != nil {
+			return err
+		}
+		w.end("PropertyIsNull")
+		return nil
+
+	case *SpatialComparison:
+		return w.writeSpatial(e)
+
+	case *TemporalComparison:
+		return w.writeTemporal(e)
+
+	case *ArrayComparison:
+		return fmt.Errorf("unsupported predicate %q", e.Name)
+	}
+	return fmt.Errorf("unsupported predicate %s", expr)
+}
+
+func (w *fesWriter) writeLogical(name string, args []BooleanExpression) error {
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("expected at least one arg for %q", strings.ToLower(name))
+	case 1:
+		return w.writePredicate(args[0])
+	}
+	w.start(name)
+	for _, arg := range args {
+		if err := w.writePredicate(arg); err != nil {
+			return err
+		}
+	}
+	w.end(name)
+	return nil
+}
+
+func (w *fesWriter) writeOp(name string, matchCase bool, args ...Expression) error {
+	if matchCase {
+		w.start(name)
+	} else {
+		w.start(name, "matchCase", "false")
+	}
+	for _, arg := range args {
+		if err := w.writeExpression(arg); err != nil {
+			return err
+		}
+	}
+	w.end(name)
+	return nil
+}
+
+// unwrapCaseInsensitive removes casei from both arguments if both are wrapped.
+func unwrapCaseInsensitive(left Expression, right Expression) (Expression, Expression, bool) {
+	l, leftOk := left.(*CaseInsensitive)
+	r, rightOk := right.(*CaseInsensitive)
+	if leftOk && rightOk {
+		return l.Value, r.Value, false
+	}
+	return left, right, true
+}
+
+// isLiteralOperand reports whether an operand should be moved to the second position in a
+// spatial or temporal operator.
+func isLiteralOperand(expr Expression) bool {
+	switch expr.(type) {
+	case *Property, *Function:
+		return false
+	}
+	return true
+}
+
+func (w *fesWriter) writeSpatial(e *SpatialComparison) error {
+	name, left, right := e.Name, e.Left, e.Right
+	if isLiteralOperand(left) && !isLiteralOperand(right) {
+		name, left, right = spatialConverse[name], right, left
+	}
+
+	op, ok := fesSpatialOps[name]
+	if !ok {
+		return fmt.Errorf("unsupported spatial comparison %q", name)
+	}
+	if _, ok := right.(*BoundingBox); ok && name == GeometryIntersects {
+		op = "BBOX"
+	}
+
+	w.start(op)
+	for _, arg := range []SpatialExpression{left, right} {
+		if err := w.writeSpatialOperand(arg); err != nil {
+			return err
+		}
+	}
+	w.end(op)
+	return nil
+}
+
+func (w *fesWriter) writeSpatialOperand(expr SpatialExpression) error {
+	switch e := expr.(type) {
+	case *Geometry:
+		gml, err := geometry.MarshalGML(e.Value)
+		if err != nil {
+			return err
+		}
+		w.builder.WriteString(gml)
+		return nil
+	case *BoundingBox:
+		gml, err := geometry.MarshalGMLEnvelope(e.Extent)
+		if err != nil {
+			return err
+		}
+		w.builder.WriteString(gml)
+		return nil
+	}
+	return w.writeExpression(expr)
+}
+
+func (w *fesWriter) writeTemporal(e *TemporalComparison) error {
+	name, left, right := e.Name, e.Left, e.Right
+	if isLiteralOperand(left) && !isLiteralOperand(right) {
+		name, left, right = temporalConverse[name], right, left
+	}
+
+	negate := false
+	if name == TimeDisjoint {
+		name = TimeIntersects
+		negate = true
+	}
+	op, ok := fesTemporalOps[name]
+	if !ok {
+		return fmt.Errorf("unsupported temporal comparison %q", name)
+	}
+
+	if negate {
+		w.start("Not")
+	}
+	w.start(op)
+	for _, arg := range []TemporalExpression{left, right} {
+		if err := w.writeTemporalOperand(arg); err != nil {
+			return err
+		}
+	}
+	w.end(op)
+	if negate {
+		w.end("Not")
+	}
+	return nil
+}
+
+func formatInstant(expr InstantExpression) (string, error) {
+	switch e := expr.(type) {
+	case *Date:
+		return e.Value.Format(time.DateOnly), nil
+	case *Timestamp:
+		return e.Value.Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("unsupported interval bound %s", expr)
+}
+
+func (w *fesWriter) writeTemporalOperand(expr TemporalExpression) error {
+	switch e := expr.(type) {
+	case *Date, *Timestamp:
+		value, err := formatInstant(e.(InstantExpression))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w.builder, `<gml:TimeInstant><gml:timePosition>%s</gml:timePosition></gml:TimeInstant>`, value)
+		return nil
+
+	case *Interval:
+		w.builder.WriteString("<gml:TimePeriod>")
+		for _, bound := range []struct {
+			name    string
+			instant InstantExpression
+		}{{"beginPosition", e.Start}, {"endPosition", e.End}} {
+			if bound.instant == nil {
+				// an open bound has no position
+				continue
+			}
+			value, err := formatInstant(bound.instant)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w.builder, "<gml:%s>%s</gml:%s>", bound.name, value, bound.name)
+		}
+		w.builder.WriteString("</gml:TimePeriod>")
+		return nil
+	}
+	return w.writeExpression(expr)
+}
+
+func (w *fesWriter) writeExpression(expr Expression) error {
+	switch e := expr.(type) {
+	case *Property:
+		w.text("ValueReference", e.Name)
+	case *String:
+		w.text("Literal", e.Value)
+	case *Number:
+		w.text("Literal", strconv.FormatFloat(e.Value, 'f', -1, 64))
+	case *Boolean:
+		w.text("Literal", strconv.FormatBool(e.Value))
+	case *Date, *Timestamp:
+		value, err := formatInstant(e.(InstantExpression))
+		if err != nil {
+			return err
+		}
+		w.text("Literal", value)
+	case *Geometry, *BoundingBox:
+		w.start("Literal")
+		if err := w.writeSpatialOperand(e.(SpatialExpression)); err != nil {
+			return err
+		}
+		w.end("Literal")
+	case *Function:
+		w.start("Function", "name", e.Op)
+		for _, arg := range e.Args {
+			if err := w.writeExpression(arg); err != nil {
+				return err
+			}
+		}
+		w.end("Function")
+	default:
+		return fmt.Errorf("unsupported expression %s", expr)
+	}
+	return nil
+}
+
+// ParseFES decodes an OGC Filter Encoding 2.0 fes:Filter document.  GML 3.2 geometries,
+// envelopes, time instants, and time periods are supported as literals, and fes:ResourceId
+// elements are decoded as predicates on the "id" property.  A fes:BBOX with only an envelope
+// applies to the "geometry" property.  A gml:TimePeriod without a beginPosition or endPosition
+// (or with an empty indeterminate position) is an interval that is open at that end.
+//
+// FES literals are untyped.  A literal whose text is a JSON number is decoded as a number,
+// "true" and "false" are decoded as booleans, and other values are decoded as strings (or as
+// dates and timestamps when used with a temporal operator).
+func ParseFES(data []byte) (*Filter, error) {
+	d := &fesDecoder{decoder: xml.NewDecoder(bytes.NewReader(data))}
+	expr, err := d.filter()
+	if err != nil {
+		return nil, fmt.Errorf("trouble parsing FES: %w", err)
+	}
+	return &Filter{Expression: expr}, nil
+}
+
+type fesDecoder struct {
+	decoder *xml.Decoder
+}
+
+func (d *fesDecoder) filter() (BooleanExpression, error) {
+	var start xml.StartElement
+	for {
+		token, err := d.decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("missing fes:Filter element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := token.(xml.StartElement); ok {
+			start = s
+			break
+		}
+	}
+	if !isFES(start, "Filter") {
+		return nil, fmt.Errorf("expected fes:Filter element, found %s", formatName(start.Name))
+	}
+
+	var predicate BooleanExpression
+	ids := ScalarList{}
+	err := d.children(func(child xml.StartElement) error {
+		if isFES(child, "ResourceId") {
+			rid := attrValue(child, "rid", "")
+			if rid == "" {
+				return errors.New("missing rid in fes:ResourceId")
+			}
+			ids = append(ids, &String{Value: rid})
+			return d.decoder.Skip()
+		}
+		if predicate != nil {
+			return fmt.Errorf("unexpected %s element after predicate", formatName(child.Name))
+		}
+		p, err := d.predicate(child)
+		predicate = p
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) > 0 {
+		if predicate != nil {
+			return nil, errors.New("unexpected predicate with fes:ResourceId")
+		}
+		if len(ids) == 1 {
+			return &Comparison{Name: Equals, Left: &Property{Name: "id"}, Right: ids[0]}, nil
+		}
+		return &In{Item: &Property{Name: "id"}, List: ids}, nil
+	}
+	if predicate == nil {
+		return nil, errors.New("expected a predicate in fes:Filter")
+	}
+	return predicate, nil
+}
+
+func isFES(start xml.StartElement, local string) bool {
+	return start.Name.Space == FESNamespace && start.Name.Local == local
+}
+
+func formatName(name xml.Name) string {
+	switch name.Space {
+	case "":
+		return name.Local
+	case FESNamespace:
+		return "fes:" + name.Local
+	case geometry.GMLNamespace:
+		return "gml:" + name.Local
+	}
+	return fmt.Sprintf("{%s}%s", name.Space, name.Local)
+}
+
+// children calls the visit function with the start of each child element.  The visit
+// function must consume the child element.
+func (d *fesDecoder) children(visit func(xml.StartElement) error) error {
+	for {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := visit(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (d *fesDecoder) predicates() ([]BooleanExpression, error) {
+	args := []BooleanExpression{}
+	err := d.children(func(child xml.StartElement) error {
+		arg, err := d.predicate(child)
+		args = append(args, arg)
+		return err
+	})
+	return args, err
+}
+
+func (d *fesDecoder) expressions() ([]Expression, error) {
+	args := []Expression{}
+	err := d.children(func(child xml.StartElement) error {
+		arg, err := d.expression(child)
+		args = append(args, arg)
+		return err
+	})
+	return args, err
+}
+
+func attrValue(start xml.StartElement, name string, defaultValue string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "" && attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return defaultValue
+}
+
+func (d *fesDecoder) predicate(start xml.StartElement) (BooleanExpression, error) {
+	if start.Name.Space != FESNamespace {
+		return nil, fmt.Errorf("unexpected %s element", formatName(start.Name))
+	}
+	name := start.Name.Local
+	element := formatName(start.Name)
+
+	switch name {
+	case "And", "Or":
+		args, err := d.predicates()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) < 2 {
+			return nil, fmt.Errorf("expected at least two operands in %s", element)
+		}
+		if name == "And" {
+			return &And{Args: args}, nil
+		}
+		return &Or{Args: args}, nil
+
+	case "Not":
+		args, err := d.predicates()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expected one operand in %s, found %d", element, len(args))
+		}
+		return &Not{Arg: args[0]}, nil
+
+	case "PropertyIsNull":
+		args, err := d.operands(element, 1)
+		if err != nil {
+			return nil, err
+		}
+		return &IsNull{Value: args[0]}, nil
+
+	case "PropertyIsLike":
+		return d.like(start)
+
+	case "PropertyIsBetween":
+		return d.between(start)
+
+	case "BBOX":
+		args, err := d.expressions()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 1 {
+			// the default geometry property is used if the envelope is the only operand
+			args = []Expression{&Property{Name: fesDefaultGeometry}, args[0]}
+		}
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected 1 or 2 operands in %s, found %d", element, len(args))
+		}
+		spatialArgs, err := toSpatialArgs(GeometryIntersects, args)
+		if err != nil {
+			return nil, err
+		}
+		return &SpatialComparison{Name: GeometryIntersects, Left: spatialArgs[0], Right: spatialArgs[1]}, nil
+	}
+
+	if op, ok := fesComparisonNames[name]; ok {
+		args, err := d.operands(element, 2)
+		if err != nil {
+			return nil, err
+		}
+		args, err = applyMatchCase(start, args)
+		if err != nil {
+			return nil, err
+		}
+		scalarArgs, err := toScalarArgs(op, args)
+		if err != nil {
+			return nil, err
+		}
+		return &Comparison{Name: op, Left: scalarArgs[0], Right: scalarArgs[1]}, nil
+	}
+
+	if op, ok := fesSpatialNames[name]; ok {
+		args, err := d.operands(element, 2)
+		if err != nil {
+			return nil, err
+		}
+		spatialArgs, err := toSpatialArgs(op, args)
+		if err != nil {
+			return nil, err
+		}
+		return &SpatialComparison{Name: op, Left: spatialArgs[0], Right: spatialArgs[1]}, nil
+	}
+
+	if op, ok := fesTemporalNames[name]; ok {
+		args, err := d.operands(element, 2)
+		if err != nil {
+			return nil, err
+		}
+		for i, arg := range args {
+			if s, ok := arg.(*String); ok {
+				instant, err := parseFESInstant(s.Value)
+				if err != nil {
+					return nil, err
+				}
+				args[i] = instant
+			}
+		}
+		temporalArgs, err := toTemporalArgs(op, args)
+		if err != nil {
+			return nil, err
+		}
+		return &TemporalComparison{Name: op, Left: temporalArgs[0], Right: temporalArgs[1]}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported operator %s", element)
+}
+
+func (d *fesDecoder) operands(element string, count int) ([]Expression, error) {
+	args, err := d.expressions()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != count {
+		return nil, fmt.Errorf("expected %d operands in %s, found %d", count, element, len(args))
+	}
+	return args, nil
+}
+
+// applyMatchCase wraps the arguments with casei if the element has matchCase="false".
+func applyMatchCase(start xml.StartElement, args []Expression) ([]Expression, error) {
+	matchCase, err := strconv.ParseBool(attrValue(start, "matchCase", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid matchCase value in %s", formatName(start.Name))
+	}
+	if matchCase {
+		return args, nil
+	}
+
+	wrapped := make([]Expression, len(args))
+	for i, arg := range args {
+		c, ok := arg.(CharacterExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected character expressions in case-insensitive %s", formatName(start.Name))
+		}
+		wrapped[i] = &CaseInsensitive{Value: c}
+	}
+	return wrapped, nil
+}
+
+func (d *fesDecoder) like(start xml.StartElement) (BooleanExpression, error) {
+	element := formatName(start.Name)
+	args, err := d.operands(element, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern, ok := args[1].(*String)
+	if !ok {
+		return nil, fmt.Errorf("expected a literal pattern in %s", element)
+	}
+	converted, err := convertFESPattern(
+		pattern.Value,
+		attrValue(start, "wildCard", "*"),
+		attrValue(start, "singleChar", "?"),
+		attrValue(start, "escapeChar", `\`),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("trouble converting pattern in %s: %w", element, err)
+	}
+	args[1] = &String{Value: converted}
+
+	args, err = applyMatchCase(start, args)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := args[0].(CharacterExpression)
+	if !ok {
+		return nil, fmt.Errorf("expected a character expression in %s", element)
+	}
+	return &Like{Value: value, Pattern: args[1].(PatternExpression)}, nil
+}
+
+// convertFESPattern converts a pattern with the given wildcard, single character, and escape
+// characters to a CQL2 pattern.
+func convertFESPattern(pattern string, wildCard string, singleChar string, escapeChar string) (string, error) {
+	for _, c := range []string{wildCard, singleChar, escapeChar} {
+		if len([]rune(c)) != 1 {
+			return "", fmt.Errorf("expected a single character, found %q", c)
+		}
+	}
+	syntax := likeSyntax{
+		wildcard: []rune(wildCard)[0],
+		single:   []rune(singleChar)[0],
+		escape:   []rune(escapeChar)[0],
+	}
+	return formatLikePattern(parseLikePattern(pattern, syntax)), nil
+}
+
+func (d *fesDecoder) between(start xml.StartElement) (BooleanExpression, error) {
+	element := formatName(start.Name)
+	var value, low, high Expression
+	err := d.children(func(child xml.StartElement) error {
+		var target *Expression
+		switch {
+		case isFES(child, "LowerBoundary"):
+			target = &low
+		case isFES(child, "UpperBoundary"):
+			target = &high
+		case value == nil:
+			v, err := d.expression(child)
+			value = v
+			return err
+		default:
+			return fmt.Errorf("unexpected %s element in %s", formatName(child.Name), element)
+		}
+
+		args, err := d.operands(formatName(child.Name), 1)
+		if err != nil {
+			return err
+		}
+		*target = args[0]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if value == nil || low == nil || high == nil {
+		return nil, fmt.Errorf("expected an expression, fes:LowerBoundary, and fes:UpperBoundary in %s", element)
+	}
+
+	numericArgs, err := toNumericArgs(betweenOp, []Expression{value, low, high})
+	if err != nil {
+		return nil, err
+	}
+	return &Between{Value: numericArgs[0], Low: numericArgs[1], High: numericArgs[2]}, nil
+}
+
+func (d *fesDecoder) text() (string, error) {
+	builder := &strings.Builder{}
+	for {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			builder.Write(t)
+		case xml.StartElement:
+			return "", fmt.Errorf("unexpected %s element in text", formatName(t.Name))
+		case xml.EndElement:
+			return builder.String(), nil
+		}
+	}
+}
+
+func (d *fesDecoder) expression(start xml.StartElement) (Expression, error) {
+	if start.Name.Space == geometry.GMLNamespace {
+		return d.gml(start)
+	}
+	if start.Name.Space != FESNamespace {
+		return nil, fmt.Errorf("unexpected %s element", formatName(start.Name))
+	}
+
+	switch start.Name.Local {
+	case "ValueReference":
+		name, err := d.text()
+		if err != nil {
+			return nil, err
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, errors.New("empty fes:ValueReference")
+		}
+		return &Property{Name: name}, nil
+
+	case "Literal":
+		return d.literal()
+
+	case "Function":
+		name := attrValue(start, "name", "")
+		if name == "" {
+			return nil, errors.New("missing name in fes:Function")
+		}
+		args, err := d.expressions()
+		if err != nil {
+			return nil, err
+		}
+		function := &Function{Op: name}
+		if len(args) > 0 {
+			function.Args = args
+		}
+		return function, nil
+	}
+
+	return nil, fmt.Errorf("unsupported expression %s", formatName(start.Name))
+}
+
+func (d *fesDecoder) literal() (Expression, error) {
+	builder := &strings.Builder{}
+	var value Expression
+	for {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			builder.Write(t)
+		case xml.StartElement:
+			if value != nil {
+				return nil, fmt.Errorf("unexpected %s element in fes:Literal", formatName(t.Name))
+			}
+			if t.Name.Space != geometry.GMLNamespace {
+				return nil, fmt.Errorf("unsupported %s element in fes:Literal", formatName(t.Name))
+			}
+			v, err := d.gml(t)
+			if err != nil {
+				return nil, err
+			}
+			value = v
+		case xml.EndElement:
+			if value != nil {
+				if strings.TrimSpace(builder.String()) != "" {
+					return nil, errors.New("unexpected text with element in fes:Literal")
+				}
+				return value, nil
+			}
+			return parseFESLiteral(builder.String()), nil
+		}
+	}
+}
+
+func parseFESLiteral(text string) Expression {
+	trimmed := strings.TrimSpace(text)
+	switch trimmed {
+	case "true":
+		return &Boolean{Value: true}
+	case "false":
+		return &Boolean{Value: false}
+	}
+
+	var number float64
+	if json.Valid([]byte(trimmed)) && json.Unmarshal([]byte(trimmed), &number) == nil {
+		return &Number{Value: number}
+	}
+	return &String{Value: text}
+}
+
+func parseFESInstant(value string) (InstantExpression, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == len(time.DateOnly) {
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", value)
+		}
+		return &Date{Value: date}, nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return &Timestamp{Value: timestamp.UTC()}, nil
+}
+
+func (d *fesDecoder) gml(start xml.StartElement) (Expression, error) {
+	switch start.Name.Local {
+	case "Envelope":
+		bounds, err := geometry.DecodeGMLEnvelope(d.decoder, start)
+		if err != nil {
+			return nil, fmt.Errorf("trouble decoding envelope: %w", err)
+		}
+		return &BoundingBox{Extent: bounds}, nil
+
+	case "TimeInstant":
+		var instant InstantExpression
+		err := d.children(func(child xml.StartElement) error {
+			if child.Name.Space != geometry.GMLNamespace || child.Name.Local != "timePosition" {
+				return d.decoder.Skip()
+			}
+			text, err := d.text()
+			if err != nil {
+				return err
+			}
+			instant, err = parseFESInstant(text)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if instant == nil {
+			return nil, errors.New("expected gml:timePosition in gml:TimeInstant")
+		}
+		return instant, nil
+
+	case "TimePeriod":
+		var begin, end InstantExpression
+		err := d.children(func(child xml.StartElement) error {
+			var target *InstantExpression
+			switch {
+			case child.Name.Space != geometry.GMLNamespace:
+				return d.decoder.Skip()
+			case child.Name.Local == "beginPosition":
+				target = &begin
+			case child.Name.Local == "endPosition":
+				target = &end
+			default:
+				return d.decoder.Skip()
+			}
+
+			text, err := d.text()
+			if err != nil {
+				return err
+			}
+			if attrValue(child, "indeterminatePosition", "") != "" && strings.TrimSpace(text) == "" {
+				return nil
+			}
+			instant, err := parseFESInstant(text)
+			*target = instant
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if begin == nil && end == nil {
+			return nil, errors.New("expected gml:beginPosition or gml:endPosition in gml:TimePeriod")
+		}
+		return NewInterval(begin, end)
+	}
+
+	g, err := geometry.DecodeGML(d.decoder, start)
+	if err != nil {
+		return nil, fmt.Errorf("trouble decoding geometry: %w", err)
+	}
+	return &Geometry{Value: g}, nil
+}
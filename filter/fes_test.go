@@ -0,0 +1,436 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fesStart = `<fes:Filter xmlns:fes="http://www.opengis.net/fes/2.0" xmlns:gml="http://www.opengis.net/gml/3.2">`
+	fesEnd   = `</fes:Filter>`
+	gmlRoot  = `xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/OGC/1.3/CRS84"`
+)
+
+func TestEncodeFES(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+		fes  string
+	}{
+		{
+			name: "comparison",
+			json: `{"op": "<", "args": [{"property": "cloud"}, 10.5]}`,
+			fes:  `<fes:PropertyIsLessThan><fes:ValueReference>cloud</fes:ValueReference><fes:Literal>10.5</fes:Literal></fes:PropertyIsLessThan>`,
+		},
+		{
+			name: "escaped literal",
+			json: `{"op": "=", "args": [{"property": "name"}, "a < b & c"]}`,
+			fes:  `<fes:PropertyIsEqualTo><fes:ValueReference>name</fes:ValueReference><fes:Literal>a &lt; b &amp; c</fes:Literal></fes:PropertyIsEqualTo>`,
+		},
+		{
+			name: "case-insensitive like",
+			json: `{"op": "like", "args": [{"op": "casei", "args": [{"property": "name"}]}, {"op": "casei", "args": ["ab%"]}]}`,
+			fes: `<fes:PropertyIsLike wildCard="%" singleChar="_" escapeChar="\" matchCase="false">` +
+				`<fes:ValueReference>name</fes:ValueReference><fes:Literal>ab%</fes:Literal></fes:PropertyIsLike>`,
+		},
+		{
+			name: "in",
+			json: `{"op": "in", "args": [{"property": "path"}, [1, 2]]}`,
+			fes: `<fes:Or>` +
+				`<fes:PropertyIsEqualTo><fes:ValueReference>path</fes:ValueReference><fes:Literal>1</fes:Literal></fes:PropertyIsEqualTo>` +
+				`<fes:PropertyIsEqualTo><fes:ValueReference>path</fes:ValueReference><fes:Literal>2</fes:Literal></fes:PropertyIsEqualTo>` +
+				`</fes:Or>`,
+		},
+		{
+			name: "bbox with literal first",
+			json: `{"op": "s_intersects", "args": [{"bbox": [-10, -5, 10, 5]}, {"property": "geom"}]}`,
+			fes: `<fes:BBOX><fes:ValueReference>geom</fes:ValueReference>` +
+				`<gml:Envelope ` + gmlRoot + `><gml:lowerCorner>-10 -5</gml:lowerCorner><gml:upperCorner>10 5</gml:upperCorner></gml:Envelope>` +
+				`</fes:BBOX>`,
+		},
+		{
+			name: "within with literal first",
+			json: `{"op": "s_within", "args": [{"type": "Point", "coordinates": [1, 2]}, {"property": "geom"}]}`,
+			fes: `<fes:Contains><fes:ValueReference>geom</fes:ValueReference>` +
+				`<gml:Point ` + gmlRoot + `><gml:pos>1 2</gml:pos></gml:Point>` +
+				`</fes:Contains>`,
+		},
+		{
+			name: "open interval",
+			json: `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2020-01-01", ".."]}]}`,
+			fes: `<fes:During><fes:ValueReference>datetime</fes:ValueReference>` +
+				`<gml:TimePeriod><gml:beginPosition>2020-01-01</gml:beginPosition></gml:TimePeriod>` +
+				`</fes:During>`,
+		},
+		{
+			name: "disjoint",
+			json: `{"op": "t_disjoint", "args": [{"property": "datetime"}, {"timestamp": "2020-01-01T00:00:00Z"}]}`,
+			fes: `<fes:Not><fes:AnyInteracts><fes:ValueReference>datetime</fes:ValueReference>` +
+				`<gml:TimeInstant><gml:timePosition>2020-01-01T00:00:00Z</gml:timePosition></gml:TimeInstant>` +
+				`</fes:AnyInteracts></fes:Not>`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := filter.EncodeFES(mustParseFilter(t, c.json))
+			require.NoError(t, err)
+			assert.Equal(t, fesStart+c.fes+fesEnd, string(data))
+		})
+	}
+}
+
+func TestEncodeFESErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		json string
+		err  string
+	}{
+		{
+			name: "boolean literal",
+			json: `true`,
+			err:  "trouble encoding FES: unsupported predicate true",
+		},
+		{
+			name: "array predicate",
+			json: `{"op": "a_contains", "args": [{"property": "tags"}, ["a"]]}`,
+			err:  `trouble encoding FES: unsupported predicate "a_contains"`,
+		},
+		{
+			name: "accent-insensitive comparison",
+			json: `{"op": "=", "args": [{"op": "accenti", "args": [{"property": "name"}]}, {"op": "accenti", "args": ["a"]}]}`,
+			err:  `trouble encoding FES: unsupported expression {"args":[{"property":"name"}],"op":"accenti"}`,
+		},
+		{
+			name: "property in interval",
+			json: `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": [{"property": "start"}, ".."]}]}`,
+			err:  `trouble encoding FES: unsupported interval bound {"property":"start"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := filter.EncodeFES(mustParseFilter(t, c.json))
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestParseFES(t *testing.T) {
+	cases := []struct {
+		name string
+		fes  string
+		json string
+	}{
+		{
+			name: "logical",
+			fes: `<fes:And>
+				<fes:PropertyIsGreaterThanOrEqualTo>
+					<fes:ValueReference>cloud</fes:ValueReference>
+					<fes:Literal>10</fes:Literal>
+				</fes:PropertyIsGreaterThanOrEqualTo>
+				<fes:Not>
+					<fes:PropertyIsNull><fes:ValueReference>platform</fes:ValueReference></fes:PropertyIsNull>
+				</fes:Not>
+				<fes:PropertyIsEqualTo>
+					<fes:ValueReference>flag</fes:ValueReference>
+					<fes:Literal>true</fes:Literal>
+				</fes:PropertyIsEqualTo>
+			</fes:And>`,
+			json: `{"op": "and", "args": [
+				{"op": ">=", "args": [{"property": "cloud"}, 10]},
+				{"op": "not", "args": [{"op": "isNull", "args": [{"property": "platform"}]}]},
+				{"op": "=", "args": [{"property": "flag"}, true]}
+			]}`,
+		},
+		{
+			name: "untyped literals",
+			fes: `<fes:Or>
+				<fes:PropertyIsEqualTo><fes:ValueReference>a</fes:ValueReference><fes:Literal>007</fes:Literal></fes:PropertyIsEqualTo>
+				<fes:PropertyIsEqualTo><fes:ValueReference>a</fes:ValueReference><fes:Literal>-1.5e3</fes:Literal></fes:PropertyIsEqualTo>
+			</fes:Or>`,
+			json: `{"op": "or", "args": [
+				{"op": "=", "args": [{"property": "a"}, "007"]},
+				{"op": "=", "args": [{"property": "a"}, -1500]}
+			]}`,
+		},
+		{
+			name: "case-insensitive comparison",
+			fes: `<fes:PropertyIsNotEqualTo matchCase="false">
+				<fes:ValueReference>name</fes:ValueReference>
+				<fes:Literal>Abc</fes:Literal>
+			</fes:PropertyIsNotEqualTo>`,
+			json: `{"op": "<>", "args": [{"op": "casei", "args": [{"property": "name"}]}, {"op": "casei", "args": ["Abc"]}]}`,
+		},
+		{
+			name: "like with custom wildcards",
+			fes: `<fes:PropertyIsLike wildCard="*" singleChar="." escapeChar="!">
+				<fes:ValueReference>name</fes:ValueReference>
+				<fes:Literal>a*b.c!*d%e</fes:Literal>
+			</fes:PropertyIsLike>`,
+			json: `{"op": "like", "args": [{"property": "name"}, "a%b_c*d\\%e"]}`,
+		},
+		{
+			name: "like with trailing escape",
+			fes: `<fes:PropertyIsLike wildCard="*" singleChar="." escapeChar="!">
+				<fes:ValueReference>name</fes:ValueReference>
+				<fes:Literal>a\!</fes:Literal>
+			</fes:PropertyIsLike>`,
+			json: `{"op": "like", "args": [{"property": "name"}, "a\\\\!"]}`,
+		},
+		{
+			name: "between",
+			fes: `<fes:PropertyIsBetween>
+				<fes:ValueReference>depth</fes:ValueReference>
+				<fes:LowerBoundary><fes:Literal>100</fes:Literal></fes:LowerBoundary>
+				<fes:UpperBoundary><fes:Function name="max"><fes:ValueReference>a</fes:ValueReference><fes:Literal>200</fes:Literal></fes:Function></fes:UpperBoundary>
+			</fes:PropertyIsBetween>`,
+			json: `{"op": "between", "args": [{"property": "depth"}, 100, {"op": "max", "args": [{"property": "a"}, 200]}]}`,
+		},
+		{
+			name: "bbox with latitude first envelope",
+			fes: `<fes:BBOX>
+				<fes:ValueReference>geom</fes:ValueReference>
+				<gml:Envelope srsName="urn:ogc:def:crs:EPSG::4326">
+					<gml:lowerCorner>-5 -10</gml:lowerCorner>
+					<gml:upperCorner>5 10</gml:upperCorner>
+				</gml:Envelope>
+			</fes:BBOX>`,
+			json: `{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [-10, -5, 10, 5]}]}`,
+		},
+		{
+			name: "bbox without value reference",
+			fes: `<fes:BBOX>
+				<gml:Envelope>
+					<gml:lowerCorner>-10 -5</gml:lowerCorner>
+					<gml:upperCorner>10 5</gml:upperCorner>
+				</gml:Envelope>
+			</fes:BBOX>`,
+			json: `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [-10, -5, 10, 5]}]}`,
+		},
+		{
+			name: "spatial operator with geometry",
+			fes: `<fes:Within>
+				<fes:ValueReference>geom</fes:ValueReference>
+				<gml:Polygon gml:id="p1">
+					<gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:exterior>
+				</gml:Polygon>
+			</fes:Within>`,
+			json: `{"op": "s_within", "args": [{"property": "geom"}, {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}]}`,
+		},
+		{
+			name: "geometry in literal",
+			fes: `<fes:Intersects>
+				<fes:ValueReference>geom</fes:ValueReference>
+				<fes:Literal><gml:Point><gml:pos>1 2</gml:pos></gml:Point></fes:Literal>
+			</fes:Intersects>`,
+			json: `{"op": "s_intersects", "args": [{"property": "geom"}, {"type": "Point", "coordinates": [1, 2]}]}`,
+		},
+		{
+			name: "time period",
+			fes: `<fes:During>
+				<fes:ValueReference>datetime</fes:ValueReference>
+				<gml:TimePeriod gml:id="t1">
+					<gml:beginPosition>2020-01-01T02:00:00+02:00</gml:beginPosition>
+					<gml:endPosition indeterminatePosition="unknown"/>
+				</gml:TimePeriod>
+			</fes:During>`,
+			json: `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2020-01-01T00:00:00Z", ".."]}]}`,
+		},
+		{
+			name: "time period without begin",
+			fes: `<fes:During>
+				<fes:ValueReference>datetime</fes:ValueReference>
+				<gml:TimePeriod><gml:endPosition>2020-01-01</gml:endPosition></gml:TimePeriod>
+			</fes:During>`,
+			json: `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["..", "2020-01-01"]}]}`,
+		},
+		{
+			name: "time instant",
+			fes: `<fes:After>
+				<fes:ValueReference>datetime</fes:ValueReference>
+				<gml:TimeInstant gml:id="t1"><gml:timePosition>2020-01-01</gml:timePosition></gml:TimeInstant>
+			</fes:After>`,
+			json: `{"op": "t_after", "args": [{"property": "datetime"}, {"date": "2020-01-01"}]}`,
+		},
+		{
+			name: "temporal literal",
+			fes: `<fes:TEquals>
+				<fes:ValueReference>datetime</fes:ValueReference>
+				<fes:Literal>2020-01-01T00:00:00Z</fes:Literal>
+			</fes:TEquals>`,
+			json: `{"op": "t_equals", "args": [{"property": "datetime"}, {"timestamp": "2020-01-01T00:00:00Z"}]}`,
+		},
+		{
+			name: "resource id",
+			fes:  `<fes:ResourceId rid="roads.1"/>`,
+			json: `{"op": "=", "args": [{"property": "id"}, "roads.1"]}`,
+		},
+		{
+			name: "resource ids",
+			fes:  `<fes:ResourceId rid="roads.1"/><fes:ResourceId rid="roads.2"/>`,
+			json: `{"op": "in", "args": [{"property": "id"}, ["roads.1", "roads.2"]]}`,
+		},
+	}
+
+	schema := getSchema(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := filter.ParseFES([]byte(fesStart + c.fes + fesEnd))
+			require.NoError(t, err)
+			assert.JSONEq(t, c.json, f.String())
+
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(f.String()), &v))
+			assert.NoError(t, schema.Validate(v))
+		})
+	}
+}
+
+func TestParseFESErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		fes  string
+		err  string
+	}{
+		{
+			name: "wrong root",
+			fes:  `<fes:Query xmlns:fes="http://www.opengis.net/fes/2.0"/>`,
+			err:  "trouble parsing FES: expected fes:Filter element, found fes:Query",
+		},
+		{
+			name: "empty filter",
+			fes:  fesStart + fesEnd,
+			err:  "trouble parsing FES: expected a predicate in fes:Filter",
+		},
+		{
+			name: "unsupported operator",
+			fes:  fesStart + `<fes:DWithin><fes:ValueReference>geom</fes:ValueReference></fes:DWithin>` + fesEnd,
+			err:  "trouble parsing FES: unsupported operator fes:DWithin",
+		},
+		{
+			name: "single operand in and",
+			fes:  fesStart + `<fes:And><fes:PropertyIsNull><fes:ValueReference>a</fes:ValueReference></fes:PropertyIsNull></fes:And>` + fesEnd,
+			err:  "trouble parsing FES: expected at least two operands in fes:And",
+		},
+		{
+			name: "missing operand",
+			fes:  fesStart + `<fes:PropertyIsEqualTo><fes:ValueReference>a</fes:ValueReference></fes:PropertyIsEqualTo>` + fesEnd,
+			err:  "trouble parsing FES: expected 2 operands in fes:PropertyIsEqualTo, found 1",
+		},
+		{
+			name: "time period without positions",
+			fes:  fesStart + `<fes:During><fes:ValueReference>t</fes:ValueReference><gml:TimePeriod></gml:TimePeriod></fes:During>` + fesEnd,
+			err:  "trouble parsing FES: expected gml:beginPosition or gml:endPosition in gml:TimePeriod",
+		},
+		{
+			name: "bbox without envelope",
+			fes:  fesStart + `<fes:BBOX></fes:BBOX>` + fesEnd,
+			err:  "trouble parsing FES: expected 1 or 2 operands in fes:BBOX, found 0",
+		},
+		{
+			name: "bad geometry",
+			fes:  fesStart + `<fes:Intersects><fes:ValueReference>g</fes:ValueReference><gml:Point srsName="EPSG:3857"><gml:pos>1 2</gml:pos></gml:Point></fes:Intersects>` + fesEnd,
+			err:  `trouble parsing FES: trouble decoding geometry: unsupported srsName "EPSG:3857"`,
+		},
+		{
+			name: "bad timestamp",
+			fes:  fesStart + `<fes:After><fes:ValueReference>t</fes:ValueReference><fes:Literal>yesterday</fes:Literal></fes:After>` + fesEnd,
+			err:  `trouble parsing FES: invalid timestamp "yesterday"`,
+		},
+		{
+			name: "time period start after end",
+			fes: fesStart + `<fes:During><fes:ValueReference>t</fes:ValueReference>` +
+				`<gml:TimePeriod><gml:beginPosition>2021-01-01</gml:beginPosition><gml:endPosition>2020-01-01</gml:endPosition></gml:TimePeriod>` +
+				`</fes:During>` + fesEnd,
+			err: "trouble parsing FES: interval start 2021-01-01 is after end 2020-01-01",
+		},
+		{
+			name: "malformed xml",
+			fes:  fesStart + `<fes:Not>` + fesEnd,
+			err:  "trouble parsing FES: XML syntax error on line 1: element <Not> closed by </Filter>",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := filter.ParseFES([]byte(c.fes))
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestFESRoundTrip(t *testing.T) {
+	cases := []string{
+		`{"op": "and", "args": [
+			{"op": "=", "args": [{"property": "platform"}, "landsat-8"]},
+			{"op": "or", "args": [
+				{"op": "<=", "args": [{"property": "cloud"}, 10]},
+				{"op": "not", "args": [{"op": ">", "args": [{"property": "sun"}, 45]}]}
+			]}
+		]}`,
+		`{"op": "like", "args": [{"property": "name"}, "a\\%b_%"]}`,
+		`{"op": "=", "args": [{"op": "casei", "args": [{"property": "name"}]}, {"op": "casei", "args": ["Abc"]}]}`,
+		`{"op": "between", "args": [{"property": "depth"}, 100, 200]}`,
+		`{"op": "s_intersects", "args": [{"property": "geom"}, {"bbox": [-10, -5, 10, 5]}]}`,
+		`{"op": "s_crosses", "args": [{"property": "geom"}, {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}]}`,
+		`{"op": "s_contains", "args": [{"property": "geom"}, {"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]]]}]}`,
+		`{"op": "t_overlaps", "args": [{"property": "datetime"}, {"interval": ["2020-01-01T00:00:00Z", "2020-02-01T12:30:00Z"]}]}`,
+		`{"op": "t_starts", "args": [{"property": "datetime"}, {"interval": ["..", "2020-02-01"]}]}`,
+		`{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2020-01-01T00:00:00Z", ".."]}]}`,
+		`{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [-10, -5, 10, 5]}]}`,
+		`{"op": "t_before", "args": [{"op": "start", "args": [{"property": "event"}]}, {"date": "2020-01-01"}]}`,
+	}
+
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			data, err := filter.EncodeFES(mustParseFilter(t, c))
+			require.NoError(t, err)
+
+			f, err := filter.ParseFES(data)
+			require.NoError(t, err)
+			assert.JSONEq(t, c, f.String())
+		})
+	}
+}
+
+func TestFESLikeRoundTrip(t *testing.T) {
+	patterns := []string{`abc\`, `\a\b_\`, `50\% off\_\\%`}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			like := &filter.Like{Value: &filter.Property{Name: "name"}, Pattern: &filter.String{Value: pattern}}
+			data, err := filter.EncodeFES(&filter.Filter{Expression: like})
+			require.NoError(t, err)
+
+			f, err := filter.ParseFES(data)
+			require.NoError(t, err)
+			parsed, ok := f.Expression.(*filter.Like)
+			require.True(t, ok)
+
+			expected, err := filter.CompileLike(like.Pattern)
+			require.NoError(t, err)
+			actual, err := filter.CompileLike(parsed.Pattern)
+			require.NoError(t, err)
+			assert.Equal(t, expected.SQL(), actual.SQL())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FoldCase returns the value with full Unicode case folding applied.  Values that differ only
+// in case fold to the same string (e.g. "Straße" and "STRASSE").  This is the folding used
+// when evaluating casei.
+func FoldCase(value string) string {
+	return norm.NFC.String(cases.Fold().String(value))
+}
+
+// FoldAccents returns the value with accents and other combining marks removed.  The value is
+// decomposed (NFD), nonspacing marks are dropped, and the result is composed again (NFC), so
+// "Chiça" folds to "Chica".  This is the folding used when evaluating accenti.
+func FoldAccents(value string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, value)
+	if err != nil {
+		return value
+	}
+	return folded
+}
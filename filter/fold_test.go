@@ -0,0 +1,64 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldCase(t *testing.T) {
+	cases := []struct {
+		value  string
+		folded string
+	}{
+		{value: "Hello WORLD", folded: "hello world"},
+		{value: "Straße", folded: "strasse"},
+		{value: "STRASSE", folded: "strasse"},
+		{value: "ΟΔΟΣ", folded: "οδοσ"},
+		{value: "οδος", folded: "οδοσ"},
+		{value: "Chiça", folded: "chiça"},
+		{value: "", folded: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			assert.Equal(t, c.folded, filter.FoldCase(c.value))
+		})
+	}
+}
+
+func TestFoldAccents(t *testing.T) {
+	cases := []struct {
+		value  string
+		folded string
+	}{
+		{value: "Chiça", folded: "Chica"},
+		{value: "Chiça", folded: "Chica"},
+		{value: "débárquément", folded: "debarquement"},
+		{value: "Ærøskøbing", folded: "Ærøskøbing"},
+		{value: "Ελλάδα", folded: "Ελλαδα"},
+		{value: "한국", folded: "한국"},
+		{value: "plain", folded: "plain"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			assert.Equal(t, c.folded, filter.FoldAccents(c.value))
+		})
+	}
+}
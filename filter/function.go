@@ -44,14 +44,11 @@ func (*Function) spatialExpression()   {}
 func (*Function) temporalExpression()  {}
 
 func (e *Function) MarshalJSON() ([]byte, error) {
-	f := map[string]any{
-		"op":   e.Op,
-		"args": e.Args,
+	args := e.Args
+	if args == nil {
+		args = []Expression{}
 	}
-	if e.Args == nil {
-		f["args"] = []Expression{}
-	}
-	return json.Marshal(f)
+	return marshalOp(e.Op, args)
 }
 
 func (e *Function) String() string {
@@ -0,0 +1,808 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"cmp"
+	"time"
+)
+
+// Implication is the result of checking whether one filter implies another.
+type Implication int
+
+const (
+	// ImplicationUnknown means the checker could not determine the result.
+	ImplicationUnknown Implication = iota
+
+	// ImplicationTrue means every item that matches the first filter also matches the second.
+	ImplicationTrue
+
+	// ImplicationFalse means there can be an item that matches the first filter but not the second.
+	ImplicationFalse
+)
+
+func (i Implication) String() string {
+	switch i {
+	case ImplicationTrue:
+		return "true"
+	case ImplicationFalse:
+		return "false"
+	}
+	return "unknown"
+}
+
+// Implies checks whether filter a implies filter b.  The check is conservative: a result of
+// ImplicationTrue or ImplicationFalse is only returned when it can be proven, and
+// ImplicationUnknown is returned otherwise.
+//
+// The checker understands:
+//   - equality, inequality, and in lists with number, string, and boolean literals
+//   - numeric ranges from comparisons and between (numbers are treated as real values)
+//   - s_within and s_intersects with bounding box literals
+//   - t_before, t_after, t_during, t_equals, and t_intersects with date, timestamp,
+//     and interval literals (a date covers the whole day)
+//   - and, or, and not combinations of the above
+//
+// Comparisons must have a property on one side and a literal on the other.
+func Implies(a, b BooleanExpression) Implication {
+	return implies(unwrapFilter(normalizeAs(a)), unwrapFilter(normalizeAs(b)))
+}
+
+func unwrapFilter(e BooleanExpression) BooleanExpression {
+	for {
+		f, ok := e.(*Filter)
+		if !ok {
+			return e
+		}
+		e = f.Expression
+	}
+}
+
+func implies(a, b BooleanExpression) Implication {
+	if or, ok := a.(*Or); ok {
+		result := ImplicationTrue
+		for _, arg := range or.Args {
+			switch implies(arg, b) {
+			case ImplicationFalse:
+				return ImplicationFalse
+			case ImplicationUnknown:
+				result = ImplicationUnknown
+			}
+		}
+		return result
+	}
+
+	conjuncts := []BooleanExpression{a}
+	if and, ok := a.(*And); ok {
+		conjuncts = and.Args
+	}
+
+	c := newConstraints(conjuncts)
+	if !c.satisfiable() {
+		return ImplicationTrue
+	}
+	return c.implies(b)
+}
+
+// constraints describes the conjuncts of a filter as constraints on individual properties.
+type constraints struct {
+	keys map[string]bool
+
+	// complete is true if every conjunct is represented exactly
+	complete bool
+
+	values   map[string]*valueConstraint
+	spatial  map[string]*extentConstraint[box]
+	temporal map[string]*extentConstraint[span[time.Time]]
+}
+
+func newConstraints(conjuncts []BooleanExpression) *constraints {
+	c := &constraints{
+		keys:     map[string]bool{},
+		complete: true,
+		values:   map[string]*valueConstraint{},
+		spatial:  map[string]*extentConstraint[box]{},
+		temporal: map[string]*extentConstraint[span[time.Time]]{},
+	}
+
+	for _, conjunct := range conjuncts {
+		c.keys[expressionKey(conjunct)] = true
+
+		if name, test, ok := valueTestOf(conjunct); ok {
+			c.value(name).add(test)
+			continue
+		}
+
+		if name, test, ok := spatialTestOf(conjunct); ok {
+			c.spatialExtent(name).add(test)
+			if !test.exact {
+				c.complete = false
+			}
+			continue
+		}
+
+		if name, test, ok := temporalTestOf(conjunct); ok {
+			c.temporalExtent(name).add(test)
+			if !test.exact {
+				c.complete = false
+			}
+			continue
+		}
+
+		c.complete = false
+	}
+
+	return c
+}
+
+func (c *constraints) value(name string) *valueConstraint {
+	v, ok := c.values[name]
+	if !ok {
+		v = &valueConstraint{excluded: map[string]bool{}}
+		c.values[name] = v
+	}
+	return v
+}
+
+func (c *constraints) spatialExtent(name string) *extentConstraint[box] {
+	e, ok := c.spatial[name]
+	if !ok {
+		e = &extentConstraint[box]{}
+		c.spatial[name] = e
+	}
+	return e
+}
+
+func (c *constraints) temporalExtent(name string) *extentConstraint[span[time.Time]] {
+	e, ok := c.temporal[name]
+	if !ok {
+		e = &extentConstraint[span[time.Time]]{}
+		c.temporal[name] = e
+	}
+	return e
+}
+
+func (c *constraints) satisfiable() bool {
+	for _, v := range c.values {
+		if !v.satisfiable() {
+			return false
+		}
+	}
+	for _, e := range c.spatial {
+		if !e.satisfiable() {
+			return false
+		}
+	}
+	for _, e := range c.temporal {
+		if !e.satisfiable() {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *constraints) implies(b BooleanExpression) Implication {
+	if c.keys[expressionKey(b)] {
+		return ImplicationTrue
+	}
+
+	switch e := b.(type) {
+	case *Filter:
+		return c.implies(e.Expression)
+	case *And:
+		result := ImplicationTrue
+		for _, arg := range e.Args {
+			switch c.implies(arg) {
+			case ImplicationFalse:
+				return ImplicationFalse
+			case ImplicationUnknown:
+				result = ImplicationUnknown
+			}
+		}
+		return result
+	case *Or:
+		for _, arg := range e.Args {
+			if c.implies(arg) == ImplicationTrue {
+				return ImplicationTrue
+			}
+		}
+		return ImplicationUnknown
+	}
+
+	result := c.impliesTest(b)
+	if result == ImplicationFalse && !c.complete {
+		return ImplicationUnknown
+	}
+	return result
+}
+
+func (c *constraints) impliesTest(b BooleanExpression) Implication {
+	if name, test, ok := valueTestOf(b); ok {
+		return c.value(name).implies(test)
+	}
+
+	if name, test, ok := spatialTestOf(b); ok && test.exact {
+		return c.spatialExtent(name).implies(test)
+	}
+
+	if name, test, ok := temporalTestOf(b); ok && test.exact {
+		return c.temporalExtent(name).implies(test)
+	}
+
+	return ImplicationUnknown
+}
+
+// valueTest is a test of a property value against literals.
+type valueTest struct {
+	kind   valueTestKind
+	values []Expression
+	span   span[number]
+}
+
+type valueTestKind int
+
+const (
+	valueInSet valueTestKind = iota
+	valueInRange
+	valueNotEqual
+)
+
+func (t valueTest) accepts(value Expression) bool {
+	switch t.kind {
+	case valueInSet:
+		key := expressionKey(value)
+		for _, v := range t.values {
+			if expressionKey(v) == key {
+				return true
+			}
+		}
+		return false
+	case valueInRange:
+		n, ok := value.(*Number)
+		return ok && t.span.contains(number(n.Value))
+	case valueNotEqual:
+		return expressionKey(value) != expressionKey(t.values[0])
+	}
+	return false
+}
+
+func isLiteral(e Expression) bool {
+	switch e.(type) {
+	case *Number, *String, *Boolean:
+		return true
+	}
+	return false
+}
+
+func valueTestOf(e BooleanExpression) (string, valueTest, bool) {
+	switch t := e.(type) {
+	case *Not:
+		// negating an equality comparison is the same as using its complement because both are
+		// unknown (and do not match) when the property is missing or null
+		if comparison, ok := t.Arg.(*Comparison); ok {
+			switch comparison.Name {
+			case Equals:
+				return valueTestOf(&Comparison{Name: NotEquals, Left: comparison.Left, Right: comparison.Right})
+			case NotEquals:
+				return valueTestOf(&Comparison{Name: Equals, Left: comparison.Left, Right: comparison.Right})
+			}
+		}
+	case *Comparison:
+		property, ok := t.Left.(*Property)
+		if !ok || !isLiteral(t.Right) {
+			break
+		}
+		switch t.Name {
+		case Equals:
+			return property.Name, valueTest{kind: valueInSet, values: []Expression{t.Right}}, true
+		case NotEquals:
+			return property.Name, valueTest{kind: valueNotEqual, values: []Expression{t.Right}}, true
+		}
+		n, ok := t.Right.(*Number)
+		if !ok {
+			break
+		}
+		value := number(n.Value)
+		test := valueTest{kind: valueInRange}
+		switch t.Name {
+		case LessThan:
+			test.span.high = bound[number]{value: value, set: true, open: true}
+		case LessThanOrEquals:
+			test.span.high = bound[number]{value: value, set: true}
+		case GreaterThan:
+			test.span.low = bound[number]{value: value, set: true, open: true}
+		case GreaterThanOrEquals:
+			test.span.low = bound[number]{value: value, set: true}
+		}
+		return property.Name, test, true
+	case *Between:
+		property, ok := t.Value.(*Property)
+		if !ok {
+			break
+		}
+		low, ok := t.Low.(*Number)
+		if !ok {
+			break
+		}
+		high, ok := t.High.(*Number)
+		if !ok {
+			break
+		}
+		test := valueTest{kind: valueInRange, span: span[number]{
+			low:  bound[number]{value: number(low.Value), set: true},
+			high: bound[number]{value: number(high.Value), set: true},
+		}}
+		return property.Name, test, true
+	case *In:
+		property, ok := t.Item.(*Property)
+		if !ok {
+			break
+		}
+		values := make([]Expression, len(t.List))
+		for i, item := range t.List {
+			if !isLiteral(item) {
+				return "", valueTest{}, false
+			}
+			values[i] = item
+		}
+		return property.Name, valueTest{kind: valueInSet, values: values}, true
+	}
+	return "", valueTest{}, false
+}
+
+// valueConstraint describes the values allowed for a property.
+type valueConstraint struct {
+	// finite is true if the property is limited to the literals in values
+	finite bool
+	values []Expression
+
+	// numeric is true if the property is limited to numbers in span
+	numeric bool
+	span    span[number]
+
+	excluded map[string]bool
+}
+
+func (c *valueConstraint) add(test valueTest) {
+	switch test.kind {
+	case valueInSet:
+		if !c.finite {
+			c.finite = true
+			c.values = test.values
+			return
+		}
+		values := []Expression{}
+		for _, v := range c.values {
+			if test.accepts(v) {
+				values = append(values, v)
+			}
+		}
+		c.values = values
+	case valueInRange:
+		c.numeric = true
+		c.span = c.span.intersect(test.span)
+	case valueNotEqual:
+		c.excluded[expressionKey(test.values[0])] = true
+	}
+}
+
+// candidates returns the allowed values and true if the property is limited to a finite set.
+func (c *valueConstraint) candidates() ([]Expression, bool) {
+	if !c.finite {
+		if !c.numeric || !c.span.degenerate() {
+			return nil, false
+		}
+		value := &Number{Value: float64(c.span.low.value)}
+		if c.excluded[expressionKey(value)] {
+			return []Expression{}, true
+		}
+		return []Expression{value}, true
+	}
+
+	values := []Expression{}
+	for _, v := range c.values {
+		if c.excluded[expressionKey(v)] {
+			continue
+		}
+		if c.numeric {
+			n, ok := v.(*Number)
+			if !ok || !c.span.contains(number(n.Value)) {
+				continue
+			}
+		}
+		values = append(values, v)
+	}
+	return values, true
+}
+
+func (c *valueConstraint) satisfiable() bool {
+	if values, ok := c.candidates(); ok {
+		return len(values) > 0
+	}
+	return !c.numeric || c.hasValue(c.span)
+}
+
+// hasValue checks whether a span includes a number that is not excluded.
+func (c *valueConstraint) hasValue(s span[number]) bool {
+	if s.empty() {
+		return false
+	}
+	if s.degenerate() {
+		return !c.excluded[expressionKey(&Number{Value: float64(s.low.value)})]
+	}
+	return true
+}
+
+func (c *valueConstraint) implies(test valueTest) Implication {
+	if values, ok := c.candidates(); ok {
+		for _, v := range values {
+			if !test.accepts(v) {
+				return ImplicationFalse
+			}
+		}
+		return ImplicationTrue
+	}
+
+	switch test.kind {
+	case valueInRange:
+		if !c.numeric {
+			return ImplicationFalse
+		}
+		for _, piece := range c.span.outside(test.span) {
+			if c.hasValue(piece) {
+				return ImplicationFalse
+			}
+		}
+		return ImplicationTrue
+	case valueNotEqual:
+		value := test.values[0]
+		if c.excluded[expressionKey(value)] {
+			return ImplicationTrue
+		}
+		if c.numeric {
+			n, ok := value.(*Number)
+			if !ok || !c.span.contains(number(n.Value)) {
+				return ImplicationTrue
+			}
+		}
+		return ImplicationFalse
+	}
+
+	// an infinite set of values cannot be limited to a finite set
+	return ImplicationFalse
+}
+
+// region is implemented by spatial and temporal extents.
+type region[R any] interface {
+	intersect(R) R
+	covers(R) bool
+	empty() bool
+}
+
+// extentTest is a test of a property extent against a literal extent.  If within is true,
+// the property extent must be within the literal extent, otherwise it must intersect it.
+// An exact test is true for exactly the property extents that are within or intersect
+// the literal extent.
+type extentTest[R region[R]] struct {
+	extent R
+	within bool
+	exact  bool
+}
+
+// extentConstraint describes the extents allowed for a property.  The property extent must
+// be within the within extent and intersect each of the intersects extents.
+type extentConstraint[R region[R]] struct {
+	bounded    bool
+	within     R
+	intersects []R
+}
+
+func (c *extentConstraint[R]) add(test extentTest[R]) {
+	if !test.within {
+		c.intersects = append(c.intersects, test.extent)
+		return
+	}
+	if !c.bounded {
+		c.bounded = true
+		c.within = test.extent
+		return
+	}
+	c.within = c.within.intersect(test.extent)
+}
+
+func (c *extentConstraint[R]) satisfiable() bool {
+	if c.bounded && c.within.empty() {
+		return false
+	}
+	for _, r := range c.intersects {
+		if c.bounded {
+			r = r.intersect(c.within)
+		}
+		if r.empty() {
+			return false
+		}
+	}
+	return true
+}
+
+// implies assumes that the constraint is satisfiable.  When the result is false, an extent made
+// up of points from each of the constrained extents is a counterexample.
+func (c *extentConstraint[R]) implies(test extentTest[R]) Implication {
+	if c.bounded && test.extent.covers(c.within) {
+		return ImplicationTrue
+	}
+
+	if test.within {
+		return ImplicationFalse
+	}
+
+	for _, r := range c.intersects {
+		if c.bounded {
+			r = r.intersect(c.within)
+		}
+		if test.extent.covers(r) {
+			return ImplicationTrue
+		}
+	}
+
+	if len(c.intersects) <= 1 {
+		return ImplicationFalse
+	}
+
+	return ImplicationUnknown
+}
+
+func spatialTestOf(e BooleanExpression) (string, extentTest[box], bool) {
+	comparison, ok := e.(*SpatialComparison)
+	if !ok {
+		return "", extentTest[box]{}, false
+	}
+	property, ok := comparison.Left.(*Property)
+	if !ok {
+		return "", extentTest[box]{}, false
+	}
+	bbox, ok := comparison.Right.(*BoundingBox)
+	if !ok {
+		return "", extentTest[box]{}, false
+	}
+	extent, ok := boxOf(bbox.Extent)
+	if !ok {
+		return "", extentTest[box]{}, false
+	}
+
+	switch comparison.Name {
+	case GeometryWithin:
+		return property.Name, extentTest[box]{extent: extent, within: true, exact: true}, true
+	case GeometryEquals:
+		return property.Name, extentTest[box]{extent: extent, within: true}, true
+	case GeometryIntersects:
+		return property.Name, extentTest[box]{extent: extent, exact: true}, true
+	}
+	return "", extentTest[box]{}, false
+}
+
+func temporalTestOf(e BooleanExpression) (string, extentTest[span[time.Time]], bool) {
+	comparison, ok := e.(*TemporalComparison)
+	if !ok {
+		return "", extentTest[span[time.Time]]{}, false
+	}
+	property, ok := comparison.Left.(*Property)
+	if !ok {
+		return "", extentTest[span[time.Time]]{}, false
+	}
+	extent, ok := temporalSpanOf(comparison.Right)
+	if !ok {
+		return "", extentTest[span[time.Time]]{}, false
+	}
+
+	test := extentTest[span[time.Time]]{within: true, exact: true}
+	switch comparison.Name {
+	case TimeBefore:
+		if !extent.low.set {
+			return "", test, false
+		}
+		test.extent.high = bound[time.Time]{value: extent.low.value, set: true, open: !extent.low.open}
+	case TimeAfter:
+		if !extent.high.set {
+			return "", test, false
+		}
+		test.extent.low = bound[time.Time]{value: extent.high.value, set: true, open: !extent.high.open}
+	case TimeDuring:
+		test.extent = extent
+		test.extent.low.open = true
+		test.extent.high.open = true
+	case TimeEquals:
+		test.extent = extent
+		test.exact = extent.degenerate()
+	case TimeIntersects:
+		test.extent = extent
+		test.within = false
+	default:
+		return "", test, false
+	}
+	return property.Name, test, true
+}
+
+func temporalSpanOf(e Expression) (span[time.Time], bool) {
+	switch t := e.(type) {
+	case *Timestamp:
+		value := bound[time.Time]{value: t.Value, set: true}
+		return span[time.Time]{low: value, high: value}, true
+	case *Date:
+		return span[time.Time]{
+			low:  bound[time.Time]{value: t.Value, set: true},
+			high: bound[time.Time]{value: t.Value.AddDate(0, 0, 1), set: true, open: true},
+		}, true
+	case *Interval:
+		s := span[time.Time]{}
+		if t.Start != nil {
+			start, ok := temporalSpanOf(t.Start)
+			if !ok {
+				return s, false
+			}
+			s.low = start.low
+		}
+		if t.End != nil {
+			end, ok := temporalSpanOf(t.End)
+			if !ok {
+				return s, false
+			}
+			s.high = end.high
+		}
+		return s, true
+	}
+	return span[time.Time]{}, false
+}
+
+type number float64
+
+func (n number) Compare(other number) int {
+	return cmp.Compare(n, other)
+}
+
+type orderable[T any] interface {
+	Compare(T) int
+}
+
+// bound is one end of a span.  An unset bound is unbounded.
+type bound[T orderable[T]] struct {
+	value T
+	set   bool
+	open  bool
+}
+
+// span is a range of ordered values.
+type span[T orderable[T]] struct {
+	low  bound[T]
+	high bound[T]
+}
+
+func (s span[T]) empty() bool {
+	if !s.low.set || !s.high.set {
+		return false
+	}
+	c := s.low.value.Compare(s.high.value)
+	return c > 0 || (c == 0 && (s.low.open || s.high.open))
+}
+
+func (s span[T]) degenerate() bool {
+	return s.low.set && s.high.set && !s.empty() && s.low.value.Compare(s.high.value) == 0
+}
+
+func (s span[T]) intersect(other span[T]) span[T] {
+	low := s.low
+	if !low.set {
+		low = other.low
+	} else if other.low.set {
+		c := low.value.Compare(other.low.value)
+		if c < 0 || (c == 0 && other.low.open) {
+			low = other.low
+		}
+	}
+
+	high := s.high
+	if !high.set {
+		high = other.high
+	} else if other.high.set {
+		c := high.value.Compare(other.high.value)
+		if c > 0 || (c == 0 && other.high.open) {
+			high = other.high
+		}
+	}
+
+	return span[T]{low: low, high: high}
+}
+
+func (s span[T]) covers(other span[T]) bool {
+	if other.empty() {
+		return true
+	}
+	if s.low.set {
+		if !other.low.set {
+			return false
+		}
+		c := s.low.value.Compare(other.low.value)
+		if c > 0 || (c == 0 && s.low.open && !other.low.open) {
+			return false
+		}
+	}
+	if s.high.set {
+		if !other.high.set {
+			return false
+		}
+		c := s.high.value.Compare(other.high.value)
+		if c < 0 || (c == 0 && s.high.open && !other.high.open) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s span[T]) contains(value T) bool {
+	point := bound[T]{value: value, set: true}
+	return s.covers(span[T]{low: point, high: point})
+}
+
+// outside returns the parts of the span that are not in the other span.
+func (s span[T]) outside(other span[T]) []span[T] {
+	pieces := []span[T]{}
+	if other.low.set {
+		below := span[T]{high: bound[T]{value: other.low.value, set: true, open: !other.low.open}}
+		pieces = append(pieces, s.intersect(below))
+	}
+	if other.high.set {
+		above := span[T]{low: bound[T]{value: other.high.value, set: true, open: !other.high.open}}
+		pieces = append(pieces, s.intersect(above))
+	}
+	return pieces
+}
+
+// box is a two-dimensional extent.
+type box struct {
+	x span[number]
+	y span[number]
+}
+
+func boxOf(extent []float64) (box, bool) {
+	var minX, minY, maxX, maxY float64
+	switch len(extent) {
+	case 4:
+		minX, minY, maxX, maxY = extent[0], extent[1], extent[2], extent[3]
+	case 6:
+		minX, minY, maxX, maxY = extent[0], extent[1], extent[3], extent[4]
+	default:
+		return box{}, false
+	}
+	if minX > maxX || minY > maxY {
+		// boxes that cross the antimeridian are not supported
+		return box{}, false
+	}
+	return box{
+		x: span[number]{low: bound[number]{value: number(minX), set: true}, high: bound[number]{value: number(maxX), set: true}},
+		y: span[number]{low: bound[number]{value: number(minY), set: true}, high: bound[number]{value: number(maxY), set: true}},
+	}, true
+}
+
+func (b box) intersect(other box) box {
+	return box{x: b.x.intersect(other.x), y: b.y.intersect(other.y)}
+}
+
+func (b box) covers(other box) bool {
+	return other.empty() || (b.x.covers(other.x) && b.y.covers(other.y))
+}
+
+func (b box) empty() bool {
+	return b.x.empty() || b.y.empty()
+}
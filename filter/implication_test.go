@@ -0,0 +1,322 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImplies(t *testing.T) {
+	cases := []struct {
+		name     string
+		a        string
+		b        string
+		expected filter.Implication
+	}{
+		{
+			name:     "identical",
+			a:        `{"op": "like", "args": [{"property": "name"}, "a%"]}`,
+			b:        `{"op": "like", "args": [{"property": "name"}, "a%"]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "narrower range",
+			a:        `{"op": "between", "args": [{"property": "cloud"}, 10, 20]}`,
+			b:        `{"op": "<=", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "wider range",
+			a:        `{"op": "<", "args": [{"property": "cloud"}, 60]}`,
+			b:        `{"op": "<=", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "open and closed bounds",
+			a:        `{"op": "<", "args": [{"property": "cloud"}, 50]}`,
+			b:        `{"op": "<=", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "closed bound is not open",
+			a:        `{"op": "<=", "args": [{"property": "cloud"}, 50]}`,
+			b:        `{"op": "<", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "excluded boundary",
+			a:        `{"op": "and", "args": [{"op": "<=", "args": [{"property": "cloud"}, 50]}, {"op": "<>", "args": [{"property": "cloud"}, 50]}]}`,
+			b:        `{"op": "<", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "conjunction of bounds",
+			a:        `{"op": "and", "args": [{"op": ">", "args": [{"property": "cloud"}, 5]}, {"op": "<", "args": [10, {"property": "cloud"}]}]}`,
+			b:        `{"op": ">", "args": [{"property": "cloud"}, 10]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "equality in range",
+			a:        `{"op": "=", "args": [{"property": "cloud"}, 15]}`,
+			b:        `{"op": "between", "args": [{"property": "cloud"}, 10, 20]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "equality in list",
+			a:        `{"op": "=", "args": [{"property": "platform"}, "sentinel-2a"]}`,
+			b:        `{"op": "in", "args": [{"property": "platform"}, ["sentinel-2a", "sentinel-2b"]]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "list in list",
+			a:        `{"op": "in", "args": [{"property": "platform"}, ["sentinel-2a", "sentinel-2b"]]}`,
+			b:        `{"op": "in", "args": [{"property": "platform"}, ["sentinel-2a"]]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "list narrowed by inequality",
+			a:        `{"op": "and", "args": [{"op": "in", "args": [{"property": "platform"}, ["sentinel-2a", "sentinel-2b"]]}, {"op": "not", "args": [{"op": "=", "args": [{"property": "platform"}, "sentinel-2b"]}]}]}`,
+			b:        `{"op": "=", "args": [{"property": "platform"}, "sentinel-2a"]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "range does not imply equality",
+			a:        `{"op": ">", "args": [{"property": "cloud"}, 10]}`,
+			b:        `{"op": "=", "args": [{"property": "cloud"}, 20]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "range implies inequality",
+			a:        `{"op": ">", "args": [{"property": "cloud"}, 10]}`,
+			b:        `{"op": "<>", "args": [{"property": "cloud"}, 5]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "unconstrained property",
+			a:        `{"op": "=", "args": [{"property": "cloud"}, 10]}`,
+			b:        `{"op": "=", "args": [{"property": "platform"}, "landsat-8"]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "contradiction implies anything",
+			a:        `{"op": "and", "args": [{"op": "=", "args": [{"property": "cloud"}, 10]}, {"op": "=", "args": [{"property": "cloud"}, 20]}]}`,
+			b:        `{"op": "=", "args": [{"property": "platform"}, "landsat-8"]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "unsupported conjunct prevents false",
+			a:        `{"op": "and", "args": [{"op": "<", "args": [{"property": "cloud"}, 60]}, {"op": "like", "args": [{"property": "name"}, "a%"]}]}`,
+			b:        `{"op": "<=", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationUnknown,
+		},
+		{
+			name:     "unsupported conjunct still allows true",
+			a:        `{"op": "and", "args": [{"op": "<", "args": [{"property": "cloud"}, 40]}, {"op": "like", "args": [{"property": "name"}, "a%"]}]}`,
+			b:        `{"op": "<=", "args": [{"property": "cloud"}, 50]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "unsupported consequent",
+			a:        `{"op": "<", "args": [{"property": "cloud"}, 40]}`,
+			b:        `{"op": "like", "args": [{"property": "name"}, "a%"]}`,
+			expected: filter.ImplicationUnknown,
+		},
+		{
+			name:     "disjunction in antecedent",
+			a:        `{"op": "or", "args": [{"op": "=", "args": [{"property": "cloud"}, 10]}, {"op": "=", "args": [{"property": "cloud"}, 20]}]}`,
+			b:        `{"op": "<", "args": [{"property": "cloud"}, 30]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "disjunction in antecedent with counterexample",
+			a:        `{"op": "or", "args": [{"op": "=", "args": [{"property": "cloud"}, 10]}, {"op": "=", "args": [{"property": "cloud"}, 40]}]}`,
+			b:        `{"op": "<", "args": [{"property": "cloud"}, 30]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "disjunction in consequent",
+			a:        `{"op": "=", "args": [{"property": "cloud"}, 10]}`,
+			b:        `{"op": "or", "args": [{"op": "=", "args": [{"property": "cloud"}, 10]}, {"op": "isNull", "args": [{"property": "cloud"}]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "conjunction in consequent",
+			a:        `{"op": "and", "args": [{"op": "=", "args": [{"property": "cloud"}, 10]}, {"op": "=", "args": [{"property": "platform"}, "landsat-8"]}]}`,
+			b:        `{"op": "and", "args": [{"op": "<", "args": [{"property": "cloud"}, 20]}, {"op": "in", "args": [{"property": "platform"}, ["landsat-8", "landsat-9"]]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "within smaller box",
+			a:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [1, 1, 2, 2]}]}`,
+			b:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "within larger box",
+			a:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			b:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [1, 1, 2, 2]}]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "within implies intersects",
+			a:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [1, 1, 2, 2]}]}`,
+			b:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "intersects smaller box",
+			a:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [1, 1, 2, 2]}]}`,
+			b:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "intersects larger box",
+			a:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			b:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [1, 1, 2, 2]}]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "intersects clipped by within",
+			a:        `{"op": "and", "args": [{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [0, 0, 5, 5]}]}, {"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [4, 4, 10, 10]}]}]}`,
+			b:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [4, 4, 6, 6]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "intersects does not imply within",
+			a:        `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [1, 1, 2, 2]}]}`,
+			b:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "contains as within",
+			a:        `{"op": "s_contains", "args": [{"bbox": [0, 0, 1, 1]}, {"property": "geometry"}]}`,
+			b:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [-1, -1, 1, 1]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "antimeridian box",
+			a:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [170, 0, -170, 10]}]}`,
+			b:        `{"op": "s_within", "args": [{"property": "geometry"}, {"bbox": [0, 0, 10, 10]}]}`,
+			expected: filter.ImplicationUnknown,
+		},
+		{
+			name:     "during narrower interval",
+			a:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-10", "2023-01-20"]}]}`,
+			b:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-01", "2023-01-31"]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "during wider interval",
+			a:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-01", "2023-12-31"]}]}`,
+			b:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-01", "2023-01-31"]}]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "date end covers the day",
+			a:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-10T00:00:00Z", "2023-01-31T12:00:00Z"]}]}`,
+			b:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-01", "2023-01-31"]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "open interval",
+			a:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2023-01-10T00:00:00Z", ".."]}]}`,
+			b:        `{"op": "t_after", "args": [{"property": "datetime"}, {"timestamp": "2023-01-01T00:00:00Z"}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "open interval is unbounded",
+			a:        `{"op": "t_after", "args": [{"property": "datetime"}, {"timestamp": "2023-01-01T00:00:00Z"}]}`,
+			b:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": ["2022-01-01T00:00:00Z", "2024-01-01T00:00:00Z"]}]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "before implies before later",
+			a:        `{"op": "t_before", "args": [{"property": "datetime"}, {"date": "2023-01-01"}]}`,
+			b:        `{"op": "t_before", "args": [{"property": "datetime"}, {"timestamp": "2023-06-01T00:00:00Z"}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "equals timestamp implies intersects",
+			a:        `{"op": "t_equals", "args": [{"property": "datetime"}, {"timestamp": "2023-01-15T00:00:00+02:00"}]}`,
+			b:        `{"op": "t_intersects", "args": [{"property": "datetime"}, {"interval": ["2023-01-01", "2023-01-31"]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "intersects disjoint interval",
+			a:        `{"op": "t_intersects", "args": [{"property": "datetime"}, {"interval": ["2023-01-01", "2023-01-31"]}]}`,
+			b:        `{"op": "t_intersects", "args": [{"property": "datetime"}, {"interval": ["2023-02-01", "2023-02-28"]}]}`,
+			expected: filter.ImplicationFalse,
+		},
+		{
+			name:     "interval with property bound",
+			a:        `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": [{"property": "start"}, "2023-01-31"]}]}`,
+			b:        `{"op": "t_before", "args": [{"property": "datetime"}, {"date": "2023-06-01"}]}`,
+			expected: filter.ImplicationUnknown,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := mustParseFilter(t, c.a)
+			b := mustParseFilter(t, c.b)
+			assert.Equal(t, c.expected.String(), filter.Implies(a, b).String())
+		})
+	}
+}
+
+// TestImpliesNegatedComparison checks that a negated equality comparison is treated as its
+// complement.
+func TestImpliesNegatedComparison(t *testing.T) {
+	cases := []struct {
+		name     string
+		a        string
+		b        string
+		expected filter.Implication
+	}{
+		{
+			name:     "not equal to negated equal",
+			a:        `{"op": "not", "args": [{"op": "=", "args": [{"property": "x"}, 5]}]}`,
+			b:        `{"op": "<>", "args": [{"property": "x"}, 5]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "negated equal to not equal",
+			a:        `{"op": "<>", "args": [{"property": "x"}, 5]}`,
+			b:        `{"op": "not", "args": [{"op": "=", "args": [{"property": "x"}, 5]}]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "negated not equal to equal",
+			a:        `{"op": "not", "args": [{"op": "<>", "args": [{"property": "x"}, 5]}]}`,
+			b:        `{"op": "=", "args": [{"property": "x"}, 5]}`,
+			expected: filter.ImplicationTrue,
+		},
+		{
+			name:     "negated equal to other value",
+			a:        `{"op": "not", "args": [{"op": "=", "args": [{"property": "x"}, 5]}]}`,
+			b:        `{"op": "=", "args": [{"property": "x"}, 6]}`,
+			expected: filter.ImplicationFalse,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, filter.Implies(mustParseFilter(t, c.a), mustParseFilter(t, c.b)))
+		})
+	}
+}
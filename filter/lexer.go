@@ -0,0 +1,594 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdentifier
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenTime
+	tokenOperator
+	tokenPunctuation
+)
+
+// token is a lexical token.  For strings and quoted identifiers, the value has quotes
+// removed and escapes replaced.
+type token struct {
+	kind  tokenType
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenString:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(t.value, "'", "''"))
+	case tokenQuotedIdentifier:
+		return fmt.Sprintf("%q", t.value)
+	}
+	return t.value
+}
+
+// is checks whether the token is a keyword or symbol.  Keywords are not case sensitive.
+func (t token) is(value string) bool {
+	switch t.kind {
+	case tokenIdentifier:
+		return strings.EqualFold(t.value, value)
+	case tokenOperator, tokenPunctuation:
+		return t.value == value
+	}
+	return false
+}
+
+var timePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?)?`)
+
+// lexer splits text filters into tokens.
+type lexer struct {
+	input string
+	pos   int
+
+	// times enables unquoted date and timestamp tokens
+	times bool
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		l.pos += size
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	start := l.pos
+	if start >= len(l.input) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(l.input[start:])
+	switch {
+	case r == '\'':
+		value, err := l.scanQuoted('\'')
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokenString, value: value, pos: start}, nil
+
+	case r == '"':
+		value, err := l.scanQuoted('"')
+		if err != nil {
+			return token{}, err
+		}
+		return token{kind: tokenQuotedIdentifier, value: value, pos: start}, nil
+
+	case isDigit(r) || (r == '.' && start+1 < len(l.input) && isDigit(rune(l.input[start+1]))):
+		if l.times {
+			if match := timePattern.FindString(l.input[start:]); match != "" {
+				l.pos += len(match)
+				return token{kind: tokenTime, value: match, pos: start}, nil
+			}
+		}
+		l.pos += len(scanNumber(l.input[start:]))
+		return token{kind: tokenNumber, value: l.input[start:l.pos], pos: start}, nil
+
+	case isIdentifierStart(r):
+		l.pos += size
+		for l.pos < len(l.input) {
+			r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+			if !isIdentifierPart(r) {
+				break
+			}
+			l.pos += size
+		}
+		return token{kind: tokenIdentifier, value: l.input[start:l.pos], pos: start}, nil
+
+	case strings.ContainsRune("(),[]", r):
+		l.pos += size
+		return token{kind: tokenPunctuation, value: string(r), pos: start}, nil
+
+	case strings.ContainsRune("=<>!+-*/%^", r):
+		for _, operator := range []string{"<>", "<=", ">=", "!="} {
+			if strings.HasPrefix(l.input[start:], operator) {
+				l.pos += len(operator)
+				return token{kind: tokenOperator, value: operator, pos: start}, nil
+			}
+		}
+		l.pos += size
+		return token{kind: tokenOperator, value: string(r), pos: start}, nil
+	}
+
+	return token{}, fmt.Errorf("unexpected character %q at position %d", r, start)
+}
+
+// scanQuoted scans a quoted value, where a repeated quote is an escaped quote.
+func (l *lexer) scanQuoted(quote byte) (string, error) {
+	start := l.pos
+	builder := &strings.Builder{}
+	l.pos++
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		l.pos++
+		if c != quote {
+			builder.WriteByte(c)
+			continue
+		}
+		if l.pos < len(l.input) && l.input[l.pos] == quote {
+			builder.WriteByte(quote)
+			l.pos++
+			continue
+		}
+		return builder.String(), nil
+	}
+	return "", fmt.Errorf("unterminated quote starting at position %d", start)
+}
+
+// scanGeometry returns the text of a WKT geometry starting at the given position.  The text
+// includes the type keyword, any dimension tags, and either EMPTY or a balanced parenthesized
+// list of coordinates.
+func (l *lexer) scanGeometry(start int) (string, error) {
+	l.pos = start
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.input) {
+			return "", fmt.Errorf("unexpected end of input in geometry starting at position %d", start)
+		}
+
+		if l.input[l.pos] == '(' {
+			depth := 0
+			for l.pos < len(l.input) {
+				switch l.input[l.pos] {
+				case '(':
+					depth++
+				case ')':
+					depth--
+				}
+				l.pos++
+				if depth == 0 {
+					return l.input[start:l.pos], nil
+				}
+			}
+			return "", fmt.Errorf("unbalanced parentheses in geometry starting at position %d", start)
+		}
+
+		wordStart := l.pos
+		for l.pos < len(l.input) && isLetter(rune(l.input[l.pos])) {
+			l.pos++
+		}
+		if l.pos == wordStart {
+			return "", fmt.Errorf("unexpected character %q in geometry at position %d", l.input[l.pos], l.pos)
+		}
+		if strings.EqualFold(l.input[wordStart:l.pos], "EMPTY") {
+			return l.input[start:l.pos], nil
+		}
+	}
+}
+
+func scanNumber(input string) string {
+	end := 0
+	for end < len(input) && isDigit(rune(input[end])) {
+		end++
+	}
+	if end < len(input) && input[end] == '.' {
+		end++
+		for end < len(input) && isDigit(rune(input[end])) {
+			end++
+		}
+	}
+	if end < len(input) && (input[end] == 'e' || input[end] == 'E') {
+		exponent := end + 1
+		if exponent < len(input) && (input[exponent] == '+' || input[exponent] == '-') {
+			exponent++
+		}
+		if exponent < len(input) && isDigit(rune(input[exponent])) {
+			end = exponent
+			for end < len(input) && isDigit(rune(input[end])) {
+				end++
+			}
+		}
+	}
+	return input[:end]
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentifierPart(r rune) bool {
+	return r == '_' || r == ':' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// grammar provides the parts of a text filter syntax that differ between CQL2 Text and ECQL.
+// The parser handles the boolean structure (OR, AND, NOT, and parentheses) and the predicates
+// the two syntaxes share.
+type grammar interface {
+	// parseExpression parses an operand of a predicate.
+	parseExpression() (Expression, error)
+
+	// parseKeywordPredicate parses a predicate that starts with the given token (e.g. BBOX in
+	// ECQL).  The result is nil if the token does not start such a predicate.
+	parseKeywordPredicate(t token) (BooleanExpression, error)
+
+	// parseKeywordComparison parses a predicate where the keyword follows the first operand
+	// (e.g. BEFORE in ECQL).  The result is nil if the keyword is not handled.
+	parseKeywordComparison(keyword string, left Expression, t token) (BooleanExpression, error)
+
+	// parseLike parses the pattern that follows the LIKE (or ILIKE) keyword.
+	parseLike(keyword string, value CharacterExpression, t token) (BooleanExpression, error)
+
+	// parseInList parses the list that follows the IN keyword.
+	parseInList() (ScalarList, error)
+}
+
+// parser provides a stream of tokens with one token of lookahead and parses boolean
+// expressions using the provided grammar.
+type parser struct {
+	lexer  *lexer
+	peeked *token
+
+	grammar grammar
+
+	// comparisons maps comparison operators to op names
+	comparisons map[string]string
+
+	// continuations are the tokens that can follow the first operand in a predicate
+	continuations []string
+}
+
+type parserState struct {
+	pos    int
+	peeked *token
+}
+
+func (p *parser) peek() (token, error) {
+	if p.peeked == nil {
+		t, err := p.lexer.next()
+		if err != nil {
+			return token{}, err
+		}
+		p.peeked = &t
+	}
+	return *p.peeked, nil
+}
+
+func (p *parser) next() (token, error) {
+	t, err := p.peek()
+	if err != nil {
+		return token{}, err
+	}
+	p.peeked = nil
+	return t, nil
+}
+
+// accept consumes the next token if it is the given keyword or symbol.
+func (p *parser) accept(value string) (bool, error) {
+	t, err := p.peek()
+	if err != nil {
+		return false, err
+	}
+	if !t.is(value) {
+		return false, nil
+	}
+	p.peeked = nil
+	return true, nil
+}
+
+// expect consumes the next token and returns an error if it is not the given keyword or symbol.
+func (p *parser) expect(value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !t.is(value) {
+		return fmt.Errorf("expected %q, found %s at position %d", value, t, t.pos)
+	}
+	return nil
+}
+
+func (p *parser) state() parserState {
+	return parserState{pos: p.lexer.pos, peeked: p.peeked}
+}
+
+func (p *parser) restore(state parserState) {
+	p.lexer.pos = state.pos
+	p.peeked = state.peeked
+}
+
+func unexpectedToken(t token) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of input at position %d", t.pos)
+	}
+	return fmt.Errorf("unexpected %s at position %d", t, t.pos)
+}
+
+func (p *parser) parseOr() (BooleanExpression, error) {
+	args := []BooleanExpression{}
+	for {
+		arg, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		ok, err := p.accept("OR")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &Or{Args: args}, nil
+}
+
+func (p *parser) parseAnd() (BooleanExpression, error) {
+	args := []BooleanExpression{}
+	for {
+		arg, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		ok, err := p.accept("AND")
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	return &And{Args: args}, nil
+}
+
+func (p *parser) parseNot() (BooleanExpression, error) {
+	ok, err := p.accept("NOT")
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		arg, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Arg: arg}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (BooleanExpression, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	var parenthesizedErr error
+	if t.is("(") {
+		expression, err := p.tryParenthesized()
+		if err == nil && expression != nil {
+			return expression, nil
+		}
+		parenthesizedErr = err
+	}
+
+	expression, err := p.grammar.parseKeywordPredicate(t)
+	if err != nil || expression != nil {
+		return expression, err
+	}
+
+	left, err := p.grammar.parseExpression()
+	if err != nil {
+		if parenthesizedErr != nil {
+			return nil, parenthesizedErr
+		}
+		return nil, err
+	}
+	return p.parseComparison(left)
+}
+
+// tryParenthesized tries to parse a parenthesized boolean expression.  If the parentheses
+// instead start an expression like "(a + 1) > 2", the parser state is restored and the
+// result is nil.  If parsing fails, the parser state is restored and the error is returned.
+func (p *parser) tryParenthesized() (BooleanExpression, error) {
+	state := p.state()
+	_, _ = p.next()
+
+	expression, err := p.parseOr()
+	if err == nil {
+		err = p.expect(")")
+	}
+	if err == nil {
+		t, peekErr := p.peek()
+		if peekErr == nil && !p.isPredicateContinuation(t) {
+			return expression, nil
+		}
+	}
+
+	p.restore(state)
+	return nil, err
+}
+
+func (p *parser) isPredicateContinuation(t token) bool {
+	for _, value := range p.continuations {
+		if t.is(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parseComparison(left Expression) (BooleanExpression, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if name, ok := p.comparisons[t.value]; ok && t.kind == tokenOperator {
+		_, _ = p.next()
+		right, err := p.grammar.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		scalarArgs, err := toScalarArgs(name, []Expression{left, right})
+		if err != nil {
+			return nil, err
+		}
+		return &Comparison{Name: name, Left: scalarArgs[0], Right: scalarArgs[1]}, nil
+	}
+
+	keyword := strings.ToUpper(t.value)
+	if t.kind != tokenIdentifier {
+		keyword = ""
+	}
+
+	switch keyword {
+	case "NOT":
+		_, _ = p.next()
+		t, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind != tokenIdentifier {
+			return nil, unexpectedToken(t)
+		}
+		expression, err := p.parseNegatable(strings.ToUpper(t.value), left, t)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Arg: expression}, nil
+
+	case "BETWEEN", "LIKE", "ILIKE", "IN":
+		_, _ = p.next()
+		return p.parseNegatable(keyword, left, t)
+
+	case "IS":
+		_, _ = p.next()
+		not, err := p.accept("NOT")
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("NULL"); err != nil {
+			return nil, err
+		}
+		var expression BooleanExpression = &IsNull{Value: left}
+		if not {
+			expression = &Not{Arg: expression}
+		}
+		return expression, nil
+	}
+
+	expression, err := p.grammar.parseKeywordComparison(keyword, left, t)
+	if err != nil || expression != nil {
+		return expression, err
+	}
+
+	if expression, ok := left.(BooleanExpression); ok {
+		return expression, nil
+	}
+	return nil, unexpectedToken(t)
+}
+
+// parseNegatable parses the predicates that can be negated with NOT after the first operand.
+func (p *parser) parseNegatable(keyword string, left Expression, t token) (BooleanExpression, error) {
+	switch keyword {
+	case "BETWEEN":
+		low, err := p.grammar.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.grammar.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		numericArgs, err := toNumericArgs(betweenOp, []Expression{left, low, high})
+		if err != nil {
+			return nil, err
+		}
+		return &Between{Value: numericArgs[0], Low: numericArgs[1], High: numericArgs[2]}, nil
+
+	case "LIKE", "ILIKE":
+		value, ok := left.(CharacterExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected a character expression before %s at position %d", keyword, t.pos)
+		}
+		return p.grammar.parseLike(keyword, value, t)
+
+	case "IN":
+		item, ok := left.(ScalarExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected a scalar expression before IN at position %d", t.pos)
+		}
+		list, err := p.grammar.parseInList()
+		if err != nil {
+			return nil, err
+		}
+		return &In{Item: item, List: list}, nil
+	}
+
+	return nil, unexpectedToken(t)
+}
@@ -0,0 +1,271 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	likeWildcardChar = '%'
+	likeSingleChar   = '_'
+	likeEscapeChar   = '\\'
+)
+
+type likeTokenKind int
+
+const (
+	likeLiteral likeTokenKind = iota
+	likeAny
+	likeSingle
+)
+
+type likeToken struct {
+	kind likeTokenKind
+	char rune
+
+	// folded is the literal character after case or accent folding
+	folded string
+}
+
+// likeSyntax identifies the wildcard, single character, and escape characters in a pattern.
+type likeSyntax struct {
+	wildcard rune
+	single   rune
+	escape   rune
+}
+
+// cql2LikeSyntax is the syntax of CQL2 like patterns.
+var cql2LikeSyntax = likeSyntax{wildcard: likeWildcardChar, single: likeSingleChar, escape: likeEscapeChar}
+
+// LikePattern is a compiled like pattern.  In a pattern, % matches any number of characters, _
+// matches a single character, and \ escapes the character that follows it.  A trailing \ matches
+// itself.
+//
+// If the pattern is case or accent insensitive, characters are folded one at a time with
+// FoldCase and FoldAccents, so _ always matches a single character of the value.  This differs
+// from comparisons, where the whole value is folded: "ß" is equal to casei("SS"), but does not
+// match the pattern casei("__").
+type LikePattern struct {
+	// CaseInsensitive is true if the pattern was wrapped with casei.
+	CaseInsensitive bool
+
+	// AccentInsensitive is true if the pattern was wrapped with accenti.
+	AccentInsensitive bool
+
+	tokens []likeToken
+}
+
+// CompileLike compiles the pattern of a like predicate.  The pattern must be a string, optionally
+// wrapped with casei or accenti.
+func CompileLike(pattern PatternExpression) (*LikePattern, error) {
+	expr, caseInsensitive, accentInsensitive := unwrapInsensitive(pattern)
+	if e, ok := expr.(*String); ok {
+		return NewLikePattern(e.Value, caseInsensitive, accentInsensitive), nil
+	}
+	return nil, fmt.Errorf("expected a string pattern, got %s", describe(expr))
+}
+
+// unwrapInsensitive removes any casei and accenti wrappers from an expression.
+func unwrapInsensitive(expr Expression) (Expression, bool, bool) {
+	caseInsensitive := false
+	accentInsensitive := false
+	for {
+		switch e := expr.(type) {
+		case *CaseInsensitive:
+			caseInsensitive = true
+			expr = e.Value
+		case *AccentInsensitive:
+			accentInsensitive = true
+			expr = e.Value
+		default:
+			return expr, caseInsensitive, accentInsensitive
+		}
+	}
+}
+
+// NewLikePattern compiles a pattern string.
+func NewLikePattern(pattern string, caseInsensitive bool, accentInsensitive bool) *LikePattern {
+	p := &LikePattern{
+		CaseInsensitive:   caseInsensitive,
+		AccentInsensitive: accentInsensitive,
+	}
+	if p.folding() {
+		pattern = norm.NFC.String(pattern)
+	}
+	p.tokens = parseLikePattern(pattern, cql2LikeSyntax)
+	for i, token := range p.tokens {
+		if token.kind == likeLiteral {
+			p.tokens[i].folded = p.fold(token.char)
+		}
+	}
+	return p
+}
+
+// parseLikePattern splits a pattern into tokens.  An escape character at the end of the pattern
+// is a literal.
+func parseLikePattern(pattern string, syntax likeSyntax) []likeToken {
+	tokens := []likeToken{}
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == syntax.escape && i+1 < len(runes):
+			i++
+			tokens = append(tokens, likeToken{kind: likeLiteral, char: runes[i]})
+		case r == syntax.wildcard:
+			if len(tokens) > 0 && tokens[len(tokens)-1].kind == likeAny {
+				continue
+			}
+			tokens = append(tokens, likeToken{kind: likeAny})
+		case r == syntax.single:
+			tokens = append(tokens, likeToken{kind: likeSingle})
+		default:
+			tokens = append(tokens, likeToken{kind: likeLiteral, char: r})
+		}
+	}
+	return tokens
+}
+
+// formatLikePattern writes tokens as a CQL2 pattern.  Literal %, _, and \ characters are
+// escaped.
+func formatLikePattern(tokens []likeToken) string {
+	builder := &strings.Builder{}
+	for _, token := range tokens {
+		switch token.kind {
+		case likeAny:
+			builder.WriteRune(likeWildcardChar)
+		case likeSingle:
+			builder.WriteRune(likeSingleChar)
+		default:
+			writeLikeLiteral(builder, token.char)
+		}
+	}
+	return builder.String()
+}
+
+func writeLikeLiteral(builder *strings.Builder, r rune) {
+	if r == likeWildcardChar || r == likeSingleChar || r == likeEscapeChar {
+		builder.WriteRune(likeEscapeChar)
+	}
+	builder.WriteRune(r)
+}
+
+func (p *LikePattern) folding() bool {
+	return p.CaseInsensitive || p.AccentInsensitive
+}
+
+// fold returns a single character after case and accent folding.
+func (p *LikePattern) fold(r rune) string {
+	value := string(r)
+	if p.CaseInsensitive {
+		value = FoldCase(value)
+	}
+	if p.AccentInsensitive {
+		value = FoldAccents(value)
+	}
+	return value
+}
+
+// Match reports whether the value matches the pattern.
+func (p *LikePattern) Match(value string) bool {
+	var runes []rune
+	var folded []string
+	if p.folding() {
+		runes = []rune(norm.NFC.String(value))
+		folded = make([]string, len(runes))
+		for i, r := range runes {
+			folded[i] = p.fold(r)
+		}
+	} else {
+		runes = []rune(value)
+	}
+	equal := func(token likeToken, v int) bool {
+		if folded != nil {
+			return token.folded == folded[v]
+		}
+		return token.char == runes[v]
+	}
+
+	tokens := p.tokens
+	t, v := 0, 0
+	star, mark := -1, 0
+	for v < len(runes) {
+		if t < len(tokens) {
+			switch token := tokens[t]; {
+			case token.kind == likeAny:
+				star, mark = t, v
+				t++
+				continue
+			case token.kind == likeSingle, token.kind == likeLiteral && equal(token, v):
+				t++
+				v++
+				continue
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		mark++
+		t, v = star+1, mark
+	}
+	for t < len(tokens) && tokens[t].kind == likeAny {
+		t++
+	}
+	return t == len(tokens)
+}
+
+// SQL returns the pattern for use with SQL LIKE (or ILIKE) and ESCAPE '\'.  Literal %, _, and \
+// characters are escaped.  If the pattern is accent insensitive, literals are folded with
+// FoldAccents and the value should be compared with its accents removed (e.g. with unaccent in
+// PostgreSQL).
+func (p *LikePattern) SQL() string {
+	builder := &strings.Builder{}
+	literal := &strings.Builder{}
+	flush := func() {
+		value := literal.String()
+		if p.AccentInsensitive {
+			value = FoldAccents(value)
+		}
+		for _, r := range value {
+			writeLikeLiteral(builder, r)
+		}
+		literal.Reset()
+	}
+	for _, token := range p.tokens {
+		switch token.kind {
+		case likeAny:
+			flush()
+			builder.WriteRune(likeWildcardChar)
+		case likeSingle:
+			flush()
+			builder.WriteRune(likeSingleChar)
+		default:
+			literal.WriteRune(token.char)
+		}
+	}
+	flush()
+	return builder.String()
+}
+
+// SQLOperator returns ILIKE if the pattern is case insensitive and LIKE otherwise.
+func (p *LikePattern) SQLOperator() string {
+	if p.CaseInsensitive {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikePattern(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		value    string
+		expected bool
+	}{
+		{pattern: `Hello%`, value: "Hello World", expected: true},
+		{pattern: `%World`, value: "Hello World", expected: true},
+		{pattern: `%lo W%`, value: "Hello World", expected: true},
+		{pattern: `H_llo%`, value: "Hello", expected: true},
+		{pattern: `H_llo`, value: "Hllo", expected: false},
+		{pattern: `%`, value: "", expected: true},
+		{pattern: `_`, value: "", expected: false},
+		{pattern: ``, value: "", expected: true},
+		{pattern: ``, value: "a", expected: false},
+		{pattern: `a%b%c`, value: "aXbYbZc", expected: true},
+		{pattern: `a%b%c`, value: "aXbYbZ", expected: false},
+		{pattern: `%%a`, value: "bba", expected: true},
+		{pattern: `100\%`, value: "100%", expected: true},
+		{pattern: `100\%`, value: "1000", expected: false},
+		{pattern: `a\_b`, value: "a_b", expected: true},
+		{pattern: `a\_b`, value: "axb", expected: false},
+		{pattern: `a\\%`, value: `a\b`, expected: true},
+		{pattern: `a\b`, value: "ab", expected: true},
+		{pattern: `a\`, value: `a\`, expected: true},
+		{pattern: `Chi_a`, value: "Chiça", expected: true},
+		{pattern: "line%", value: "line\nbreak", expected: true},
+		{pattern: `hello%`, value: "Hello", expected: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.pattern+" "+c.value, func(t *testing.T) {
+			assert.Equal(t, c.expected, filter.NewLikePattern(c.pattern, false, false).Match(c.value))
+		})
+	}
+}
+
+func TestCompileLike(t *testing.T) {
+	cases := []struct {
+		name        string
+		pattern     filter.PatternExpression
+		matches     []string
+		nonMatches  []string
+		sql         string
+		sqlOperator string
+	}{
+		{
+			name:        "string",
+			pattern:     &filter.String{Value: `Ch%`},
+			matches:     []string{"Chiça", "Ch"},
+			nonMatches:  []string{"chiça"},
+			sql:         `Ch%`,
+			sqlOperator: "LIKE",
+		},
+		{
+			name:        "case insensitive",
+			pattern:     &filter.CaseInsensitive{Value: &filter.String{Value: `straße%`}},
+			matches:     []string{"STRAẞE 1", "Straße"},
+			nonMatches:  []string{"Strase", "STRASSE 1"},
+			sql:         `straße%`,
+			sqlOperator: "ILIKE",
+		},
+		{
+			name:        "case insensitive single character",
+			pattern:     &filter.CaseInsensitive{Value: &filter.String{Value: `stra_e`}},
+			matches:     []string{"Straße", "STRAẞE"},
+			nonMatches:  []string{"STRASSE"},
+			sql:         `stra_e`,
+			sqlOperator: "ILIKE",
+		},
+		{
+			name:        "accent insensitive decomposed value",
+			pattern:     &filter.AccentInsensitive{Value: &filter.String{Value: `Chi_a`}},
+			matches:     []string{"Chic\u0327a", "Chica"},
+			nonMatches:  []string{"Chic\u0327\u0327a"},
+			sql:         `Chi_a`,
+			sqlOperator: "LIKE",
+		},
+		{
+			name:        "accent insensitive",
+			pattern:     &filter.AccentInsensitive{Value: &filter.String{Value: `Chiça`}},
+			matches:     []string{"Chica", "Chiça"},
+			nonMatches:  []string{"chica"},
+			sql:         `Chica`,
+			sqlOperator: "LIKE",
+		},
+		{
+			name: "case and accent insensitive",
+			pattern: &filter.CaseInsensitive{Value: &filter.AccentInsensitive{
+				Value: &filter.String{Value: `%ÇA`},
+			}},
+			matches:     []string{"Chiça", "chica"},
+			nonMatches:  []string{"Chic"},
+			sql:         `%CA`,
+			sqlOperator: "ILIKE",
+		},
+		{
+			name:        "escapes",
+			pattern:     &filter.String{Value: `50\% off\_\\%`},
+			matches:     []string{`50% off_\ today`},
+			nonMatches:  []string{`500 off_\ today`, `50% offX\ today`},
+			sql:         `50\% off\_\\%`,
+			sqlOperator: "LIKE",
+		},
+		{
+			name:        "unneeded escapes",
+			pattern:     &filter.String{Value: `\a\b_\`},
+			matches:     []string{`abc\`},
+			nonMatches:  []string{`\a\bc\`},
+			sql:         `ab_\\`,
+			sqlOperator: "LIKE",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pattern, err := filter.CompileLike(c.pattern)
+			require.NoError(t, err)
+			for _, value := range c.matches {
+				assert.True(t, pattern.Match(value), value)
+			}
+			for _, value := range c.nonMatches {
+				assert.False(t, pattern.Match(value), value)
+			}
+			assert.Equal(t, c.sql, pattern.SQL())
+			assert.Equal(t, c.sqlOperator, pattern.SQLOperator())
+		})
+	}
+}
+
+func TestCompileLikeError(t *testing.T) {
+	_, err := filter.CompileLike(&filter.CaseInsensitive{Value: &filter.Property{Name: "pattern"}})
+	assert.EqualError(t, err, `expected a string pattern, got {"property":"pattern"}`)
+}
@@ -100,10 +100,6 @@ var argCount = map[string]int{
 }
 
 func decodeOp(name string, encodedArgs []any) (Expression, error) {
-	if fixedArgCount, ok := argCount[name]; ok && len(encodedArgs) != fixedArgCount {
-		return nil, fmt.Errorf("expected %d args for %q op, found %d", fixedArgCount, name, len(encodedArgs))
-	}
-
 	args := make([]Expression, len(encodedArgs))
 	for i, arg := range encodedArgs {
 		argument, err := decodeExpression(arg)
@@ -113,6 +109,15 @@ func decodeOp(name string, encodedArgs []any) (Expression, error) {
 		args[i] = argument
 	}
 
+	return newOp(name, args)
+}
+
+// newOp creates the expression for an op from its decoded args.
+func newOp(name string, args []Expression) (Expression, error) {
+	if fixedArgCount, ok := argCount[name]; ok && len(args) != fixedArgCount {
+		return nil, fmt.Errorf("expected %d args for %q op, found %d", fixedArgCount, name, len(args))
+	}
+
 	switch name {
 	case notOp:
 		boolArg, ok := args[0].(BooleanExpression)
@@ -0,0 +1,207 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrHiddenProperty is returned by Policy.Apply when a client filter references a hidden property.
+var ErrHiddenProperty = errors.New("reference to hidden property")
+
+// Policy restricts the filters that clients can apply.
+type Policy struct {
+	// Mandatory is combined with every client filter.  Items that do not match the mandatory
+	// filter are never selected, regardless of the client filter.
+	Mandatory BooleanExpression
+
+	// Hidden lists properties that clients cannot reference.  Names are parsed as property
+	// paths, and a hidden path also hides any path nested below it (and any path that it is
+	// nested below).  A leading "properties" segment is ignored when comparing paths, so
+	// hiding "owner" also hides "properties.owner".  Because member names may contain "."
+	// (see PropertyPath.Resolve), paths are compared with their segments joined by ".", so
+	// hiding "eo:cloud.cover" also hides "/eo:cloud.cover" and hiding "/a.b" also hides "a.b".  A reference to the whole properties object
+	// is rejected if any property is hidden.
+	Hidden []string
+
+	// Strip determines how references to hidden properties are handled.  By default, a client
+	// filter with a reference to a hidden property is rejected.  If Strip is true, the
+	// smallest boolean expression containing the reference is removed instead.
+	Strip bool
+}
+
+// Apply combines a client filter with the policy.  The result is the mandatory filter and the
+// client filter joined with "and", so there is no client filter that selects items that do
+// not match the mandatory filter.  The client filter may be nil.  The result is nil if there
+// is no mandatory filter and nothing remains of the client filter.
+func (p *Policy) Apply(client *Filter) (*Filter, error) {
+	hidden := make([]string, len(p.Hidden))
+	for i, name := range p.Hidden {
+		path, err := ParsePropertyPath(name)
+		if err != nil {
+			return nil, fmt.Errorf("trouble parsing hidden property: %w", err)
+		}
+		hidden[i] = strings.Join(withoutPropertiesPrefix(path), ".")
+	}
+
+	var expression BooleanExpression
+	if client != nil && client.Expression != nil {
+		expression = unwrapFilter(client.Expression)
+	}
+
+	if expression != nil {
+		checker := &policyChecker{hidden: hidden}
+		if p.Strip {
+			stripped, err := checker.strip(expression)
+			if err != nil {
+				return nil, err
+			}
+			expression = stripped
+		} else if err := checker.check(expression); err != nil {
+			return nil, err
+		}
+	}
+
+	var mandatory BooleanExpression
+	if p.Mandatory != nil {
+		mandatory = unwrapFilter(p.Mandatory)
+	}
+
+	switch {
+	case mandatory == nil && expression == nil:
+		return nil, nil
+	case mandatory == nil:
+		return &Filter{Expression: expression}, nil
+	case expression == nil:
+		return &Filter{Expression: mandatory}, nil
+	}
+	return &Filter{Expression: &And{Args: []BooleanExpression{mandatory, expression}}}, nil
+}
+
+func withoutPropertiesPrefix(path PropertyPath) PropertyPath {
+	if len(path) > 1 && path[0] == "properties" {
+		return path[1:]
+	}
+	return path
+}
+
+type policyChecker struct {
+	// hidden paths with segments joined by "."
+	hidden []string
+}
+
+func (c *policyChecker) isHidden(property *Property) (bool, error) {
+	path, err := property.Path()
+	if err != nil {
+		return false, fmt.Errorf("trouble parsing property: %w", err)
+	}
+	if len(path) == 1 && path[0] == "properties" {
+		// the properties object includes every hidden property
+		return len(c.hidden) > 0, nil
+	}
+	// the joined form matches every way of resolving the path (e.g. "a.b" as one member or two)
+	name := strings.Join(withoutPropertiesPrefix(path), ".")
+
+	for _, hidden := range c.hidden {
+		if isPathPrefix(hidden, name) || isPathPrefix(name, hidden) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isPathPrefix reports whether the joined path a is equal to or a parent of the joined path b.
+func isPathPrefix(a string, b string) bool {
+	return a == b || strings.HasPrefix(b, a+".")
+}
+
+// check returns an error if the expression references a hidden property.
+func (c *policyChecker) check(expr Expression) error {
+	var err error
+	Walk(expr, func(e Expression) bool {
+		if err != nil {
+			return false
+		}
+		property, ok := e.(*Property)
+		if !ok {
+			return true
+		}
+		hidden, parseErr := c.isHidden(property)
+		if parseErr != nil {
+			err = parseErr
+		} else if hidden {
+			err = fmt.Errorf("%w %q", ErrHiddenProperty, property.Name)
+		}
+		return false
+	})
+	return err
+}
+
+// strip returns a copy of the expression without any boolean expressions that reference hidden
+// properties.  The result is nil if the whole expression is removed.
+func (c *policyChecker) strip(expr BooleanExpression) (BooleanExpression, error) {
+	switch e := expr.(type) {
+	case *Filter:
+		return c.strip(e.Expression)
+	case *Not:
+		arg, err := c.strip(e.Arg)
+		if err != nil || arg == nil {
+			return nil, err
+		}
+		return &Not{Arg: arg}, nil
+	case *And:
+		args, err := c.stripArgs(e.Args)
+		if err != nil || len(args) == 0 {
+			return nil, err
+		}
+		if len(args) == 1 {
+			return args[0], nil
+		}
+		return &And{Args: args}, nil
+	case *Or:
+		args, err := c.stripArgs(e.Args)
+		if err != nil || len(args) == 0 {
+			return nil, err
+		}
+		if len(args) == 1 {
+			return args[0], nil
+		}
+		return &Or{Args: args}, nil
+	}
+
+	if err := c.check(expr); err != nil {
+		if errors.Is(err, ErrHiddenProperty) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return expr, nil
+}
+
+func (c *policyChecker) stripArgs(args []BooleanExpression) ([]BooleanExpression, error) {
+	stripped := []BooleanExpression{}
+	for _, arg := range args {
+		s, err := c.strip(arg)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			stripped = append(stripped, s)
+		}
+	}
+	return stripped, nil
+}
@@ -0,0 +1,217 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyApply(t *testing.T) {
+	tenant := `{"op": "=", "args": [{"property": "tenant"}, "acme"]}`
+
+	cases := []struct {
+		name     string
+		policy   *filter.Policy
+		client   string
+		expected string
+		err      string
+	}{
+		{
+			name:     "mandatory and client",
+			policy:   &filter.Policy{Mandatory: mustParseFilter(t, tenant)},
+			client:   `{"op": "<", "args": [{"property": "cloud"}, 10]}`,
+			expected: `{"op": "and", "args": [` + tenant + `, {"op": "<", "args": [{"property": "cloud"}, 10]}]}`,
+		},
+		{
+			name:   "or cannot bypass mandatory",
+			policy: &filter.Policy{Mandatory: mustParseFilter(t, tenant)},
+			client: `{"op": "or", "args": [{"op": "<", "args": [{"property": "cloud"}, 10]}, {"op": "not", "args": [` + tenant + `]}]}`,
+			expected: `{"op": "and", "args": [` + tenant + `, ` +
+				`{"op": "or", "args": [{"op": "<", "args": [{"property": "cloud"}, 10]}, {"op": "not", "args": [` + tenant + `]}]}]}`,
+		},
+		{
+			name:     "mandatory without client",
+			policy:   &filter.Policy{Mandatory: mustParseFilter(t, tenant)},
+			expected: tenant,
+		},
+		{
+			name:     "mandatory may reference hidden properties",
+			policy:   &filter.Policy{Mandatory: mustParseFilter(t, tenant), Hidden: []string{"tenant"}},
+			client:   `{"op": "<", "args": [{"property": "cloud"}, 10]}`,
+			expected: `{"op": "and", "args": [` + tenant + `, {"op": "<", "args": [{"property": "cloud"}, 10]}]}`,
+		},
+		{
+			name:   "reject hidden property",
+			policy: &filter.Policy{Hidden: []string{"tenant"}},
+			client: `{"op": "or", "args": [{"op": "<", "args": [{"property": "cloud"}, 10]}, {"op": "=", "args": [{"property": "tenant"}, "other"]}]}`,
+			err:    `reference to hidden property "tenant"`,
+		},
+		{
+			name:   "reject nested hidden property",
+			policy: &filter.Policy{Hidden: []string{"owner"}},
+			client: `{"op": "=", "args": [{"property": "/properties/owner/email"}, "a@example.com"]}`,
+			err:    `reference to hidden property "/properties/owner/email"`,
+		},
+		{
+			name:   "reject parent of hidden property",
+			policy: &filter.Policy{Hidden: []string{"owner.email"}},
+			client: `{"op": "isNull", "args": [{"property": "owner"}]}`,
+			err:    `reference to hidden property "owner"`,
+		},
+		{
+			name:   "reject properties object",
+			policy: &filter.Policy{Hidden: []string{"owner"}},
+			client: `{"op": "not", "args": [{"op": "isNull", "args": [{"property": "properties"}]}]}`,
+			err:    `reference to hidden property "properties"`,
+		},
+		{
+			name:   "reject properties object pointer",
+			policy: &filter.Policy{Hidden: []string{"properties.owner"}},
+			client: `{"op": "not", "args": [{"op": "isNull", "args": [{"property": "/properties"}]}]}`,
+			err:    `reference to hidden property "/properties"`,
+		},
+		{
+			name:   "strip properties object",
+			policy: &filter.Policy{Hidden: []string{"owner"}, Strip: true},
+			client: `{"op": "and", "args": [
+				{"op": "not", "args": [{"op": "isNull", "args": [{"property": "properties"}]}]},
+				{"op": "<", "args": [{"property": "cloud"}, 10]}
+			]}`,
+			expected: `{"op": "<", "args": [{"property": "cloud"}, 10]}`,
+		},
+		{
+			name:     "properties object without hidden properties",
+			policy:   &filter.Policy{},
+			client:   `{"op": "isNull", "args": [{"property": "properties"}]}`,
+			expected: `{"op": "isNull", "args": [{"property": "properties"}]}`,
+		},
+		{
+			name:   "reject hidden property in function args",
+			policy: &filter.Policy{Hidden: []string{"tenant"}},
+			client: `{"op": "=", "args": [{"op": "upper", "args": [{"property": "tenant"}]}, "ACME"]}`,
+			err:    `reference to hidden property "tenant"`,
+		},
+		{
+			name:   "reject hidden property in interval bounds",
+			policy: &filter.Policy{Hidden: []string{"created"}},
+			client: `{"op": "t_during", "args": [{"property": "datetime"}, {"interval": [{"property": "created"}, ".."]}]}`,
+			err:    `reference to hidden property "created"`,
+		},
+		{
+			name:   "reject pointer to hidden dotted member",
+			policy: &filter.Policy{Hidden: []string{"eo:cloud.cover"}},
+			client: `{"op": "<", "args": [{"property": "/eo:cloud.cover"}, 10]}`,
+			err:    `reference to hidden property "/eo:cloud.cover"`,
+		},
+		{
+			name:   "reject pointer to hidden dotted member in properties",
+			policy: &filter.Policy{Hidden: []string{"eo:cloud.cover"}},
+			client: `{"op": "<", "args": [{"property": "/properties/eo:cloud.cover"}, 10]}`,
+			err:    `reference to hidden property "/properties/eo:cloud.cover"`,
+		},
+		{
+			name:   "reject dotted path to hidden pointer member",
+			policy: &filter.Policy{Hidden: []string{"/a.b"}},
+			client: `{"op": "=", "args": [{"property": "a.b"}, 1]}`,
+			err:    `reference to hidden property "a.b"`,
+		},
+		{
+			name:   "reject dotted path below hidden pointer member",
+			policy: &filter.Policy{Hidden: []string{"/a.b"}},
+			client: `{"op": "=", "args": [{"property": "properties.a.b.c"}, 1]}`,
+			err:    `reference to hidden property "properties.a.b.c"`,
+		},
+		{
+			name:     "dotted member with shared prefix is visible",
+			policy:   &filter.Policy{Hidden: []string{"eo:cloud.cover"}},
+			client:   `{"op": "<", "args": [{"property": "/eo:cloud.coverage"}, 10]}`,
+			expected: `{"op": "<", "args": [{"property": "/eo:cloud.coverage"}, 10]}`,
+		},
+		{
+			name:     "sibling property is visible",
+			policy:   &filter.Policy{Hidden: []string{"owner.email"}},
+			client:   `{"op": "=", "args": [{"property": "owner.name"}, "a"]}`,
+			expected: `{"op": "=", "args": [{"property": "owner.name"}, "a"]}`,
+		},
+		{
+			name:   "strip hidden property",
+			policy: &filter.Policy{Mandatory: mustParseFilter(t, tenant), Hidden: []string{"tenant"}, Strip: true},
+			client: `{"op": "and", "args": [
+				{"op": "<", "args": [{"property": "cloud"}, 10]},
+				{"op": "=", "args": [{"property": "tenant"}, "other"]}
+			]}`,
+			expected: `{"op": "and", "args": [` + tenant + `, {"op": "<", "args": [{"property": "cloud"}, 10]}]}`,
+		},
+		{
+			name:   "strip inside or and not",
+			policy: &filter.Policy{Hidden: []string{"secret"}, Strip: true},
+			client: `{"op": "or", "args": [
+				{"op": "not", "args": [{"op": "isNull", "args": [{"property": "secret"}]}]},
+				{"op": "=", "args": [{"op": "lower", "args": [{"property": "secret"}]}, "x"]},
+				{"op": "<", "args": [{"property": "cloud"}, 10]},
+				{"op": "=", "args": [{"property": "platform"}, "landsat-8"]}
+			]}`,
+			expected: `{"op": "or", "args": [
+				{"op": "<", "args": [{"property": "cloud"}, 10]},
+				{"op": "=", "args": [{"property": "platform"}, "landsat-8"]}
+			]}`,
+		},
+		{
+			name:     "strip whole client filter",
+			policy:   &filter.Policy{Mandatory: mustParseFilter(t, tenant), Hidden: []string{"tenant"}, Strip: true},
+			client:   `{"op": "<>", "args": [{"property": "tenant"}, "acme"]}`,
+			expected: tenant,
+		},
+		{
+			name:   "invalid property path",
+			policy: &filter.Policy{Hidden: []string{"tenant"}, Strip: true},
+			client: `{"op": "isNull", "args": [{"property": "a..b"}]}`,
+			err:    `trouble parsing property: empty segment in property path "a..b"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var client *filter.Filter
+			if c.client != "" {
+				client = mustParseFilter(t, c.client)
+			}
+
+			result, err := c.policy.Apply(client)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				if strings.HasPrefix(c.err, "reference to hidden property") {
+					assert.ErrorIs(t, err, filter.ErrHiddenProperty)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, c.expected, result.String())
+		})
+	}
+}
+
+func TestPolicyApplyNothing(t *testing.T) {
+	policy := &filter.Policy{Hidden: []string{"secret"}, Strip: true}
+
+	result, err := policy.Apply(mustParseFilter(t, `{"op": "isNull", "args": [{"property": "secret"}]}`))
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
@@ -14,7 +14,13 @@
 
 package filter
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 type Property struct {
 	Name string
@@ -47,3 +53,120 @@ func (e *Property) MarshalJSON() ([]byte, error) {
 func (e *Property) String() string {
 	return toString(e)
 }
+
+// PropertyPath is a parsed property reference.  Each segment is a member name or an array index.
+type PropertyPath []string
+
+// Path parses the property name.  See ParsePropertyPath for details.
+func (e *Property) Path() (PropertyPath, error) {
+	return ParsePropertyPath(e.Name)
+}
+
+// ParsePropertyPath parses a property name into path segments.  Names that start with a "/"
+// are parsed as JSON pointers (e.g. "/properties/eo:bands/0/name").  Other names are split
+// on "." (e.g. "properties.eo:bands.name").  Member names that contain "." can still be
+// resolved (see Resolve).
+func ParsePropertyPath(name string) (PropertyPath, error) {
+	if name == "" {
+		return nil, errors.New("empty property name")
+	}
+
+	if !strings.HasPrefix(name, "/") {
+		segments := strings.Split(name, ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return nil, fmt.Errorf("empty segment in property path %q", name)
+			}
+		}
+		return segments, nil
+	}
+
+	segments := strings.Split(name[1:], "/")
+	for i, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("empty segment in property path %q", name)
+		}
+		for j := 0; j < len(segment); j++ {
+			if segment[j] == '~' && (j+1 >= len(segment) || (segment[j+1] != '0' && segment[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid escape in property path %q", name)
+			}
+		}
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments, nil
+}
+
+// Pointer returns the path as a JSON pointer.
+func (p PropertyPath) Pointer() string {
+	builder := &strings.Builder{}
+	for _, segment := range p {
+		builder.WriteString("/")
+		builder.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return builder.String()
+}
+
+// Resolve looks up the value at the path in generic JSON data (as produced by json.Unmarshal).
+//
+// A numeric segment selects an item from an array.  Any other segment applied to an array
+// is applied to each item, and the values found are collected into a new array.  For example,
+// the path "eo:bands.name" resolves to the names of all bands.  If the segments do not
+// resolve to a value, consecutive segments are joined with "." and used as a single member
+// name, so "eo:cloud.cover" still resolves a member with that literal name.  The boolean
+// result is false if no value is found.
+func (p PropertyPath) Resolve(value any) (any, bool) {
+	if len(p) == 0 {
+		return value, true
+	}
+
+	segment := p[0]
+	switch v := value.(type) {
+	case map[string]any:
+		if child, ok := v[segment]; ok {
+			if result, found := p[1:].Resolve(child); found {
+				return result, true
+			}
+		}
+		// member names may contain "." (e.g. "eo:cloud.cover")
+		for end := 2; end <= len(p); end++ {
+			child, ok := v[strings.Join(p[:end], ".")]
+			if !ok {
+				continue
+			}
+			if result, found := p[end:].Resolve(child); found {
+				return result, true
+			}
+		}
+		return nil, false
+	case []any:
+		if index, err := strconv.Atoi(segment); err == nil {
+			if index < 0 || index >= len(v) {
+				return nil, false
+			}
+			return p[1:].Resolve(v[index])
+		}
+		results := []any{}
+		for _, item := range v {
+			result, ok := p.Resolve(item)
+			if !ok {
+				continue
+			}
+			if items, ok := result.([]any); ok {
+				results = append(results, items...)
+			} else {
+				results = append(results, result)
+			}
+		}
+		if len(results) == 0 {
+			return nil, false
+		}
+		return results, true
+	}
+	return nil, false
+}
+
+// PropertyResolver is implemented by values that provide data for property references.
+type PropertyResolver interface {
+	// ResolveProperty returns the value at the path.  The boolean result is false if there is no value.
+	ResolveProperty(path PropertyPath) (any, bool)
+}
@@ -19,6 +19,8 @@ import (
 	"testing"
 
 	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProperty(t *testing.T) {
@@ -44,3 +46,96 @@ func TestProperty(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePropertyPath(t *testing.T) {
+	cases := []struct {
+		name string
+		path filter.PropertyPath
+		err  string
+	}{
+		{name: "eo:cloud_cover", path: filter.PropertyPath{"eo:cloud_cover"}},
+		{name: "properties.eo:bands.name", path: filter.PropertyPath{"properties", "eo:bands", "name"}},
+		{name: "eo:bands.0.name", path: filter.PropertyPath{"eo:bands", "0", "name"}},
+		{name: "/properties/eo:bands/0/name", path: filter.PropertyPath{"properties", "eo:bands", "0", "name"}},
+		{name: "/a.b/c~1d/e~0f", path: filter.PropertyPath{"a.b", "c/d", "e~f"}},
+		{name: "", err: "empty property name"},
+		{name: "a..b", err: `empty segment in property path "a..b"`},
+		{name: "a.", err: `empty segment in property path "a."`},
+		{name: "/", err: `empty segment in property path "/"`},
+		{name: "/a~2", err: `invalid escape in property path "/a~2"`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path, err := (&filter.Property{c.name}).Path()
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.path, path)
+		})
+	}
+}
+
+func TestPropertyPathPointer(t *testing.T) {
+	path := filter.PropertyPath{"a.b", "c/d", "e~f"}
+	assert.Equal(t, "/a.b/c~1d/e~0f", path.Pointer())
+
+	parsed, err := filter.ParsePropertyPath(path.Pointer())
+	require.NoError(t, err)
+	assert.Equal(t, path, parsed)
+}
+
+func TestPropertyPathResolve(t *testing.T) {
+	data := map[string]any{
+		"platform": "sentinel-2a",
+		"view": map[string]any{
+			"sun_elevation": 42.0,
+		},
+		"eo:bands": []any{
+			map[string]any{"name": "B1", "common_name": "coastal"},
+			map[string]any{"name": "B2", "common_name": "blue"},
+			map[string]any{"name": "B10"},
+		},
+		"tiles": []any{
+			map[string]any{"ids": []any{"a", "b"}},
+			map[string]any{"ids": []any{"c"}},
+		},
+		"eo:cloud.cover": 12.5,
+		"a.b":            map[string]any{"c": "flat"},
+		"a":              map[string]any{"b": map[string]any{"d": "nested"}},
+	}
+
+	cases := []struct {
+		path  string
+		value any
+		found bool
+	}{
+		{path: "platform", value: "sentinel-2a", found: true},
+		{path: "view.sun_elevation", value: 42.0, found: true},
+		{path: "/view/sun_elevation", value: 42.0, found: true},
+		{path: "eo:bands.1.name", value: "B2", found: true},
+		{path: "eo:bands.name", value: []any{"B1", "B2", "B10"}, found: true},
+		{path: "eo:bands.common_name", value: []any{"coastal", "blue"}, found: true},
+		{path: "tiles.ids", value: []any{"a", "b", "c"}, found: true},
+		{path: "eo:cloud.cover", value: 12.5, found: true},
+		{path: "a.b.c", value: "flat", found: true},
+		{path: "a.b.d", value: "nested", found: true},
+		{path: "eo:bands.3.name"},
+		{path: "eo:bands.missing"},
+		{path: "platform.name"},
+		{path: "missing"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			path, err := filter.ParsePropertyPath(c.path)
+			require.NoError(t, err)
+
+			value, found := path.Resolve(data)
+			assert.Equal(t, c.found, found)
+			assert.Equal(t, c.value, value)
+		})
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// STAC query operators.
+const (
+	queryEquals              = "eq"
+	queryNotEquals           = "neq"
+	queryLessThan            = "lt"
+	queryLessThanOrEquals    = "lte"
+	queryGreaterThan         = "gt"
+	queryGreaterThanOrEquals = "gte"
+	queryStartsWith          = "startsWith"
+	queryEndsWith            = "endsWith"
+	queryContains            = "contains"
+	queryIn                  = "in"
+)
+
+// queryOperators lists the operators in the order they are converted.
+var queryOperators = []string{
+	queryEquals,
+	queryNotEquals,
+	queryLessThan,
+	queryLessThanOrEquals,
+	queryGreaterThan,
+	queryGreaterThanOrEquals,
+	queryStartsWith,
+	queryEndsWith,
+	queryContains,
+	queryIn,
+}
+
+var queryComparisons = map[string]string{
+	queryEquals:              Equals,
+	queryNotEquals:           NotEquals,
+	queryLessThan:            LessThan,
+	queryLessThanOrEquals:    LessThanOrEquals,
+	queryGreaterThan:         GreaterThan,
+	queryGreaterThanOrEquals: GreaterThanOrEquals,
+}
+
+// ParseSTACQuery converts a JSON encoded STAC API query extension object (e.g.
+// {"eo:cloud_cover": {"lt": 10}}) into a filter.  See DecodeSTACQuery for details.
+func ParseSTACQuery(data []byte) (*Filter, error) {
+	query := map[string]any{}
+	if err := json.Unmarshal(data, &query); err != nil {
+		return nil, fmt.Errorf("trouble parsing query: %w", err)
+	}
+	return DecodeSTACQuery(query)
+}
+
+// DecodeSTACQuery converts a generic STAC API query extension object (as produced by
+// json.Unmarshal) into a filter.  The object keys are property names and the values are
+// objects with one or more of the eq, neq, lt, lte, gt, gte, startsWith, endsWith, contains,
+// and in operators.  All of the conditions are joined with "and".  The startsWith, endsWith,
+// and contains operators are converted to "like" predicates, and an eq or neq with a null
+// value is converted to an "isNull" predicate.  The result is nil if the query has no
+// conditions.
+func DecodeSTACQuery(query map[string]any) (*Filter, error) {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []BooleanExpression{}
+	for _, name := range names {
+		operators, ok := query[name].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an object of operators for property %q", name)
+		}
+		for op := range operators {
+			if !slices.Contains(queryOperators, op) {
+				return nil, fmt.Errorf("unsupported operator %q for property %q", op, name)
+			}
+		}
+
+		property := &Property{Name: name}
+		for _, op := range queryOperators {
+			value, ok := operators[op]
+			if !ok {
+				continue
+			}
+			arg, err := decodeQueryCondition(property, op, value)
+			if err != nil {
+				return nil, fmt.Errorf("trouble decoding %q operator for property %q: %w", op, name, err)
+			}
+			args = append(args, arg)
+		}
+	}
+
+	switch len(args) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &Filter{Expression: args[0]}, nil
+	}
+	return &Filter{Expression: &And{Args: args}}, nil
+}
+
+func decodeQueryValue(value any) (ScalarExpression, error) {
+	switch v := value.(type) {
+	case string:
+		return &String{Value: v}, nil
+	case float64:
+		return &Number{Value: v}, nil
+	case bool:
+		return &Boolean{Value: v}, nil
+	}
+	return nil, fmt.Errorf("expected a string, number, or boolean, got %v", value)
+}
+
+func decodeQueryCondition(property *Property, op string, value any) (BooleanExpression, error) {
+	if name, ok := queryComparisons[op]; ok {
+		if value == nil && (op == queryEquals || op == queryNotEquals) {
+			if op == queryEquals {
+				return &IsNull{Value: property}, nil
+			}
+			return &Not{Arg: &IsNull{Value: property}}, nil
+		}
+		scalar, err := decodeQueryValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return &Comparison{Name: name, Left: property, Right: scalar}, nil
+	}
+
+	if op == queryIn {
+		values, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected an array, got %v", value)
+		}
+		list := make(ScalarList, len(values))
+		for i, v := range values {
+			scalar, err := decodeQueryValue(v)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = scalar
+		}
+		return &In{Item: property, List: list}, nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a string, got %v", value)
+	}
+	pattern := escapeLikePattern(str)
+	switch op {
+	case queryStartsWith:
+		pattern = pattern + "%"
+	case queryEndsWith:
+		pattern = "%" + pattern
+	case queryContains:
+		pattern = "%" + pattern + "%"
+	}
+	return &Like{Value: property, Pattern: &String{Value: pattern}}, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLikePattern escapes the wildcard and escape characters in a like pattern.
+func escapeLikePattern(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// splitLikePattern splits a like pattern into its literal value and whether it has a leading
+// or trailing multi-character wildcard.  The result is not ok if the pattern has any other
+// wildcards.
+func splitLikePattern(pattern string) (literal string, leading bool, trailing bool, ok bool) {
+	tokens := parseLikePattern(pattern, cql2LikeSyntax)
+	builder := &strings.Builder{}
+	for i, token := range tokens {
+		switch {
+		case token.kind == likeLiteral:
+			builder.WriteRune(token.char)
+		case token.kind == likeAny && i == 0:
+			leading = true
+		case token.kind == likeAny && i == len(tokens)-1:
+			trailing = true
+		default:
+			return "", false, false, false
+		}
+	}
+	return builder.String(), leading, trailing, true
+}
+
+// EncodeSTACQuery converts a filter into a STAC API query extension object.  This is the
+// reverse of DecodeSTACQuery, and an error is returned if the expression cannot be expressed
+// as a query (e.g. if it includes "or" or has more than one condition with the same operator
+// for a property).
+func EncodeSTACQuery(expr BooleanExpression) (map[string]any, error) {
+	query := map[string]any{}
+	if err := encodeQueryConditions(query, expr); err != nil {
+		return nil, fmt.Errorf("trouble encoding query: %w", err)
+	}
+	return query, nil
+}
+
+func encodeQueryConditions(query map[string]any, expr BooleanExpression) error {
+	switch e := expr.(type) {
+	case *Filter:
+		return encodeQueryConditions(query, e.Expression)
+	case *And:
+		for _, arg := range e.Args {
+			if err := encodeQueryConditions(query, arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	name, op, value, ok := encodeQueryCondition(expr)
+	if !ok {
+		return fmt.Errorf("cannot express %s as a query", describe(expr))
+	}
+	operators, ok := query[name].(map[string]any)
+	if !ok {
+		operators = map[string]any{}
+		query[name] = operators
+	}
+	if _, exists := operators[op]; exists {
+		return fmt.Errorf("more than one %q condition for property %q", op, name)
+	}
+	operators[op] = value
+	return nil
+}
+
+func encodeQueryValue(expr Expression) (any, bool) {
+	switch e := expr.(type) {
+	case *String:
+		return e.Value, true
+	case *Number:
+		return e.Value, true
+	case *Boolean:
+		return e.Value, true
+	}
+	return nil, false
+}
+
+// encodeQueryCondition returns the property name, operator, and value for a query condition.
+func encodeQueryCondition(expr BooleanExpression) (string, string, any, bool) {
+	switch e := expr.(type) {
+	case *Comparison:
+		name, left, right := e.Name, Expression(e.Left), Expression(e.Right)
+		if _, ok := right.(*Property); ok {
+			name, left, right = comparisonConverse[name], right, left
+		}
+		property, ok := left.(*Property)
+		if !ok {
+			return "", "", nil, false
+		}
+		value, ok := encodeQueryValue(right)
+		if !ok {
+			return "", "", nil, false
+		}
+		for op, comparison := range queryComparisons {
+			if comparison == name {
+				return property.Name, op, value, true
+			}
+		}
+
+	case *Like:
+		property, ok := e.Value.(*Property)
+		if !ok {
+			return "", "", nil, false
+		}
+		pattern, ok := e.Pattern.(*String)
+		if !ok {
+			return "", "", nil, false
+		}
+		literal, leading, trailing, ok := splitLikePattern(pattern.Value)
+		if !ok {
+			return "", "", nil, false
+		}
+		op := queryEquals
+		switch {
+		case leading && trailing:
+			op = queryContains
+		case leading:
+			op = queryEndsWith
+		case trailing:
+			op = queryStartsWith
+		}
+		return property.Name, op, literal, true
+
+	case *In:
+		property, ok := e.Item.(*Property)
+		if !ok {
+			return "", "", nil, false
+		}
+		values := make([]any, len(e.List))
+		for i, item := range e.List {
+			value, ok := encodeQueryValue(item)
+			if !ok {
+				return "", "", nil, false
+			}
+			values[i] = value
+		}
+		return property.Name, queryIn, values, true
+
+	case *IsNull:
+		if property, ok := e.Value.(*Property); ok {
+			return property.Name, queryEquals, nil, true
+		}
+
+	case *Not:
+		if isNull, ok := e.Arg.(*IsNull); ok {
+			if property, ok := isNull.Value.(*Property); ok {
+				return property.Name, queryNotEquals, nil, true
+			}
+		}
+	}
+	return "", "", nil, false
+}
+
+// describe returns compact JSON for an expression, for use in error messages.
+func describe(e Expression) string {
+	v, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Sprintf("%#v", e)
+	}
+	buffer := &bytes.Buffer{}
+	if err := json.Compact(buffer, v); err != nil {
+		return string(v)
+	}
+	return buffer.String()
+}
@@ -0,0 +1,216 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSTACQuery(t *testing.T) {
+	cases := []struct {
+		name   string
+		query  string
+		filter string
+	}{
+		{
+			name:   "single condition",
+			query:  `{"eo:cloud_cover": {"lt": 10}}`,
+			filter: `{"op": "<", "args": [{"property": "eo:cloud_cover"}, 10]}`,
+		},
+		{
+			name:  "multiple conditions",
+			query: `{"platform": {"in": ["a", "b"]}, "eo:cloud_cover": {"gte": 5, "lte": 10}, "flag": {"neq": true}}`,
+			filter: `{"op": "and", "args": [
+				{"op": "<=", "args": [{"property": "eo:cloud_cover"}, 10]},
+				{"op": ">=", "args": [{"property": "eo:cloud_cover"}, 5]},
+				{"op": "<>", "args": [{"property": "flag"}, true]},
+				{"op": "in", "args": [{"property": "platform"}, ["a", "b"]]}
+			]}`,
+		},
+		{
+			name:  "string operators",
+			query: `{"a": {"startsWith": "x_"}, "b": {"endsWith": "50%"}, "c": {"contains": "\\"}}`,
+			filter: `{"op": "and", "args": [
+				{"op": "like", "args": [{"property": "a"}, "x\\_%"]},
+				{"op": "like", "args": [{"property": "b"}, "%50\\%"]},
+				{"op": "like", "args": [{"property": "c"}, "%\\\\%"]}
+			]}`,
+		},
+		{
+			name:  "null values",
+			query: `{"a": {"eq": null}, "b": {"neq": null}}`,
+			filter: `{"op": "and", "args": [
+				{"op": "isNull", "args": [{"property": "a"}]},
+				{"op": "not", "args": [{"op": "isNull", "args": [{"property": "b"}]}]}
+			]}`,
+		},
+		{
+			name:   "datetime string",
+			query:  `{"created": {"gt": "2020-01-01T00:00:00Z"}}`,
+			filter: `{"op": ">", "args": [{"property": "created"}, "2020-01-01T00:00:00Z"]}`,
+		},
+	}
+
+	schema := getSchema(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := filter.ParseSTACQuery([]byte(c.query))
+			require.NoError(t, err)
+			assert.JSONEq(t, c.filter, f.String())
+
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(f.String()), &v))
+			assert.NoError(t, schema.Validate(v))
+		})
+	}
+}
+
+func TestParseSTACQueryEmpty(t *testing.T) {
+	f, err := filter.ParseSTACQuery([]byte(`{}`))
+	require.NoError(t, err)
+	assert.Nil(t, f)
+}
+
+func TestParseSTACQueryErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		err   string
+	}{
+		{
+			query: `[]`,
+			err:   "trouble parsing query: json: cannot unmarshal array into Go value of type map[string]interface {}",
+		},
+		{
+			query: `{"a": 10}`,
+			err:   `expected an object of operators for property "a"`,
+		},
+		{
+			query: `{"a": {"like": "x"}}`,
+			err:   `unsupported operator "like" for property "a"`,
+		},
+		{
+			query: `{"a": {"lt": [1]}}`,
+			err:   `trouble decoding "lt" operator for property "a": expected a string, number, or boolean, got [1]`,
+		},
+		{
+			query: `{"a": {"in": "x"}}`,
+			err:   `trouble decoding "in" operator for property "a": expected an array, got x`,
+		},
+		{
+			query: `{"a": {"startsWith": 1}}`,
+			err:   `trouble decoding "startsWith" operator for property "a": expected a string, got 1`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			_, err := filter.ParseSTACQuery([]byte(c.query))
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestEncodeSTACQuery(t *testing.T) {
+	cases := []struct {
+		name   string
+		filter string
+		query  string
+	}{
+		{
+			name: "conditions",
+			filter: `{"op": "and", "args": [
+				{"op": "<=", "args": [{"property": "eo:cloud_cover"}, 10]},
+				{"op": "and", "args": [
+					{"op": ">", "args": [5, {"property": "eo:cloud_cover"}]},
+					{"op": "in", "args": [{"property": "platform"}, ["a", "b"]]}
+				]},
+				{"op": "isNull", "args": [{"property": "c"}]}
+			]}`,
+			query: `{"eo:cloud_cover": {"lte": 10, "lt": 5}, "platform": {"in": ["a", "b"]}, "c": {"eq": null}}`,
+		},
+		{
+			name: "like patterns",
+			filter: `{"op": "and", "args": [
+				{"op": "like", "args": [{"property": "a"}, "x\\_%"]},
+				{"op": "like", "args": [{"property": "b"}, "%50\\%"]},
+				{"op": "like", "args": [{"property": "c"}, "%\\\\%"]},
+				{"op": "like", "args": [{"property": "d"}, "exact"]}
+			]}`,
+			query: `{"a": {"startsWith": "x_"}, "b": {"endsWith": "50%"}, "c": {"contains": "\\"}, "d": {"eq": "exact"}}`,
+		},
+		{
+			name:   "like pattern with trailing escape",
+			filter: `{"op": "like", "args": [{"property": "a"}, "abc\\"]}`,
+			query:  `{"a": {"eq": "abc\\"}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			query, err := filter.EncodeSTACQuery(mustParseFilter(t, c.filter))
+			require.NoError(t, err)
+
+			data, err := json.Marshal(query)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.query, string(data))
+		})
+	}
+}
+
+func TestEncodeSTACQueryErrors(t *testing.T) {
+	cases := []struct {
+		filter string
+		err    string
+	}{
+		{
+			filter: `{"op": "or", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "=", "args": [{"property": "b"}, 1]}]}`,
+			err:    `trouble encoding query: cannot express {"op":"or","args":[{"op":"=","args":[{"property":"a"},1]},{"op":"=","args":[{"property":"b"},1]}]} as a query`,
+		},
+		{
+			filter: `{"op": "like", "args": [{"property": "a"}, "a%b"]}`,
+			err:    `trouble encoding query: cannot express {"op":"like","args":[{"property":"a"},"a%b"]} as a query`,
+		},
+		{
+			filter: `{"op": "and", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "=", "args": [{"property": "a"}, 2]}]}`,
+			err:    `trouble encoding query: more than one "eq" condition for property "a"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			_, err := filter.EncodeSTACQuery(mustParseFilter(t, c.filter))
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestSTACQueryRoundTrip(t *testing.T) {
+	query := `{"eo:cloud_cover": {"gte": 5, "lt": 10}, "platform": {"in": ["a", "b"], "neq": "c"}, "id": {"contains": "50%_off"}}`
+
+	f, err := filter.ParseSTACQuery([]byte(query))
+	require.NoError(t, err)
+
+	encoded, err := filter.EncodeSTACQuery(f)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(encoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, query, string(data))
+}
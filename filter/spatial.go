@@ -16,8 +16,9 @@ package filter
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+
+	"github.com/planetlabs/go-ogc/geometry"
 )
 
 const (
@@ -62,7 +63,7 @@ type SpatialExpression interface {
 }
 
 type Geometry struct {
-	Value any
+	Value geometry.Geometry
 }
 
 var (
@@ -84,37 +85,13 @@ func (e *Geometry) String() string {
 	return toString(e)
 }
 
-var geometryTypes = map[string]bool{
-	"Point":              true,
-	"LineString":         true,
-	"Polygon":            true,
-	"MultiPoint":         true,
-	"MultiLineString":    true,
-	"MultiPolygon":       true,
-	"GeometryCollection": true,
-}
-
 func decodeGeometry(value map[string]any) (*Geometry, error) {
-	geomType, ok := value["type"].(string)
-	if !ok {
-		return nil, errors.New("geometry missing type")
-	}
-
-	if !geometryTypes[geomType] {
-		return nil, fmt.Errorf("unexpected geometry type: %s", geomType)
-	}
-
-	if geomType == "GeometryCollection" {
-		if _, ok := value["geometries"].([]any); !ok {
-			return nil, errors.New("expected geometries array in geometry collection")
-		}
-	} else {
-		if _, ok := value["coordinates"].([]any); !ok {
-			return nil, errors.New("expected coordinates in geometry")
-		}
+	g, err := geometry.Decode(value)
+	if err != nil {
+		return nil, fmt.Errorf("trouble decoding geometry: %w", err)
 	}
 
-	return &Geometry{Value: value}, nil
+	return &Geometry{Value: g}, nil
 }
 
 type BoundingBox struct {
@@ -15,10 +15,14 @@
 package filter_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/planetlabs/go-ogc/filter"
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSpatial(t *testing.T) {
@@ -151,6 +155,51 @@ func TestSpatial(t *testing.T) {
 				]
 			}`,
 		},
+		{
+			filter: &filter.Filter{
+				Expression: &filter.SpatialComparison{
+					Name: filter.GeometryIntersects,
+					Left: &filter.Property{"geometry"},
+					Right: &filter.Geometry{&geometry.Polygon{
+						Coordinates: [][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}},
+					}},
+				},
+			},
+			data: `{
+				"op": "s_intersects",
+				"args": [
+					{"property": "geometry"},
+					{"type": "Polygon", "coordinates": [[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]]]}
+				]
+			}`,
+		},
+		{
+			filter: &filter.Filter{
+				Expression: &filter.SpatialComparison{
+					Name: filter.GeometryWithin,
+					Left: &filter.Property{"geometry"},
+					Right: &filter.Geometry{&geometry.GeometryCollection{
+						Geometries: []geometry.Geometry{
+							&geometry.Point{Coordinates: []float64{1, 2}},
+							&geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+						},
+					}},
+				},
+			},
+			data: `{
+				"op": "s_within",
+				"args": [
+					{"property": "geometry"},
+					{
+						"type": "GeometryCollection",
+						"geometries": [
+							{"type": "Point", "coordinates": [1, 2]},
+							{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}
+						]
+					}
+				]
+			}`,
+		},
 	}
 
 	for i, c := range cases {
@@ -159,3 +208,18 @@ func TestSpatial(t *testing.T) {
 		})
 	}
 }
+
+func TestSpatialInvalidGeometry(t *testing.T) {
+	data := `{
+		"op": "s_intersects",
+		"args": [
+			{"property": "geometry"},
+			{"type": "Polygon", "coordinates": [[[0, 0], [10, 0], [10, 10], [0, 10]]]}
+		]
+	}`
+
+	f := &filter.Filter{}
+	err := json.Unmarshal([]byte(data), f)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ring is not closed")
+}
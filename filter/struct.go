@@ -0,0 +1,193 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/planetlabs/go-ogc/geometry"
+)
+
+// StructResolver resolves properties from the fields of a struct.
+//
+// Fields are named with a "cql" tag (e.g. `cql:"eo:cloud_cover"`), and fields without a tag
+// use the field name.  Fields tagged with `cql:"-"` and unexported fields are ignored.  The
+// fields of embedded structs are promoted unless the embedded field has a tag.  Path segments
+// after the first select fields of nested structs, values from maps with string keys, and items
+// from slices and arrays (see PropertyPath.Resolve for how segments are applied to arrays).
+// Nil pointers and interfaces are treated as missing values.
+type StructResolver struct {
+	value reflect.Value
+}
+
+var _ PropertyResolver = (*StructResolver)(nil)
+
+// NewStructResolver creates a resolver for a struct or a pointer to a struct.
+func NewStructResolver(value any) (*StructResolver, error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, fmt.Errorf("expected a struct, got a nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %T", value)
+	}
+	return &StructResolver{value: v}, nil
+}
+
+// ResolveProperty returns the value of the field at the path.
+func (r *StructResolver) ResolveProperty(path PropertyPath) (any, bool) {
+	if len(path) == 0 {
+		return nil, false
+	}
+	return resolveReflected(r.value, path)
+}
+
+// structFieldsCache maps struct types to their fields by property name.
+var structFieldsCache sync.Map
+
+var (
+	timeType             = reflect.TypeFor[time.Time]()
+	geometryType         = reflect.TypeFor[geometry.Geometry]()
+	geometryProviderType = reflect.TypeFor[GeometryProvider]()
+)
+
+func structFields(t reflect.Type) map[string][]int {
+	if fields, ok := structFieldsCache.Load(t); ok {
+		return fields.(map[string][]int)
+	}
+
+	fields := map[string][]int{}
+	depths := map[string]int{}
+	addStructFields(t, nil, fields, depths)
+
+	actual, _ := structFieldsCache.LoadOrStore(t, fields)
+	return actual.(map[string][]int)
+}
+
+func addStructFields(t reflect.Type, index []int, fields map[string][]int, depths map[string]int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("cql")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Anonymous && !hasTag {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, fieldIndex, fields, depths)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		// fields at a shallower depth take precedence over promoted fields
+		if depth, exists := depths[name]; exists && depth <= len(index) {
+			continue
+		}
+		fields[name] = fieldIndex
+		depths[name] = len(index)
+	}
+}
+
+// isLeaf reports whether a value should not be traversed by a path.
+func isLeaf(t reflect.Type) bool {
+	return t == timeType || t.Implements(geometryType) || t.Implements(geometryProviderType)
+}
+
+func resolveReflected(v reflect.Value, path PropertyPath) (any, bool) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		if len(path) == 0 || isLeaf(v.Type()) {
+			break
+		}
+		v = v.Elem()
+	}
+	if len(path) == 0 {
+		return v.Interface(), true
+	}
+	if isLeaf(v.Type()) {
+		return nil, false
+	}
+
+	segment := path[0]
+	switch v.Kind() {
+	case reflect.Struct:
+		index, ok := structFields(v.Type())[segment]
+		if !ok {
+			return nil, false
+		}
+		field, err := v.FieldByIndexErr(index)
+		if err != nil {
+			return nil, false
+		}
+		return resolveReflected(field, path[1:])
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		item := v.MapIndex(reflect.ValueOf(segment).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return nil, false
+		}
+		return resolveReflected(item, path[1:])
+
+	case reflect.Slice, reflect.Array:
+		if index, err := strconv.Atoi(segment); err == nil {
+			if index < 0 || index >= v.Len() {
+				return nil, false
+			}
+			return resolveReflected(v.Index(index), path[1:])
+		}
+		results := []any{}
+		for i := 0; i < v.Len(); i++ {
+			result, ok := resolveReflected(v.Index(i), path)
+			if !ok {
+				continue
+			}
+			if items, ok := normalizeValue(result).([]any); ok {
+				results = append(results, items...)
+			} else {
+				results = append(results, result)
+			}
+		}
+		if len(results) == 0 {
+			return nil, false
+		}
+		return results, true
+	}
+	return nil, false
+}
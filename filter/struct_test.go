@@ -0,0 +1,137 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type location struct {
+	lon float64
+	lat float64
+}
+
+func (l location) Geometry() geometry.Geometry {
+	return &geometry.Point{Coordinates: []float64{l.lon, l.lat}}
+}
+
+type band struct {
+	Name       string  `cql:"name"`
+	Wavelength float32 `cql:"center_wavelength"`
+}
+
+type common struct {
+	Platform string `cql:"platform"`
+}
+
+type item struct {
+	common
+	Id         string            `cql:"id"`
+	CloudCover int               `cql:"eo:cloud_cover"`
+	Datetime   time.Time         `cql:"datetime"`
+	Bands      []band            `cql:"eo:bands"`
+	Keywords   []string          `cql:"keywords"`
+	Footprint  geometry.Geometry `cql:"geometry"`
+	Location   location          `cql:"location"`
+	Extra      map[string]any    `cql:"extra"`
+	Parent     *item             `cql:"parent"`
+	Secret     string            `cql:"-"`
+	Untagged   uint8
+	internal   string
+}
+
+func TestStructResolver(t *testing.T) {
+	value := &item{
+		common:     common{Platform: "sentinel-2a"},
+		Id:         "abc",
+		CloudCover: 12,
+		Datetime:   time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC),
+		Bands:      []band{{Name: "red", Wavelength: 0.66}, {Name: "nir", Wavelength: 0.84}},
+		Keywords:   []string{"a", "b"},
+		Footprint:  &geometry.Polygon{Coordinates: [][][]float64{{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}}},
+		Location:   location{lon: 5, lat: 5},
+		Extra:      map[string]any{"nested": map[string]any{"value": "x"}},
+		Secret:     "hidden",
+		Untagged:   7,
+		internal:   "internal",
+	}
+
+	cases := []struct {
+		filter   string
+		expected bool
+	}{
+		{`{"op": "=", "args": [{"property": "id"}, "abc"]}`, true},
+		{`{"op": "<", "args": [{"property": "eo:cloud_cover"}, 20]}`, true},
+		{`{"op": "between", "args": [{"property": "eo:cloud_cover"}, 13, 20]}`, false},
+		{`{"op": "=", "args": [{"property": "platform"}, "sentinel-2a"]}`, true},
+		{`{"op": "t_after", "args": [{"property": "datetime"}, {"date": "2020-06-14"}]}`, true},
+		{`{"op": "=", "args": [{"property": "datetime"}, {"timestamp": "2020-06-15T12:00:00Z"}]}`, true},
+		{`{"op": "a_contains", "args": [{"property": "keywords"}, ["b"]]}`, true},
+		{`{"op": "a_contains", "args": [{"property": "eo:bands.name"}, ["nir"]]}`, true},
+		{`{"op": "=", "args": [{"property": "eo:bands.1.name"}, "nir"]}`, true},
+		{`{"op": ">", "args": [{"property": "eo:bands.0.center_wavelength"}, 0.6]}`, true},
+		{`{"op": "s_contains", "args": [{"property": "geometry"}, {"property": "location"}]}`, true},
+		{`{"op": "s_intersects", "args": [{"property": "location"}, {"bbox": [20, 20, 30, 30]}]}`, false},
+		{`{"op": "=", "args": [{"property": "extra.nested.value"}, "x"]}`, true},
+		{`{"op": "isNull", "args": [{"property": "parent"}]}`, true},
+		{`{"op": "isNull", "args": [{"property": "parent.id"}]}`, true},
+		{`{"op": "isNull", "args": [{"property": "Secret"}]}`, true},
+		{`{"op": "isNull", "args": [{"property": "internal"}]}`, true},
+		{`{"op": "=", "args": [{"property": "Untagged"}, 7]}`, true},
+	}
+
+	resolver, err := filter.NewStructResolver(value)
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		t.Run(c.filter, func(t *testing.T) {
+			result, err := filter.Evaluate(mustParseFilter(t, c.filter), resolver)
+			require.NoError(t, err)
+			assert.Equal(t, c.expected, result)
+		})
+	}
+}
+
+func TestStructResolverNested(t *testing.T) {
+	value := item{Id: "child", Parent: &item{Id: "parent", CloudCover: 50}}
+
+	resolver, err := filter.NewStructResolver(value)
+	require.NoError(t, err)
+
+	id, ok := resolver.ResolveProperty(filter.PropertyPath{"parent", "id"})
+	require.True(t, ok)
+	assert.Equal(t, "parent", id)
+
+	cloudCover, ok := resolver.ResolveProperty(filter.PropertyPath{"parent", "eo:cloud_cover"})
+	require.True(t, ok)
+	assert.Equal(t, 50, cloudCover)
+
+	_, ok = resolver.ResolveProperty(filter.PropertyPath{"parent", "parent", "id"})
+	assert.False(t, ok)
+}
+
+func TestStructResolverErrors(t *testing.T) {
+	_, err := filter.NewStructResolver("not a struct")
+	assert.EqualError(t, err, "expected a struct, got string")
+
+	_, err = filter.NewStructResolver((*item)(nil))
+	assert.EqualError(t, err, "expected a struct, got a nil *filter_test.item")
+}
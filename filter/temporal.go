@@ -122,7 +122,7 @@ func (*Timestamp) scalarExpression()   {}
 func (*Timestamp) temporalExpression() {}
 
 func (e *Timestamp) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]string{"timestamp": e.Value.Format(time.RFC3339Nano)})
+	return json.Marshal(map[string]string{"timestamp": formatTimestamp(e.Value)})
 }
 
 func (e *Timestamp) String() string {
@@ -134,7 +134,12 @@ func decodeTimestamp(value string) (*Timestamp, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse timestamp: %w", err)
 	}
-	return &Timestamp{Value: timestamp}, nil
+	return &Timestamp{Value: timestamp.UTC()}, nil
+}
+
+// formatTimestamp formats a timestamp in UTC with fractional seconds if needed.
+func formatTimestamp(value time.Time) string {
+	return value.UTC().Format(time.RFC3339Nano)
 }
 
 func decodeDateOrTimestamp(value string) (InstantExpression, error) {
@@ -158,32 +163,58 @@ var (
 func (*Interval) expression()         {}
 func (*Interval) temporalExpression() {}
 
-func (e *Interval) MarshalJSON() ([]byte, error) {
-	items := make([]any, 2)
-	if e.Start == nil {
-		items[0] = ".."
-	} else {
-		switch t := e.Start.(type) {
-		case *Date:
-			items[0] = t.Value.Format(time.DateOnly)
-		case *Timestamp:
-			items[0] = t.Value
-		default:
-			items[0] = e.Start
-		}
+// NewInterval creates an interval.  A nil start or end means the interval is unbounded on that
+// side (".." in CQL2), but at least one must be provided.  If both are dates or timestamps, the
+// start must not be after the end.  A date end includes the whole day.
+func NewInterval(start InstantExpression, end InstantExpression) (*Interval, error) {
+	if start == nil && end == nil {
+		return nil, errors.New("interval start or end must be provided")
 	}
-	if e.End == nil {
-		items[1] = ".."
-	} else {
-		switch t := e.End.(type) {
-		case *Date:
-			items[1] = t.Value.Format(time.DateOnly)
-		case *Timestamp:
-			items[1] = t.Value
-		default:
-			items[1] = e.End
-		}
+
+	startTime, startOk := literalIntervalStart(start)
+	endTime, endOk := literalIntervalEnd(end)
+	if startOk && endOk && startTime.After(endTime) {
+		return nil, fmt.Errorf("interval start %s is after end %s", intervalItem(start), intervalItem(end))
+	}
+	return &Interval{Start: start, End: end}, nil
+}
+
+func literalIntervalStart(instant InstantExpression) (time.Time, bool) {
+	switch t := instant.(type) {
+	case *Date:
+		return t.Value, true
+	case *Timestamp:
+		return t.Value, true
+	}
+	return time.Time{}, false
+}
+
+func literalIntervalEnd(instant InstantExpression) (time.Time, bool) {
+	switch t := instant.(type) {
+	case *Date:
+		return t.Value.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+	case *Timestamp:
+		return t.Value, true
+	}
+	return time.Time{}, false
+}
+
+// intervalItem returns the CQL2 JSON value for an interval start or end.  Dates and
+// timestamps are encoded as strings and other expressions are encoded as objects.
+func intervalItem(instant InstantExpression) any {
+	switch t := instant.(type) {
+	case nil:
+		return nilInstant
+	case *Date:
+		return t.Value.Format(time.DateOnly)
+	case *Timestamp:
+		return formatTimestamp(t.Value)
 	}
+	return instant
+}
+
+func (e *Interval) MarshalJSON() ([]byte, error) {
+	items := []any{intervalItem(e.Start), intervalItem(e.End)}
 	return json.Marshal(map[string]any{"interval": items})
 }
 
@@ -198,53 +229,41 @@ func decodeInterval(values []any) (*Interval, error) {
 		return nil, fmt.Errorf("expected 2 items for interval, found %d", len(values))
 	}
 
-	var start InstantExpression
-	startValue, err := decodeExpression(values[0])
+	start, err := decodeIntervalItem(values[0], "start")
 	if err != nil {
-		return nil, fmt.Errorf("trouble parsing interval start: %w", err)
+		return nil, err
 	}
-	switch s := startValue.(type) {
-	case *String:
-		if s.Value != nilInstant {
-			value, err := decodeDateOrTimestamp(s.Value)
-			if err != nil {
-				return nil, fmt.Errorf("expected date or timestamp expression, got %s", s.Value)
-			}
-			start = value
-		}
-	case *Property:
-		start = s
-	case *Function:
-		start = s
-	default:
-		return nil, errors.New("unsupported start expression in interval")
+	end, err := decodeIntervalItem(values[1], "end")
+	if err != nil {
+		return nil, err
 	}
+	return NewInterval(start, end)
+}
 
-	var end InstantExpression
-	endValue, err := decodeExpression(values[1])
+// decodeIntervalItem decodes an interval start or end.  A nil instant is returned for "..".
+func decodeIntervalItem(value any, name string) (InstantExpression, error) {
+	expression, err := decodeExpression(value)
 	if err != nil {
-		return nil, fmt.Errorf("trouble parsing interval end: %w", err)
+		return nil, fmt.Errorf("trouble parsing interval %s: %w", name, err)
 	}
-	switch s := endValue.(type) {
+	switch e := expression.(type) {
 	case *String:
-		if s.Value != nilInstant {
-			value, err := decodeDateOrTimestamp(s.Value)
-			if err != nil {
-				return nil, fmt.Errorf("expected date or timestamp expression, got %s", s.Value)
-			}
-			end = value
+		if e.Value == nilInstant {
+			return nil, nil
+		}
+		instant, err := decodeDateOrTimestamp(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("expected date or timestamp expression, got %s", e.Value)
 		}
+		return instant, nil
+	case *Date:
+		return e, nil
+	case *Timestamp:
+		return e, nil
 	case *Property:
-		end = s
+		return e, nil
 	case *Function:
-		end = s
-	default:
-		return nil, errors.New("unsupported end expression in interval")
-	}
-
-	if start == nil && end == nil {
-		return nil, errors.New("interval start or end must be provided")
+		return e, nil
 	}
-
-	return &Interval{Start: start, End: end}, nil
+	return nil, fmt.Errorf("unsupported %s expression in interval", name)
 }
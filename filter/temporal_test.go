@@ -20,6 +20,8 @@ import (
 	"time"
 
 	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTemporal(t *testing.T) {
@@ -401,3 +403,115 @@ func TestTemporal(t *testing.T) {
 		})
 	}
 }
+
+func TestTemporalRoundTrip(t *testing.T) {
+	cases := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{
+			name:     "timestamp offset",
+			data:     `{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-06-01T12:00:00-07:00"}]}`,
+			expected: `{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-06-01T19:00:00Z"}]}`,
+		},
+		{
+			name:     "fractional seconds",
+			data:     `{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-06-01T00:30:00.125+01:00"}]}`,
+			expected: `{"op": "t_after", "args": [{"property": "t"}, {"timestamp": "2023-05-31T23:30:00.125Z"}]}`,
+		},
+		{
+			name:     "interval offsets",
+			data:     `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2020-01-01T02:00:00+02:00", "2020-01-01T12:00:00.5-05:00"]}]}`,
+			expected: `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2020-01-01T00:00:00Z", "2020-01-01T17:00:00.5Z"]}]}`,
+		},
+		{
+			name:     "open start",
+			data:     `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["..", "2020-01-01"]}]}`,
+			expected: `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["..", "2020-01-01"]}]}`,
+		},
+		{
+			name:     "property and function bounds",
+			data:     `{"op": "t_intersects", "args": [{"interval": [{"property": "start"}, {"op": "later", "args": [{"property": "end"}, "1 day"]}]}, {"interval": ["2020-01-01", ".."]}]}`,
+			expected: `{"op": "t_intersects", "args": [{"interval": [{"property": "start"}, {"op": "later", "args": [{"property": "end"}, "1 day"]}]}, {"interval": ["2020-01-01", ".."]}]}`,
+		},
+		{
+			name:     "typed bounds",
+			data:     `{"op": "t_during", "args": [{"property": "t"}, {"interval": [{"date": "2020-01-01"}, {"timestamp": "2020-01-02T01:00:00+01:00"}]}]}`,
+			expected: `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2020-01-01", "2020-01-02T00:00:00Z"]}]}`,
+		},
+		{
+			name:     "same day",
+			data:     `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2020-01-01T12:00:00Z", "2020-01-01"]}]}`,
+			expected: `{"op": "t_during", "args": [{"property": "t"}, {"interval": ["2020-01-01T12:00:00Z", "2020-01-01"]}]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := mustParseFilter(t, c.data)
+			assert.JSONEq(t, c.expected, f.String())
+			assert.JSONEq(t, c.expected, mustParseFilter(t, f.String()).String())
+		})
+	}
+}
+
+func TestIntervalErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "open",
+			data: `{"interval": ["..", ".."]}`,
+			err:  "interval start or end must be provided",
+		},
+		{
+			name: "reversed dates",
+			data: `{"interval": ["2020-01-02", "2020-01-01"]}`,
+			err:  "interval start 2020-01-02 is after end 2020-01-01",
+		},
+		{
+			name: "reversed timestamps",
+			data: `{"interval": ["2020-01-01T00:00:00-01:00", "2020-01-01T00:30:00Z"]}`,
+			err:  "interval start 2020-01-01T01:00:00Z is after end 2020-01-01T00:30:00Z",
+		},
+		{
+			name: "invalid start",
+			data: `{"interval": ["yesterday", ".."]}`,
+			err:  "expected date or timestamp expression, got yesterday",
+		},
+		{
+			name: "unsupported end",
+			data: `{"interval": ["2020-01-01", 42]}`,
+			err:  "unsupported end expression in interval",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := `{"op": "t_during", "args": [{"property": "t"}, ` + c.data + `]}`
+			f := &filter.Filter{}
+			err := f.UnmarshalJSON([]byte(data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), c.err)
+		})
+	}
+}
+
+func TestNewInterval(t *testing.T) {
+	start := &filter.Timestamp{Value: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)}
+	end := &filter.Date{Value: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	interval, err := filter.NewInterval(start, end)
+	require.NoError(t, err)
+	assert.Equal(t, start, interval.Start)
+
+	_, err = filter.NewInterval(end, &filter.Timestamp{Value: time.Date(2019, 12, 31, 23, 0, 0, 0, time.UTC)})
+	assert.EqualError(t, err, "interval start 2020-01-01 is after end 2019-12-31T23:00:00Z")
+
+	interval, err = filter.NewInterval(&filter.Property{Name: "end"}, start)
+	require.NoError(t, err)
+	assert.Equal(t, &filter.Property{Name: "end"}, interval.Start)
+}
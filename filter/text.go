@@ -0,0 +1,787 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/planetlabs/go-ogc/geometry"
+)
+
+// ParseText parses a filter written in CQL2 Text.  Keywords and function names are not case
+// sensitive.  Array literals are only supported as arguments of the array functions (e.g.
+// A_CONTAINS(tags, ('a', 'b'))).
+func ParseText(text string) (*Filter, error) {
+	p := &textParser{}
+	p.parser = parser{
+		lexer:         &lexer{input: text},
+		grammar:       p,
+		comparisons:   textComparisons,
+		continuations: textPredicateContinuations,
+	}
+
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("trouble parsing CQL2 text: %w", err)
+	}
+
+	t, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("trouble parsing CQL2 text: %w", err)
+	}
+	if t.kind != tokenEOF {
+		return nil, fmt.Errorf("trouble parsing CQL2 text: %w", unexpectedToken(t))
+	}
+
+	return &Filter{Expression: expression}, nil
+}
+
+type textParser struct {
+	parser
+}
+
+var textComparisons = map[string]string{
+	"=":  Equals,
+	"<>": NotEquals,
+	"<":  LessThan,
+	"<=": LessThanOrEquals,
+	">":  GreaterThan,
+	">=": GreaterThanOrEquals,
+}
+
+// textOps are the ops written as functions with a fixed number of arguments.
+var textOps = map[string]string{}
+
+func init() {
+	for _, name := range []string{
+		ArrayContainedBy, ArrayContains, ArrayEquals, ArrayOverlaps,
+		GeometryContains, GeometryCrosses, GeometryDisjoint, GeometryEquals,
+		GeometryIntersects, GeometryOverlaps, GeometryTouches, GeometryWithin,
+		TimeAfter, TimeBefore, TimeContains, TimeDisjoint, TimeDuring, TimeEquals,
+		TimeFinishedBy, TimeFinishes, TimeIntersects, TimeMeets, TimeMetBy,
+		TimeOverlappedBy, TimeOverlaps, TimeStartedBy, TimeStarts,
+		caseInsensitiveOp, accentInsensitiveOp,
+	} {
+		textOps[strings.ToUpper(name)] = name
+	}
+}
+
+// textPredicateContinuations are tokens that can follow the first expression in a predicate.
+var textPredicateContinuations = []string{
+	"=", "<>", "<", "<=", ">", ">=", "+", "-", "*", "/", "%", "^", "DIV",
+	"NOT", "BETWEEN", "LIKE", "IN", "IS",
+}
+
+func (p *textParser) parseKeywordPredicate(t token) (BooleanExpression, error) {
+	return nil, nil
+}
+
+func (p *textParser) parseKeywordComparison(keyword string, left Expression, t token) (BooleanExpression, error) {
+	return nil, nil
+}
+
+// parseLike parses a pattern expression.  ILIKE is not part of CQL2 Text.
+func (p *textParser) parseLike(keyword string, value CharacterExpression, t token) (BooleanExpression, error) {
+	if keyword != "LIKE" {
+		return nil, unexpectedToken(t)
+	}
+	patternStart, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	expression, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+	pattern, ok := expression.(PatternExpression)
+	if !ok {
+		return nil, fmt.Errorf("expected a pattern expression at position %d", patternStart.pos)
+	}
+	return &Like{Value: value, Pattern: pattern}, nil
+}
+
+func (p *textParser) parseInList() (ScalarList, error) {
+	items, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	list := make(ScalarList, len(items))
+	for i, expression := range items {
+		scalar, ok := expression.(ScalarExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected a scalar expression for item %d of list", i)
+		}
+		list[i] = scalar
+	}
+	return list, nil
+}
+
+// parseList parses a parenthesized list of expressions.
+func (p *textParser) parseList() ([]Expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	items := []Expression{}
+	closed, err := p.accept(")")
+	if err != nil {
+		return nil, err
+	}
+	for !closed {
+		item, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		more, err := p.accept(",")
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			closed = true
+		}
+	}
+	return items, nil
+}
+
+// parseArray parses an array literal or another array expression.
+func (p *textParser) parseArray() (Expression, error) {
+	t, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if !t.is("(") {
+		return p.parseExpression()
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	array := Array{}
+	closed, err := p.accept(")")
+	if err != nil {
+		return nil, err
+	}
+	for !closed {
+		start, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		expression, err := p.parseArray()
+		if err != nil {
+			return nil, err
+		}
+		item, ok := expression.(ArrayItemExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected an array item at position %d", start.pos)
+		}
+		array = append(array, item)
+
+		more, err := p.accept(",")
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			closed = true
+		}
+	}
+	return array, nil
+}
+
+func (p *textParser) parseExpression() (Expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !t.is("+") && !t.is("-") {
+			return left, nil
+		}
+		_, _ = p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Function{Op: t.value, Args: []Expression{left, right}}
+	}
+}
+
+func (p *textParser) parseTerm() (Expression, error) {
+	left, err := p.parsePower()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		op := ""
+		switch {
+		case t.is("*"), t.is("/"), t.is("%"):
+			op = t.value
+		case t.is("DIV"):
+			op = "div"
+		default:
+			return left, nil
+		}
+		_, _ = p.next()
+		right, err := p.parsePower()
+		if err != nil {
+			return nil, err
+		}
+		left = &Function{Op: op, Args: []Expression{left, right}}
+	}
+}
+
+func (p *textParser) parsePower() (Expression, error) {
+	base, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	power, err := p.accept("^")
+	if err != nil {
+		return nil, err
+	}
+	if !power {
+		return base, nil
+	}
+	exponent, err := p.parsePower()
+	if err != nil {
+		return nil, err
+	}
+	return &Function{Op: "^", Args: []Expression{base, exponent}}, nil
+}
+
+func (p *textParser) parseFactor() (Expression, error) {
+	negative, err := p.accept("-")
+	if err != nil {
+		return nil, err
+	}
+	if !negative {
+		return p.parsePrimary()
+	}
+
+	expression, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	if n, ok := expression.(*Number); ok {
+		return &Number{Value: -n.Value}, nil
+	}
+	return &Function{Op: "*", Args: []Expression{&Number{Value: -1}, expression}}, nil
+}
+
+func (p *textParser) parsePrimary() (Expression, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return &Number{Value: value}, nil
+
+	case tokenString:
+		return &String{Value: t.value}, nil
+
+	case tokenQuotedIdentifier:
+		return &Property{Name: t.value}, nil
+
+	case tokenPunctuation:
+		if !t.is("(") {
+			break
+		}
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expression, nil
+
+	case tokenIdentifier:
+		return p.parseIdentifier(t)
+	}
+
+	return nil, unexpectedToken(t)
+}
+
+func (p *textParser) parseIdentifier(t token) (Expression, error) {
+	keyword := strings.ToUpper(t.value)
+	switch keyword {
+	case "TRUE", "FALSE":
+		return &Boolean{Value: keyword == "TRUE"}, nil
+	}
+
+	next, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	if ecqlGeometryTypes[keyword] && (next.is("(") || next.is("EMPTY") || next.is("Z") || next.is("M") || next.is("ZM")) {
+		text, err := p.lexer.scanGeometry(t.pos)
+		if err != nil {
+			return nil, err
+		}
+		p.peeked = nil
+		g, err := geometry.UnmarshalWKT(text)
+		if err != nil {
+			return nil, fmt.Errorf("trouble parsing geometry at position %d: %w", t.pos, err)
+		}
+		return &Geometry{Value: g}, nil
+	}
+
+	if !next.is("(") {
+		return &Property{Name: t.value}, nil
+	}
+
+	switch keyword {
+	case "DATE", "TIMESTAMP":
+		_, _ = p.next()
+		value, err := p.parseInstantString(keyword)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return value, nil
+
+	case "INTERVAL":
+		return p.parseInterval()
+
+	case "BBOX":
+		items, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		if len(items) != 4 && len(items) != 6 {
+			return nil, fmt.Errorf("expected 4 or 6 values for BBOX at position %d, found %d", t.pos, len(items))
+		}
+		extent := make([]float64, len(items))
+		for i, item := range items {
+			n, ok := item.(*Number)
+			if !ok {
+				return nil, fmt.Errorf("expected a number for item %d of BBOX at position %d", i, t.pos)
+			}
+			extent[i] = n.Value
+		}
+		return &BoundingBox{Extent: extent}, nil
+	}
+
+	if name, ok := textOps[keyword]; ok {
+		var args []Expression
+		if strings.HasPrefix(name, "a_") {
+			args, err = p.parseArrayArgs()
+		} else {
+			args, err = p.parseList()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return newOp(name, args)
+	}
+
+	args, err := p.parseList()
+	if err != nil {
+		return nil, err
+	}
+	function := &Function{Op: t.value}
+	if len(args) > 0 {
+		function.Args = args
+	}
+	return function, nil
+}
+
+func (p *textParser) parseArrayArgs() ([]Expression, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	left, err := p.parseArray()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	right, err := p.parseArray()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return []Expression{left, right}, nil
+}
+
+func (p *textParser) parseInstantString(keyword string) (InstantExpression, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if t.kind != tokenString {
+		return nil, fmt.Errorf("expected a string for %s, found %s at position %d", keyword, t, t.pos)
+	}
+	if keyword == "DATE" {
+		value, err := time.Parse(time.DateOnly, t.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q at position %d", t.value, t.pos)
+		}
+		return &Date{Value: value}, nil
+	}
+	value, err := time.Parse(time.RFC3339Nano, t.value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q at position %d", t.value, t.pos)
+	}
+	return &Timestamp{Value: value.UTC()}, nil
+}
+
+func (p *textParser) parseInterval() (Expression, error) {
+	_, _ = p.next()
+	bounds := make([]InstantExpression, 2)
+	for i := range bounds {
+		if i > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		t, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if t.kind == tokenString {
+			_, _ = p.next()
+			if t.value == nilInstant {
+				continue
+			}
+			value, err := decodeDateOrTimestamp(t.value)
+			if err != nil {
+				return nil, fmt.Errorf("expected a date or timestamp, found %s at position %d", t, t.pos)
+			}
+			bounds[i] = value
+			continue
+		}
+
+		expression, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+		bound, ok := expression.(InstantExpression)
+		if !ok {
+			return nil, fmt.Errorf("expected an instant expression at position %d", t.pos)
+		}
+		bounds[i] = bound
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return NewInterval(bounds[0], bounds[1])
+}
+
+// EncodeText encodes an expression as CQL2 Text.
+func EncodeText(expr Expression) (string, error) {
+	builder := &strings.Builder{}
+	if err := writeText(builder, expr); err != nil {
+		return "", fmt.Errorf("trouble encoding CQL2 text: %w", err)
+	}
+	return builder.String(), nil
+}
+
+var textIdentifierPattern = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_:.]*$`)
+
+// textKeywords are identifiers that must be quoted when used as property names.
+var textKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "BETWEEN": true, "IN": true, "IS": true,
+	"NULL": true, "TRUE": true, "FALSE": true, "DIV": true,
+}
+
+func writeTextProperty(builder *strings.Builder, name string) {
+	upper := strings.ToUpper(name)
+	if textIdentifierPattern.MatchString(name) && !textKeywords[upper] && !ecqlGeometryTypes[upper] {
+		builder.WriteString(name)
+		return
+	}
+	builder.WriteString(`"`)
+	builder.WriteString(strings.ReplaceAll(name, `"`, `""`))
+	builder.WriteString(`"`)
+}
+
+func writeTextString(builder *strings.Builder, value string) {
+	builder.WriteString("'")
+	builder.WriteString(strings.ReplaceAll(value, "'", "''"))
+	builder.WriteString("'")
+}
+
+func formatTextNumber(value float64) string {
+	if math.Abs(value) < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func writeTextArgs[T Expression](builder *strings.Builder, args []T) error {
+	builder.WriteString("(")
+	for i, arg := range args {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		if err := writeText(builder, arg); err != nil {
+			return err
+		}
+	}
+	builder.WriteString(")")
+	return nil
+}
+
+// writeTextOperand writes an argument of a logical operator, adding parentheses if needed.
+func writeTextOperand(builder *strings.Builder, expr BooleanExpression) error {
+	switch expr.(type) {
+	case *And, *Or:
+		builder.WriteString("(")
+		if err := writeText(builder, expr); err != nil {
+			return err
+		}
+		builder.WriteString(")")
+		return nil
+	}
+	return writeText(builder, expr)
+}
+
+func writeTextLogical(builder *strings.Builder, keyword string, args []BooleanExpression) error {
+	for i, arg := range args {
+		if i > 0 {
+			builder.WriteString(" ")
+			builder.WriteString(keyword)
+			builder.WriteString(" ")
+		}
+		if err := writeTextOperand(builder, arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var textArithmetic = map[string]string{
+	"+":   "+",
+	"-":   "-",
+	"*":   "*",
+	"/":   "/",
+	"%":   "%",
+	"^":   "^",
+	"div": "DIV",
+}
+
+func writeText(builder *strings.Builder, expr Expression) error {
+	switch e := expr.(type) {
+	case *Filter:
+		return writeText(builder, e.Expression)
+
+	case *And:
+		return writeTextLogical(builder, "AND", e.Args)
+
+	case *Or:
+		return writeTextLogical(builder, "OR", e.Args)
+
+	case *Not:
+		builder.WriteString("NOT ")
+		switch e.Arg.(type) {
+		case *And, *Or, *Not, *Comparison, *Like, *Between, *In, *IsNull:
+			builder.WriteString("(")
+			if err := writeText(builder, e.Arg); err != nil {
+				return err
+			}
+			builder.WriteString(")")
+			return nil
+		}
+		return writeText(builder, e.Arg)
+
+	case *Comparison:
+		if err := writeText(builder, e.Left); err != nil {
+			return err
+		}
+		builder.WriteString(" " + e.Name + " ")
+		return writeText(builder, e.Right)
+
+	case *Like:
+		if err := writeText(builder, e.Value); err != nil {
+			return err
+		}
+		builder.WriteString(" LIKE ")
+		return writeText(builder, e.Pattern)
+
+	case *Between:
+		if err := writeText(builder, e.Value); err != nil {
+			return err
+		}
+		builder.WriteString(" BETWEEN ")
+		if err := writeText(builder, e.Low); err != nil {
+			return err
+		}
+		builder.WriteString(" AND ")
+		return writeText(builder, e.High)
+
+	case *In:
+		if err := writeText(builder, e.Item); err != nil {
+			return err
+		}
+		builder.WriteString(" IN ")
+		return writeTextArgs(builder, e.List)
+
+	case *IsNull:
+		if err := writeText(builder, e.Value); err != nil {
+			return err
+		}
+		builder.WriteString(" IS NULL")
+		return nil
+
+	case *ArrayComparison:
+		builder.WriteString(strings.ToUpper(e.Name))
+		return writeTextArgs(builder, []Expression{e.Left, e.Right})
+
+	case *SpatialComparison:
+		builder.WriteString(strings.ToUpper(e.Name))
+		return writeTextArgs(builder, []Expression{e.Left, e.Right})
+
+	case *TemporalComparison:
+		builder.WriteString(strings.ToUpper(e.Name))
+		return writeTextArgs(builder, []Expression{e.Left, e.Right})
+
+	case *CaseInsensitive:
+		builder.WriteString("CASEI")
+		return writeTextArgs(builder, []Expression{e.Value})
+
+	case *AccentInsensitive:
+		builder.WriteString("ACCENTI")
+		return writeTextArgs(builder, []Expression{e.Value})
+
+	case *Function:
+		if op, ok := textArithmetic[e.Op]; ok && len(e.Args) == 2 {
+			builder.WriteString("(")
+			if err := writeText(builder, e.Args[0]); err != nil {
+				return err
+			}
+			builder.WriteString(" " + op + " ")
+			if err := writeText(builder, e.Args[1]); err != nil {
+				return err
+			}
+			builder.WriteString(")")
+			return nil
+		}
+		builder.WriteString(e.Op)
+		return writeTextArgs(builder, e.Args)
+
+	case *Property:
+		writeTextProperty(builder, e.Name)
+		return nil
+
+	case *String:
+		writeTextString(builder, e.Value)
+		return nil
+
+	case *Number:
+		if math.IsInf(e.Value, 0) || math.IsNaN(e.Value) {
+			return fmt.Errorf("unsupported number %v", e.Value)
+		}
+		builder.WriteString(formatTextNumber(e.Value))
+		return nil
+
+	case *Boolean:
+		if e.Value {
+			builder.WriteString("TRUE")
+		} else {
+			builder.WriteString("FALSE")
+		}
+		return nil
+
+	case *Date:
+		builder.WriteString("DATE(")
+		writeTextString(builder, e.Value.Format(time.DateOnly))
+		builder.WriteString(")")
+		return nil
+
+	case *Timestamp:
+		builder.WriteString("TIMESTAMP(")
+		writeTextString(builder, formatTimestamp(e.Value))
+		builder.WriteString(")")
+		return nil
+
+	case *Interval:
+		builder.WriteString("INTERVAL(")
+		for i, bound := range []InstantExpression{e.Start, e.End} {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			switch b := bound.(type) {
+			case nil:
+				writeTextString(builder, nilInstant)
+			case *Date:
+				writeTextString(builder, b.Value.Format(time.DateOnly))
+			case *Timestamp:
+				writeTextString(builder, formatTimestamp(b.Value))
+			default:
+				if err := writeText(builder, b); err != nil {
+					return err
+				}
+			}
+		}
+		builder.WriteString(")")
+		return nil
+
+	case *Geometry:
+		text, err := geometry.MarshalWKT(e.Value)
+		if err != nil {
+			return err
+		}
+		builder.WriteString(text)
+		return nil
+
+	case *BoundingBox:
+		builder.WriteString("BBOX(")
+		for i, value := range e.Extent {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(formatTextNumber(value))
+		}
+		builder.WriteString(")")
+		return nil
+
+	case Array:
+		return writeTextArgs(builder, e)
+	}
+
+	return fmt.Errorf("unsupported expression %s", describe(expr))
+}
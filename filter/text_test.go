@@ -0,0 +1,261 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestText(t *testing.T) {
+	cases := []struct {
+		name   string
+		text   string
+		filter string
+	}{
+		{
+			name:   "comparison",
+			text:   "eo:cloud_cover < 10",
+			filter: `{"op": "<", "args": [{"property": "eo:cloud_cover"}, 10]}`,
+		},
+		{
+			name:   "quoted property",
+			text:   `"my property" = 'it''s'`,
+			filter: `{"op": "=", "args": [{"property": "my property"}, "it's"]}`,
+		},
+		{
+			name: "logical",
+			text: "(a = 1 OR b = 2) AND NOT (c = 3)",
+			filter: `{"op": "and", "args": [
+				{"op": "or", "args": [
+					{"op": "=", "args": [{"property": "a"}, 1]},
+					{"op": "=", "args": [{"property": "b"}, 2]}
+				]},
+				{"op": "not", "args": [{"op": "=", "args": [{"property": "c"}, 3]}]}
+			]}`,
+		},
+		{
+			name:   "like",
+			text:   "CASEI(name) LIKE CASEI('Ch%')",
+			filter: `{"op": "like", "args": [{"op": "casei", "args": [{"property": "name"}]}, {"op": "casei", "args": ["Ch%"]}]}`,
+		},
+		{
+			name:   "not like",
+			text:   "NOT (name LIKE 'a\\_%')",
+			filter: `{"op": "not", "args": [{"op": "like", "args": [{"property": "name"}, "a\\_%"]}]}`,
+		},
+		{
+			name:   "between",
+			text:   "depth BETWEEN 100 AND 150.5",
+			filter: `{"op": "between", "args": [{"property": "depth"}, 100, 150.5]}`,
+		},
+		{
+			name:   "in",
+			text:   "cityName IN ('Toronto', 'Frankfurt')",
+			filter: `{"op": "in", "args": [{"property": "cityName"}, ["Toronto", "Frankfurt"]]}`,
+		},
+		{
+			name:   "is null",
+			text:   "NOT (geometry IS NULL)",
+			filter: `{"op": "not", "args": [{"op": "isNull", "args": [{"property": "geometry"}]}]}`,
+		},
+		{
+			name:   "boolean",
+			text:   "flag = TRUE",
+			filter: `{"op": "=", "args": [{"property": "flag"}, true]}`,
+		},
+		{
+			name:   "spatial",
+			text:   "S_INTERSECTS(geometry, POINT (36.319836 32.288087))",
+			filter: `{"op": "s_intersects", "args": [{"property": "geometry"}, {"type": "Point", "coordinates": [36.319836, 32.288087]}]}`,
+		},
+		{
+			name:   "bbox",
+			text:   "S_INTERSECTS(geometry, BBOX(-128.098193, -1.1, -99999, 180, 90, 100000))",
+			filter: `{"op": "s_intersects", "args": [{"property": "geometry"}, {"bbox": [-128.098193, -1.1, -99999, 180, 90, 100000]}]}`,
+		},
+		{
+			name:   "temporal",
+			text:   "T_INTERSECTS(INTERVAL(starts_at, ends_at), INTERVAL('2005-01-10', '2010-02-10'))",
+			filter: `{"op": "t_intersects", "args": [{"interval": [{"property": "starts_at"}, {"property": "ends_at"}]}, {"interval": ["2005-01-10", "2010-02-10"]}]}`,
+		},
+		{
+			name:   "timestamp",
+			text:   "T_AFTER(updated, TIMESTAMP('2012-08-10T05:30:00Z'))",
+			filter: `{"op": "t_after", "args": [{"property": "updated"}, {"timestamp": "2012-08-10T05:30:00Z"}]}`,
+		},
+		{
+			name:   "open interval",
+			text:   "T_DURING(created, INTERVAL('2020-01-01T00:00:00Z', '..'))",
+			filter: `{"op": "t_during", "args": [{"property": "created"}, {"interval": ["2020-01-01T00:00:00Z", ".."]}]}`,
+		},
+		{
+			name:   "date",
+			text:   "updated > DATE('2012-08-10')",
+			filter: `{"op": ">", "args": [{"property": "updated"}, {"date": "2012-08-10"}]}`,
+		},
+		{
+			name:   "array",
+			text:   "A_CONTAINS(layer:ids, ('layers-ca', 'layers-us'))",
+			filter: `{"op": "a_contains", "args": [{"property": "layer:ids"}, ["layers-ca", "layers-us"]]}`,
+		},
+		{
+			name:   "arithmetic",
+			text:   "(vehicle_height + 1) > (bridge_clearance * 2)",
+			filter: `{"op": ">", "args": [{"op": "+", "args": [{"property": "vehicle_height"}, 1]}, {"op": "*", "args": [{"property": "bridge_clearance"}, 2]}]}`,
+		},
+		{
+			name:   "function",
+			text:   "Foo(geometry) = TRUE",
+			filter: `{"op": "=", "args": [{"op": "Foo", "args": [{"property": "geometry"}]}, true]}`,
+		},
+	}
+
+	schema := getSchema(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := filter.ParseText(c.text)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.filter, f.String())
+
+			var v any
+			require.NoError(t, json.Unmarshal([]byte(f.String()), &v))
+			assert.NoError(t, schema.Validate(v))
+
+			text, err := filter.EncodeText(f)
+			require.NoError(t, err)
+			assert.Equal(t, c.text, text)
+		})
+	}
+}
+
+func TestParseTextVariants(t *testing.T) {
+	cases := []struct {
+		text   string
+		filter string
+	}{
+		{
+			text:   "a = 1 and not b is null",
+			filter: `{"op": "and", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "not", "args": [{"op": "isNull", "args": [{"property": "b"}]}]}]}`,
+		},
+		{
+			text:   "a NOT BETWEEN 1 AND 2",
+			filter: `{"op": "not", "args": [{"op": "between", "args": [{"property": "a"}, 1, 2]}]}`,
+		},
+		{
+			text:   "a IS NOT NULL",
+			filter: `{"op": "not", "args": [{"op": "isNull", "args": [{"property": "a"}]}]}`,
+		},
+		{
+			text:   "s_within(geometry, polygon((0 0, 1 0, 1 1, 0 0)))",
+			filter: `{"op": "s_within", "args": [{"property": "geometry"}, {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}]}`,
+		},
+		{
+			text:   "a = -b",
+			filter: `{"op": "=", "args": [{"property": "a"}, {"op": "*", "args": [-1, {"property": "b"}]}]}`,
+		},
+		{
+			text:   "a = 2 ^ 3 ^ 2 DIV 4",
+			filter: `{"op": "=", "args": [{"property": "a"}, {"op": "div", "args": [{"op": "^", "args": [2, {"op": "^", "args": [3, 2]}]}, 4]}]}`,
+		},
+		{
+			text:   "TIMESTAMP('2020-01-01T02:00:00+02:00') = t",
+			filter: `{"op": "=", "args": [{"timestamp": "2020-01-01T00:00:00Z"}, {"property": "t"}]}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			f, err := filter.ParseText(c.text)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.filter, f.String())
+		})
+	}
+}
+
+func TestParseTextErrors(t *testing.T) {
+	cases := []struct {
+		text string
+		err  string
+	}{
+		{
+			text: "a =",
+			err:  "trouble parsing CQL2 text: unexpected end of input at position 3",
+		},
+		{
+			text: "a = 1 b",
+			err:  "trouble parsing CQL2 text: unexpected b at position 6",
+		},
+		{
+			text: "a > DATE('yesterday')",
+			err:  `trouble parsing CQL2 text: invalid date "yesterday" at position 9`,
+		},
+		{
+			text: "S_INTERSECTS(geometry)",
+			err:  `trouble parsing CQL2 text: expected 2 args for "s_intersects" op, found 1`,
+		},
+		{
+			text: "T_AFTER(a, INTERVAL('..', '..'))",
+			err:  "trouble parsing CQL2 text: interval start or end must be provided",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			_, err := filter.ParseText(c.text)
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	cases := []struct {
+		filter string
+		text   string
+	}{
+		{
+			filter: `{"op": "=", "args": [{"property": "and"}, "x"]}`,
+			text:   `"and" = 'x'`,
+		},
+		{
+			filter: `{"op": "or", "args": [{"op": "and", "args": [{"op": "=", "args": [{"property": "a"}, 1]}, {"op": "=", "args": [{"property": "b"}, 2]}]}, {"op": "=", "args": [{"property": "c"}, 3]}]}`,
+			text:   "(a = 1 AND b = 2) OR c = 3",
+		},
+		{
+			filter: `{"op": "t_before", "args": [{"property": "a"}, {"interval": ["..", "2020-01-01"]}]}`,
+			text:   "T_BEFORE(a, INTERVAL('..', '2020-01-01'))",
+		},
+		{
+			filter: `{"op": "=", "args": [{"property": "a"}, 1e+30]}`,
+			text:   "a = 1e+30",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			text, err := filter.EncodeText(mustParseFilter(t, c.filter))
+			require.NoError(t, err)
+			assert.Equal(t, c.text, text)
+
+			f, err := filter.ParseText(text)
+			require.NoError(t, err)
+			assert.JSONEq(t, c.filter, f.String())
+		})
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+// Walk visits an expression and all of its descendants in depth-first order.  The children
+// of an expression are only visited if the visit function returns true.  Descendants include
+// function arguments, array and list items, and interval bounds.
+func Walk(expr Expression, visit func(Expression) bool) {
+	if expr == nil || !visit(expr) {
+		return
+	}
+	for _, child := range children(expr) {
+		Walk(child, visit)
+	}
+}
+
+func children(expr Expression) []Expression {
+	switch e := expr.(type) {
+	case *Filter:
+		return []Expression{e.Expression}
+	case *Not:
+		return []Expression{e.Arg}
+	case *And:
+		return toExpressions(e.Args)
+	case *Or:
+		return toExpressions(e.Args)
+	case *Comparison:
+		return []Expression{e.Left, e.Right}
+	case *ArrayComparison:
+		return []Expression{e.Left, e.Right}
+	case *SpatialComparison:
+		return []Expression{e.Left, e.Right}
+	case *TemporalComparison:
+		return []Expression{e.Left, e.Right}
+	case *Like:
+		return []Expression{e.Value, e.Pattern}
+	case *Between:
+		return []Expression{e.Value, e.Low, e.High}
+	case *In:
+		return []Expression{e.Item, e.List}
+	case ScalarList:
+		return toExpressions(e)
+	case *IsNull:
+		return []Expression{e.Value}
+	case *CaseInsensitive:
+		return []Expression{e.Value}
+	case *AccentInsensitive:
+		return []Expression{e.Value}
+	case *Function:
+		return e.Args
+	case Array:
+		return toExpressions(e)
+	case *Interval:
+		bounds := []Expression{}
+		if e.Start != nil {
+			bounds = append(bounds, e.Start)
+		}
+		if e.End != nil {
+			bounds = append(bounds, e.End)
+		}
+		return bounds
+	}
+	return nil
+}
+
+func toExpressions[T Expression](items []T) []Expression {
+	expressions := make([]Expression, len(items))
+	for i, item := range items {
+		expressions[i] = item
+	}
+	return expressions
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	f := mustParseFilter(t, `{"op": "and", "args": [
+		{"op": "in", "args": [{"property": "a"}, ["x", "y"]]},
+		{"op": "=", "args": [{"op": "lower", "args": [{"property": "b"}]}, "z"]},
+		{"op": "t_during", "args": [{"property": "c"}, {"interval": [{"property": "d"}, ".."]}]},
+		{"op": "not", "args": [{"op": "isNull", "args": [{"property": "e"}]}]}
+	]}`)
+
+	names := []string{}
+	filter.Walk(f, func(e filter.Expression) bool {
+		if p, ok := e.(*filter.Property); ok {
+			names = append(names, p.Name)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, names)
+
+	names = []string{}
+	filter.Walk(f, func(e filter.Expression) bool {
+		if p, ok := e.(*filter.Property); ok {
+			names = append(names, p.Name)
+		}
+		_, skip := e.(*filter.Not)
+		return !skip
+	})
+	assert.Equal(t, []string{"a", "b", "c", "d"}, names)
+}
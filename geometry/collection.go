@@ -0,0 +1,84 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// GeometryCollection is a heterogeneous collection of geometries.
+type GeometryCollection struct {
+	Geometries []Geometry
+}
+
+var (
+	_ Geometry         = (*GeometryCollection)(nil)
+	_ json.Unmarshaler = (*GeometryCollection)(nil)
+)
+
+func (*GeometryCollection) geometry() {}
+
+func (*GeometryCollection) Type() string {
+	return TypeGeometryCollection
+}
+
+func (g *GeometryCollection) Bounds() []float64 {
+	e := newExtent(LayoutDefault)
+	for _, child := range g.Geometries {
+		if child != nil {
+			e.addBounds(child.Bounds())
+		}
+	}
+	return e.bounds()
+}
+
+func (g *GeometryCollection) Validate() error {
+	for i, child := range g.Geometries {
+		if child == nil {
+			return fmt.Errorf("missing geometry %d in geometry collection", i)
+		}
+		if err := child.Validate(); err != nil {
+			return fmt.Errorf("invalid geometry %d in geometry collection: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (g *GeometryCollection) MarshalJSON() ([]byte, error) {
+	geometries := g.Geometries
+	if geometries == nil {
+		geometries = []Geometry{}
+	}
+	for _, child := range geometries {
+		if child == nil {
+			return nil, errors.New("missing geometry in geometry collection")
+		}
+	}
+	return json.Marshal(map[string]any{
+		"type":       TypeGeometryCollection,
+		"geometries": geometries,
+	})
+}
+
+func (g *GeometryCollection) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypeGeometryCollection)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*GeometryCollection)
+	return nil
+}
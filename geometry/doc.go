@@ -0,0 +1,24 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geometry provides typed geometries for use with OGC API features and CQL2 filters.
+//
+// Geometries are encoded and decoded as GeoJSON, Well-Known Text (WKT), and Well-Known Binary (WKB).
+// See the specifications below for more detail:
+//   - [RFC 7946: The GeoJSON Format]
+//   - [OpenGIS Simple Features Access - Part 1: Common Architecture]
+//
+// [RFC 7946: The GeoJSON Format]: https://datatracker.ietf.org/doc/html/rfc7946
+// [OpenGIS Simple Features Access - Part 1: Common Architecture]: https://portal.ogc.org/files/?artifact_id=25355
+package geometry
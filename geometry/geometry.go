@@ -0,0 +1,348 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Geometry types.
+const (
+	TypePoint              = "Point"
+	TypeLineString         = "LineString"
+	TypePolygon            = "Polygon"
+	TypeMultiPoint         = "MultiPoint"
+	TypeMultiLineString    = "MultiLineString"
+	TypeMultiPolygon       = "MultiPolygon"
+	TypeGeometryCollection = "GeometryCollection"
+)
+
+// Geometry is implemented by all of the geometry types in this package.
+type Geometry interface {
+	json.Marshaler
+
+	// Type returns the GeoJSON type name of the geometry.
+	Type() string
+
+	// Bounds returns the bounding box of the geometry.  The result has four values
+	// (minX, minY, maxX, maxY) or six values if all positions have a third dimension.
+	// The result is nil for empty geometries.
+	Bounds() []float64
+
+	// Validate checks that the geometry coordinates are well formed.
+	Validate() error
+
+	geometry()
+}
+
+// IsType reports whether the provided name is one of the geometry types.
+func IsType(name string) bool {
+	switch name {
+	case TypePoint, TypeLineString, TypePolygon, TypeMultiPoint, TypeMultiLineString, TypeMultiPolygon, TypeGeometryCollection:
+		return true
+	}
+	return false
+}
+
+// Unmarshal decodes and validates a GeoJSON geometry.
+func Unmarshal(data []byte) (Geometry, error) {
+	value := map[string]any{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return Decode(value)
+}
+
+// Decode creates a geometry from a generic GeoJSON object (as produced by json.Unmarshal)
+// and validates the result.
+func Decode(value map[string]any) (Geometry, error) {
+	geometryType, ok := value["type"].(string)
+	if !ok {
+		return nil, errors.New("geometry missing type")
+	}
+
+	var g Geometry
+	if geometryType == TypeGeometryCollection {
+		geometries, ok := value["geometries"].([]any)
+		if !ok {
+			return nil, errors.New("expected geometries array in geometry collection")
+		}
+		collection := &GeometryCollection{Geometries: make([]Geometry, len(geometries))}
+		for i, item := range geometries {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("expected an object for geometry %d in geometry collection", i)
+			}
+			child, err := Decode(m)
+			if err != nil {
+				return nil, fmt.Errorf("trouble decoding geometry %d in geometry collection: %w", i, err)
+			}
+			collection.Geometries[i] = child
+		}
+		return collection, nil
+	}
+
+	coordinates, ok := value["coordinates"].([]any)
+	if !ok {
+		return nil, errors.New("expected coordinates in geometry")
+	}
+
+	switch geometryType {
+	case TypePoint:
+		point := &Point{}
+		if len(coordinates) > 0 {
+			position, err := decodePosition(coordinates)
+			if err != nil {
+				return nil, err
+			}
+			point.Coordinates = position
+		}
+		g = point
+	case TypeLineString:
+		positions, err := decodePositions(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		g = &LineString{Coordinates: positions}
+	case TypePolygon:
+		rings, err := decodeRings(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		g = &Polygon{Coordinates: rings}
+	case TypeMultiPoint:
+		positions, err := decodePositions(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		g = &MultiPoint{Coordinates: positions}
+	case TypeMultiLineString:
+		lines, err := decodeRings(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		g = &MultiLineString{Coordinates: lines}
+	case TypeMultiPolygon:
+		polygons := make([][][][]float64, len(coordinates))
+		for i, v := range coordinates {
+			rings, err := decodeRings(v)
+			if err != nil {
+				return nil, err
+			}
+			polygons[i] = rings
+		}
+		g = &MultiPolygon{Coordinates: polygons}
+	default:
+		return nil, fmt.Errorf("unexpected geometry type: %s", geometryType)
+	}
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func decodePosition(value any) ([]float64, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected position array, got %v", value)
+	}
+	position := make([]float64, len(values))
+	for i, v := range values {
+		n, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected number in position, got %v", v)
+		}
+		position[i] = n
+	}
+	return position, nil
+}
+
+func decodePositions(value any) ([][]float64, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected array of positions, got %v", value)
+	}
+	positions := make([][]float64, len(values))
+	for i, v := range values {
+		position, err := decodePosition(v)
+		if err != nil {
+			return nil, err
+		}
+		positions[i] = position
+	}
+	return positions, nil
+}
+
+func decodeRings(value any) ([][][]float64, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected array of position arrays, got %v", value)
+	}
+	rings := make([][][]float64, len(values))
+	for i, v := range values {
+		positions, err := decodePositions(v)
+		if err != nil {
+			return nil, err
+		}
+		rings[i] = positions
+	}
+	return rings, nil
+}
+
+func validatePosition(position []float64, layout Layout) error {
+	if stride := layout.Stride(); stride > 0 && len(position) != stride {
+		return fmt.Errorf("expected %d values in %s position, found %d", stride, layout, len(position))
+	}
+	if len(position) < 2 {
+		return fmt.Errorf("expected at least 2 values in position, found %d", len(position))
+	}
+	for _, v := range position {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("invalid position value %v", v)
+		}
+	}
+	return nil
+}
+
+func validatePositions(positions [][]float64, layout Layout) error {
+	for _, position := range positions {
+		if err := validatePosition(position, layout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLine(positions [][]float64, layout Layout) error {
+	if len(positions) == 1 {
+		return errors.New("expected at least 2 positions in line")
+	}
+	return validatePositions(positions, layout)
+}
+
+func validateRing(positions [][]float64, layout Layout) error {
+	if len(positions) < 4 {
+		return fmt.Errorf("expected at least 4 positions in ring, found %d", len(positions))
+	}
+	if err := validatePositions(positions, layout); err != nil {
+		return err
+	}
+	first := positions[0]
+	last := positions[len(positions)-1]
+	if len(first) != len(last) {
+		return errors.New("ring is not closed")
+	}
+	for i := range first {
+		if first[i] != last[i] {
+			return errors.New("ring is not closed")
+		}
+	}
+	return nil
+}
+
+func validatePolygon(rings [][][]float64, layout Layout) error {
+	for i, ring := range rings {
+		if err := validateRing(ring, layout); err != nil {
+			return fmt.Errorf("invalid polygon ring %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// extent accumulates the bounds of a set of positions.
+type extent struct {
+	min   [3]float64
+	max   [3]float64
+	dims  int
+	empty bool
+}
+
+func newExtent(layout Layout) *extent {
+	return &extent{dims: layout.spatialDims(), empty: true}
+}
+
+func (e *extent) addPosition(position []float64) {
+	dims := min(len(position), e.dims)
+	e.dims = dims
+	for i := 0; i < dims; i++ {
+		v := position[i]
+		if e.empty || v < e.min[i] {
+			e.min[i] = v
+		}
+		if e.empty || v > e.max[i] {
+			e.max[i] = v
+		}
+	}
+	e.empty = false
+}
+
+func (e *extent) addPositions(positions [][]float64) {
+	for _, position := range positions {
+		e.addPosition(position)
+	}
+}
+
+func (e *extent) addBounds(bounds []float64) {
+	switch len(bounds) {
+	case 4:
+		e.addPosition(bounds[:2])
+		e.addPosition(bounds[2:])
+	case 6:
+		e.addPosition(bounds[:3])
+		e.addPosition(bounds[3:])
+	}
+}
+
+func (e *extent) bounds() []float64 {
+	if e.empty {
+		return nil
+	}
+	if e.dims == 3 {
+		return []float64{e.min[0], e.min[1], e.min[2], e.max[0], e.max[1], e.max[2]}
+	}
+	return []float64{e.min[0], e.min[1], e.max[0], e.max[1]}
+}
+
+type encodedGeometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+func marshalCoordinates[T any](geometryType string, layout Layout, coordinates []T) ([]byte, error) {
+	if coordinates == nil {
+		coordinates = []T{}
+	}
+	encoded := &encodedGeometry{Type: geometryType, Coordinates: coordinates}
+	if layout == LayoutXYM {
+		// GeoJSON positions have no measure
+		encoded.Coordinates = dropMeasures(coordinates)
+	}
+	return json.Marshal(encoded)
+}
+
+func unmarshalTyped(data []byte, expectedType string) (Geometry, error) {
+	g, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	if g.Type() != expectedType {
+		return nil, fmt.Errorf("expected %s geometry, got %s", expectedType, g.Type())
+	}
+	return g, nil
+}
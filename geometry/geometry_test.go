@@ -0,0 +1,215 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry geometry.Geometry
+		data     string
+		bounds   []float64
+	}{
+		{
+			name:     "point",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2}},
+			data:     `{"type": "Point", "coordinates": [1, 2]}`,
+			bounds:   []float64{1, 2, 1, 2},
+		},
+		{
+			name:     "point 3d",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+			data:     `{"type": "Point", "coordinates": [1, 2, 3]}`,
+			bounds:   []float64{1, 2, 3, 1, 2, 3},
+		},
+		{
+			name:     "empty point",
+			geometry: &geometry.Point{},
+			data:     `{"type": "Point", "coordinates": []}`,
+		},
+		{
+			name:     "linestring",
+			geometry: &geometry.LineString{Coordinates: [][]float64{{0, 0}, {10, -5}, {3, 8}}},
+			data:     `{"type": "LineString", "coordinates": [[0, 0], [10, -5], [3, 8]]}`,
+			bounds:   []float64{0, -5, 10, 8},
+		},
+		{
+			name: "polygon",
+			geometry: &geometry.Polygon{Coordinates: [][][]float64{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{2, 2}, {2, 4}, {4, 4}, {2, 2}},
+			}},
+			data: `{"type": "Polygon", "coordinates": [
+				[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]],
+				[[2, 2], [2, 4], [4, 4], [2, 2]]
+			]}`,
+			bounds: []float64{0, 0, 10, 10},
+		},
+		{
+			name:     "multipoint",
+			geometry: &geometry.MultiPoint{Coordinates: [][]float64{{1, 2, 3}, {4, 5}}},
+			data:     `{"type": "MultiPoint", "coordinates": [[1, 2, 3], [4, 5]]}`,
+			bounds:   []float64{1, 2, 4, 5},
+		},
+		{
+			name:     "multilinestring",
+			geometry: &geometry.MultiLineString{Coordinates: [][][]float64{{{0, 0}, {1, 1}}, {{-1, 5}, {2, 3}}}},
+			data:     `{"type": "MultiLineString", "coordinates": [[[0, 0], [1, 1]], [[-1, 5], [2, 3]]]}`,
+			bounds:   []float64{-1, 0, 2, 5},
+		},
+		{
+			name: "multipolygon",
+			geometry: &geometry.MultiPolygon{Coordinates: [][][][]float64{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{5, 5}, {6, 5}, {6, 7}, {5, 5}}},
+			}},
+			data: `{"type": "MultiPolygon", "coordinates": [
+				[[[0, 0], [1, 0], [1, 1], [0, 0]]],
+				[[[5, 5], [6, 5], [6, 7], [5, 5]]]
+			]}`,
+			bounds: []float64{0, 0, 6, 7},
+		},
+		{
+			name: "geometry collection",
+			geometry: &geometry.GeometryCollection{Geometries: []geometry.Geometry{
+				&geometry.Point{Coordinates: []float64{-10, 20}},
+				&geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+			}},
+			data: `{"type": "GeometryCollection", "geometries": [
+				{"type": "Point", "coordinates": [-10, 20]},
+				{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}
+			]}`,
+			bounds: []float64{-10, 0, 1, 20},
+		},
+		{
+			name:     "empty geometry collection",
+			geometry: &geometry.GeometryCollection{Geometries: []geometry.Geometry{}},
+			data:     `{"type": "GeometryCollection", "geometries": []}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.geometry)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.data, string(data))
+
+			decoded, err := geometry.Unmarshal([]byte(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+
+			assert.Equal(t, tc.bounds, decoded.Bounds())
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "missing type",
+			data: `{"coordinates": [1, 2]}`,
+			err:  "geometry missing type",
+		},
+		{
+			name: "unknown type",
+			data: `{"type": "Circle", "coordinates": [1, 2]}`,
+			err:  "unexpected geometry type: Circle",
+		},
+		{
+			name: "missing coordinates",
+			data: `{"type": "Point"}`,
+			err:  "expected coordinates in geometry",
+		},
+		{
+			name: "short position",
+			data: `{"type": "Point", "coordinates": [1]}`,
+			err:  "expected at least 2 values in position, found 1",
+		},
+		{
+			name: "non-numeric position",
+			data: `{"type": "Point", "coordinates": [1, "two"]}`,
+			err:  "expected number in position, got two",
+		},
+		{
+			name: "short linestring",
+			data: `{"type": "LineString", "coordinates": [[1, 2]]}`,
+			err:  "invalid linestring: expected at least 2 positions in line",
+		},
+		{
+			name: "short ring",
+			data: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 1], [0, 0]]]}`,
+			err:  "invalid polygon ring 0: expected at least 4 positions in ring, found 3",
+		},
+		{
+			name: "open ring",
+			data: `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`,
+			err:  "invalid polygon ring 0: ring is not closed",
+		},
+		{
+			name: "open ring in multipolygon",
+			data: `{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 1]]]]}`,
+			err:  "invalid polygon 0 in multipolygon: invalid polygon ring 0: ring is not closed",
+		},
+		{
+			name: "invalid child",
+			data: `{"type": "GeometryCollection", "geometries": [{"type": "Point", "coordinates": [1]}]}`,
+			err:  "trouble decoding geometry 0 in geometry collection: expected at least 2 values in position, found 1",
+		},
+		{
+			name: "missing geometries",
+			data: `{"type": "GeometryCollection"}`,
+			err:  "expected geometries array in geometry collection",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := geometry.Unmarshal([]byte(tc.data))
+			require.Error(t, err)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestUnmarshalTyped(t *testing.T) {
+	value := struct {
+		Location *geometry.Point   `json:"location"`
+		Area     *geometry.Polygon `json:"area"`
+	}{}
+
+	data := `{
+		"location": {"type": "Point", "coordinates": [1, 2]},
+		"area": {"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 0]]]}
+	}`
+
+	require.NoError(t, json.Unmarshal([]byte(data), &value))
+	assert.Equal(t, []float64{1, 2}, value.Location.Coordinates)
+	assert.Equal(t, []float64{0, 0, 1, 1}, value.Area.Bounds())
+
+	err := json.Unmarshal([]byte(`{"location": {"type": "LineString", "coordinates": [[0, 0], [1, 1]]}}`), &value)
+	assert.EqualError(t, err, "expected Point geometry, got LineString")
+}
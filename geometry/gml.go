@@ -0,0 +1,601 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GMLNamespace is the GML 3.2 namespace URI.
+	GMLNamespace = "http://www.opengis.net/gml/3.2"
+
+	// CRS84 is the identifier for WGS 84 with longitude, latitude axis order.
+	CRS84 = "http://www.opengis.net/def/crs/OGC/1.3/CRS84"
+)
+
+// gmlAxisOrder returns true if positions in the given CRS are in latitude, longitude order.
+// Only WGS 84 identifiers are supported.  The short "EPSG:4326" form is treated as longitude,
+// latitude (following the common WFS convention), while the URN and URI forms follow the EPSG
+// axis order.
+func gmlAxisOrder(srsName string) (bool, error) {
+	switch srsName {
+	case "", CRS84, "urn:ogc:def:crs:OGC:1.3:CRS84", "urn:ogc:def:crs:OGC::CRS84", "EPSG:4326", "http://www.opengis.net/gml/srs/epsg.xml#4326":
+		return false, nil
+	case "urn:ogc:def:crs:EPSG::4326", "urn:x-ogc:def:crs:EPSG:4326", "http://www.opengis.net/def/crs/EPSG/0/4326":
+		return true, nil
+	}
+	return false, fmt.Errorf("unsupported srsName %q", srsName)
+}
+
+// MarshalGML encodes a geometry as a GML 3.2 element.  The element declares the gml namespace
+// prefix and uses the CRS84 srsName.  Multi-line strings are encoded as gml:MultiCurve,
+// multi-polygons as gml:MultiSurface, and geometry collections as gml:MultiGeometry.  GML
+// positions have no measure, so measure values are dropped.
+func MarshalGML(g Geometry) (string, error) {
+	builder := &strings.Builder{}
+	if err := writeGML(builder, g, true); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// MarshalGMLEnvelope encodes bounds (minX, minY, maxX, maxY or the equivalent with a third
+// dimension) as a GML 3.2 gml:Envelope element.
+func MarshalGMLEnvelope(bounds []float64) (string, error) {
+	if len(bounds) != 4 && len(bounds) != 6 {
+		return "", fmt.Errorf("expected 4 or 6 values in bounds, found %d", len(bounds))
+	}
+	dims := len(bounds) / 2
+
+	b := &strings.Builder{}
+	writeGMLStart(b, "Envelope", true, dims)
+	b.WriteString(">")
+	writeGMLValues(b, "lowerCorner", bounds[:dims])
+	writeGMLValues(b, "upperCorner", bounds[dims:])
+	b.WriteString("</gml:Envelope>")
+	return b.String(), nil
+}
+
+func writeGMLStart(b *strings.Builder, name string, root bool, dims int) {
+	b.WriteString("<gml:")
+	b.WriteString(name)
+	if root {
+		fmt.Fprintf(b, ` xmlns:gml="%s" srsName="%s"`, GMLNamespace, CRS84)
+		if dims == 3 {
+			b.WriteString(` srsDimension="3"`)
+		}
+	}
+}
+
+func writeGMLValues(b *strings.Builder, name string, values []float64) {
+	fmt.Fprintf(b, "<gml:%s>", name)
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+	fmt.Fprintf(b, "</gml:%s>", name)
+}
+
+func writeGMLPositions(b *strings.Builder, name string, positions [][]float64, dims int) error {
+	values := make([]float64, 0, len(positions)*dims)
+	for _, position := range positions {
+		if len(position) < dims {
+			return fmt.Errorf("expected at least %d values in position, found %d", dims, len(position))
+		}
+		values = append(values, position[:dims]...)
+	}
+	writeGMLValues(b, name, values)
+	return nil
+}
+
+func writeGMLPolygon(b *strings.Builder, rings [][][]float64, root bool, dims int) error {
+	if len(rings) == 0 {
+		return errors.New("cannot encode an empty polygon as GML")
+	}
+	writeGMLStart(b, "Polygon", root, dims)
+	b.WriteString(">")
+	for i, ring := range rings {
+		boundary := "interior"
+		if i == 0 {
+			boundary = "exterior"
+		}
+		fmt.Fprintf(b, "<gml:%s><gml:LinearRing>", boundary)
+		if err := writeGMLPositions(b, "posList", ring, dims); err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "</gml:LinearRing></gml:%s>", boundary)
+	}
+	b.WriteString("</gml:Polygon>")
+	return nil
+}
+
+func writeGML(b *strings.Builder, g Geometry, root bool) error {
+	if g == nil {
+		return errors.New("missing geometry")
+	}
+	layout, err := resolveLayout(g)
+	if err != nil {
+		return err
+	}
+	dims := layout.spatialDims()
+
+	switch t := g.(type) {
+	case *Point:
+		if len(t.Coordinates) == 0 {
+			return errors.New("cannot encode an empty point as GML")
+		}
+		writeGMLStart(b, "Point", root, dims)
+		b.WriteString(">")
+		if err := writeGMLPositions(b, "pos", [][]float64{t.Coordinates}, dims); err != nil {
+			return err
+		}
+		b.WriteString("</gml:Point>")
+
+	case *LineString:
+		if len(t.Coordinates) == 0 {
+			return errors.New("cannot encode an empty line string as GML")
+		}
+		writeGMLStart(b, "LineString", root, dims)
+		b.WriteString(">")
+		if err := writeGMLPositions(b, "posList", t.Coordinates, dims); err != nil {
+			return err
+		}
+		b.WriteString("</gml:LineString>")
+
+	case *Polygon:
+		return writeGMLPolygon(b, t.Coordinates, root, dims)
+
+	case *MultiPoint:
+		writeGMLStart(b, "MultiPoint", root, dims)
+		b.WriteString(">")
+		for _, position := range t.Coordinates {
+			b.WriteString("<gml:pointMember><gml:Point>")
+			if err := writeGMLPositions(b, "pos", [][]float64{position}, dims); err != nil {
+				return err
+			}
+			b.WriteString("</gml:Point></gml:pointMember>")
+		}
+		b.WriteString("</gml:MultiPoint>")
+
+	case *MultiLineString:
+		writeGMLStart(b, "MultiCurve", root, dims)
+		b.WriteString(">")
+		for _, line := range t.Coordinates {
+			b.WriteString("<gml:curveMember><gml:LineString>")
+			if err := writeGMLPositions(b, "posList", line, dims); err != nil {
+				return err
+			}
+			b.WriteString("</gml:LineString></gml:curveMember>")
+		}
+		b.WriteString("</gml:MultiCurve>")
+
+	case *MultiPolygon:
+		writeGMLStart(b, "MultiSurface", root, dims)
+		b.WriteString(">")
+		for _, polygon := range t.Coordinates {
+			b.WriteString("<gml:surfaceMember>")
+			if err := writeGMLPolygon(b, polygon, false, dims); err != nil {
+				return err
+			}
+			b.WriteString("</gml:surfaceMember>")
+		}
+		b.WriteString("</gml:MultiSurface>")
+
+	case *GeometryCollection:
+		writeGMLStart(b, "MultiGeometry", root, dims)
+		b.WriteString(">")
+		for _, child := range t.Geometries {
+			b.WriteString("<gml:geometryMember>")
+			if err := writeGML(b, child, false); err != nil {
+				return err
+			}
+			b.WriteString("</gml:geometryMember>")
+		}
+		b.WriteString("</gml:MultiGeometry>")
+
+	default:
+		return fmt.Errorf("unsupported geometry type %s", g.Type())
+	}
+	return nil
+}
+
+// UnmarshalGML decodes and validates a geometry from a GML 3.2 document.
+func UnmarshalGML(data []byte) (Geometry, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	start, err := firstElement(decoder)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeGML(decoder, start)
+}
+
+// DecodeGML decodes and validates a GML 3.2 geometry element.  The start element has already
+// been read from the decoder, and the decoder is positioned after the matching end element
+// when decoding succeeds.  This allows GML geometries to be decoded from within other
+// documents.
+//
+// Point, LineString, Polygon, MultiPoint, MultiCurve, MultiSurface, and MultiGeometry elements
+// are supported.  Positions are converted to longitude, latitude order based on the srsName,
+// which must identify WGS 84 (CRS84 is assumed if there is no srsName).
+func DecodeGML(decoder *xml.Decoder, start xml.StartElement) (Geometry, error) {
+	d := &gmlDecoder{decoder: decoder}
+	g, err := d.geometry(start, gmlContext{dims: 2})
+	if err != nil {
+		return nil, err
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// DecodeGMLEnvelope decodes a GML 3.2 gml:Envelope element as bounds (minX, minY, maxX, maxY
+// or the equivalent with a third dimension).  See DecodeGML for details on decoder position
+// and axis order.
+func DecodeGMLEnvelope(decoder *xml.Decoder, start xml.StartElement) ([]float64, error) {
+	d := &gmlDecoder{decoder: decoder}
+	if err := d.expectName(start, "Envelope"); err != nil {
+		return nil, err
+	}
+	ctx, err := d.context(start, gmlContext{dims: 2})
+	if err != nil {
+		return nil, err
+	}
+
+	var lower, upper []float64
+	err = d.children(func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "lowerCorner":
+			position, err := d.position(child, ctx)
+			lower = position
+			return err
+		case "upperCorner":
+			position, err := d.position(child, ctx)
+			upper = position
+			return err
+		}
+		return d.skipMetadata(child)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lower == nil || upper == nil {
+		return nil, errors.New("expected lowerCorner and upperCorner in envelope")
+	}
+	if len(lower) != len(upper) {
+		return nil, errors.New("expected the same number of values in lowerCorner and upperCorner")
+	}
+	return append(lower, upper...), nil
+}
+
+func firstElement(decoder *xml.Decoder) (xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, errors.New("missing GML element")
+		}
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
+
+// gmlContext holds the properties that nested elements inherit from their ancestors.
+type gmlContext struct {
+	swap bool
+	dims int
+}
+
+type gmlDecoder struct {
+	decoder *xml.Decoder
+}
+
+func (d *gmlDecoder) expectName(start xml.StartElement, local string) error {
+	if start.Name.Space != GMLNamespace || start.Name.Local != local {
+		return fmt.Errorf("expected gml:%s element, found %s", local, formatXMLName(start.Name))
+	}
+	return nil
+}
+
+func formatXMLName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return fmt.Sprintf("{%s}%s", name.Space, name.Local)
+}
+
+func (d *gmlDecoder) context(start xml.StartElement, parent gmlContext) (gmlContext, error) {
+	ctx := parent
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "srsName":
+			swap, err := gmlAxisOrder(attr.Value)
+			if err != nil {
+				return ctx, err
+			}
+			ctx.swap = swap
+		case "srsDimension":
+			dims, err := strconv.Atoi(attr.Value)
+			if err != nil || (dims != 2 && dims != 3) {
+				return ctx, fmt.Errorf("unsupported srsDimension %q", attr.Value)
+			}
+			ctx.dims = dims
+		}
+	}
+	return ctx, nil
+}
+
+// children calls the visit function with the start of each child element.  The visit
+// function must consume the child element.
+func (d *gmlDecoder) children(visit func(xml.StartElement) error) error {
+	for {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != GMLNamespace {
+				return fmt.Errorf("unexpected %s element", formatXMLName(t.Name))
+			}
+			if err := visit(t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// skipMetadata skips the standard GML metadata properties and returns an error for any
+// other element.
+func (d *gmlDecoder) skipMetadata(start xml.StartElement) error {
+	switch start.Name.Local {
+	case "name", "description", "descriptionReference", "identifier", "metaDataProperty":
+		return d.decoder.Skip()
+	}
+	return fmt.Errorf("unexpected gml:%s element", start.Name.Local)
+}
+
+func (d *gmlDecoder) text() (string, error) {
+	builder := &strings.Builder{}
+	for {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			builder.Write(t)
+		case xml.StartElement:
+			return "", fmt.Errorf("unexpected %s element in text", formatXMLName(t.Name))
+		case xml.EndElement:
+			return builder.String(), nil
+		}
+	}
+}
+
+func (d *gmlDecoder) values(start xml.StartElement, ctx gmlContext) ([][]float64, error) {
+	ctx, err := d.context(start, ctx)
+	if err != nil {
+		return nil, err
+	}
+	text, err := d.text()
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(text)
+	if len(fields)%ctx.dims != 0 {
+		return nil, fmt.Errorf("expected a multiple of %d values in gml:%s, found %d", ctx.dims, start.Name.Local, len(fields))
+	}
+
+	positions := make([][]float64, 0, len(fields)/ctx.dims)
+	for i := 0; i < len(fields); i += ctx.dims {
+		position := make([]float64, ctx.dims)
+		for j := range position {
+			v, err := strconv.ParseFloat(fields[i+j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q in gml:%s", fields[i+j], start.Name.Local)
+			}
+			position[j] = v
+		}
+		if ctx.swap {
+			position[0], position[1] = position[1], position[0]
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+func (d *gmlDecoder) position(start xml.StartElement, ctx gmlContext) ([]float64, error) {
+	positions, err := d.values(start, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(positions) != 1 {
+		return nil, fmt.Errorf("expected a single position in gml:%s, found %d", start.Name.Local, len(positions))
+	}
+	return positions[0], nil
+}
+
+// positions decodes a gml:posList or a sequence of gml:pos elements from the children of
+// the current element.
+func (d *gmlDecoder) positions(ctx gmlContext) ([][]float64, error) {
+	var positions [][]float64
+	err := d.children(func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "posList":
+			if positions != nil {
+				return errors.New("unexpected gml:posList element after positions")
+			}
+			list, err := d.values(child, ctx)
+			positions = list
+			return err
+		case "pos":
+			position, err := d.position(child, ctx)
+			positions = append(positions, position)
+			return err
+		}
+		return d.skipMetadata(child)
+	})
+	return positions, err
+}
+
+func (d *gmlDecoder) polygon(ctx gmlContext) ([][][]float64, error) {
+	rings := [][][]float64{}
+	err := d.children(func(child xml.StartElement) error {
+		switch child.Name.Local {
+		case "exterior", "interior":
+			if (child.Name.Local == "exterior") != (len(rings) == 0) {
+				return errors.New("expected a single gml:exterior before any gml:interior")
+			}
+			return d.children(func(ring xml.StartElement) error {
+				if ring.Name.Local != "LinearRing" {
+					return fmt.Errorf("unsupported polygon ring gml:%s", ring.Name.Local)
+				}
+				ringCtx, err := d.context(ring, ctx)
+				if err != nil {
+					return err
+				}
+				positions, err := d.positions(ringCtx)
+				rings = append(rings, positions)
+				return err
+			})
+		}
+		return d.skipMetadata(child)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rings) == 0 {
+		return nil, errors.New("expected gml:exterior in polygon")
+	}
+	return rings, nil
+}
+
+// members decodes the geometries in the member properties of a multi-geometry.
+func (d *gmlDecoder) members(ctx gmlContext, member string, expectedType string) ([]Geometry, error) {
+	var geometries []Geometry
+	err := d.children(func(child xml.StartElement) error {
+		if child.Name.Local != member && child.Name.Local != member+"s" {
+			return d.skipMetadata(child)
+		}
+		return d.children(func(item xml.StartElement) error {
+			g, err := d.geometry(item, ctx)
+			if err != nil {
+				return err
+			}
+			if expectedType != "" && g.Type() != expectedType {
+				return fmt.Errorf("unexpected gml:%s in gml:%s", item.Name.Local, member)
+			}
+			geometries = append(geometries, g)
+			return nil
+		})
+	})
+	return geometries, err
+}
+
+func (d *gmlDecoder) geometry(start xml.StartElement, parent gmlContext) (Geometry, error) {
+	if start.Name.Space != GMLNamespace {
+		return nil, fmt.Errorf("expected a GML element, found %s", formatXMLName(start.Name))
+	}
+	ctx, err := d.context(start, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch start.Name.Local {
+	case "Point":
+		positions, err := d.positions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(positions) != 1 {
+			return nil, fmt.Errorf("expected a single position in gml:Point, found %d", len(positions))
+		}
+		return &Point{Coordinates: positions[0]}, nil
+
+	case "LineString":
+		positions, err := d.positions(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &LineString{Coordinates: positions}, nil
+
+	case "Polygon":
+		rings, err := d.polygon(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &Polygon{Coordinates: rings}, nil
+
+	case "MultiPoint":
+		members, err := d.members(ctx, "pointMember", TypePoint)
+		if err != nil {
+			return nil, err
+		}
+		positions := make([][]float64, len(members))
+		for i, member := range members {
+			positions[i] = member.(*Point).Coordinates
+		}
+		return &MultiPoint{Coordinates: positions}, nil
+
+	case "MultiCurve":
+		members, err := d.members(ctx, "curveMember", TypeLineString)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([][][]float64, len(members))
+		for i, member := range members {
+			lines[i] = member.(*LineString).Coordinates
+		}
+		return &MultiLineString{Coordinates: lines}, nil
+
+	case "MultiSurface":
+		members, err := d.members(ctx, "surfaceMember", TypePolygon)
+		if err != nil {
+			return nil, err
+		}
+		polygons := make([][][][]float64, len(members))
+		for i, member := range members {
+			polygons[i] = member.(*Polygon).Coordinates
+		}
+		return &MultiPolygon{Coordinates: polygons}, nil
+
+	case "MultiGeometry":
+		members, err := d.members(ctx, "geometryMember", "")
+		if err != nil {
+			return nil, err
+		}
+		if members == nil {
+			members = []Geometry{}
+		}
+		return &GeometryCollection{Geometries: members}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported GML geometry gml:%s", start.Name.Local)
+}
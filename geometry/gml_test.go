@@ -0,0 +1,262 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gmlRoot = `xmlns:gml="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/OGC/1.3/CRS84"`
+
+func TestGML(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry geometry.Geometry
+		gml      string
+	}{
+		{
+			name:     "point",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2.5}},
+			gml:      `<gml:Point ` + gmlRoot + `><gml:pos>1 2.5</gml:pos></gml:Point>`,
+		},
+		{
+			name:     "point z",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+			gml:      `<gml:Point ` + gmlRoot + ` srsDimension="3"><gml:pos>1 2 3</gml:pos></gml:Point>`,
+		},
+		{
+			name:     "line string",
+			geometry: &geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+			gml:      `<gml:LineString ` + gmlRoot + `><gml:posList>0 0 1 1</gml:posList></gml:LineString>`,
+		},
+		{
+			name: "polygon with hole",
+			geometry: &geometry.Polygon{Coordinates: [][][]float64{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 0}},
+				{{1, 1}, {2, 1}, {2, 2}, {1, 1}},
+			}},
+			gml: `<gml:Polygon ` + gmlRoot + `>` +
+				`<gml:exterior><gml:LinearRing><gml:posList>0 0 10 0 10 10 0 0</gml:posList></gml:LinearRing></gml:exterior>` +
+				`<gml:interior><gml:LinearRing><gml:posList>1 1 2 1 2 2 1 1</gml:posList></gml:LinearRing></gml:interior>` +
+				`</gml:Polygon>`,
+		},
+		{
+			name:     "multipoint",
+			geometry: &geometry.MultiPoint{Coordinates: [][]float64{{1, 2}, {3, 4}}},
+			gml: `<gml:MultiPoint ` + gmlRoot + `>` +
+				`<gml:pointMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:pointMember>` +
+				`<gml:pointMember><gml:Point><gml:pos>3 4</gml:pos></gml:Point></gml:pointMember>` +
+				`</gml:MultiPoint>`,
+		},
+		{
+			name:     "multilinestring",
+			geometry: &geometry.MultiLineString{Coordinates: [][][]float64{{{0, 0}, {1, 1}}}},
+			gml: `<gml:MultiCurve ` + gmlRoot + `>` +
+				`<gml:curveMember><gml:LineString><gml:posList>0 0 1 1</gml:posList></gml:LineString></gml:curveMember>` +
+				`</gml:MultiCurve>`,
+		},
+		{
+			name:     "multipolygon",
+			geometry: &geometry.MultiPolygon{Coordinates: [][][][]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+			gml: `<gml:MultiSurface ` + gmlRoot + `>` +
+				`<gml:surfaceMember><gml:Polygon><gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 0</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon></gml:surfaceMember>` +
+				`</gml:MultiSurface>`,
+		},
+		{
+			name: "geometry collection",
+			geometry: &geometry.GeometryCollection{Geometries: []geometry.Geometry{
+				&geometry.Point{Coordinates: []float64{1, 2}},
+				&geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+			}},
+			gml: `<gml:MultiGeometry ` + gmlRoot + `>` +
+				`<gml:geometryMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:geometryMember>` +
+				`<gml:geometryMember><gml:LineString><gml:posList>0 0 1 1</gml:posList></gml:LineString></gml:geometryMember>` +
+				`</gml:MultiGeometry>`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gml, err := geometry.MarshalGML(tc.geometry)
+			require.NoError(t, err)
+			assert.Equal(t, tc.gml, gml)
+
+			decoded, err := geometry.UnmarshalGML([]byte(tc.gml))
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+		})
+	}
+}
+
+func TestMarshalGMLMeasures(t *testing.T) {
+	gml, err := geometry.MarshalGML(&geometry.Point{Coordinates: []float64{1, 2, 3}, Layout: geometry.LayoutXYM})
+	require.NoError(t, err)
+	assert.Equal(t, `<gml:Point `+gmlRoot+`><gml:pos>1 2</gml:pos></gml:Point>`, gml)
+}
+
+func TestUnmarshalGMLVariants(t *testing.T) {
+	cases := []struct {
+		name     string
+		gml      string
+		geometry geometry.Geometry
+	}{
+		{
+			name:     "no srsName",
+			gml:      `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 2</gml:pos></gml:Point>`,
+			geometry: &geometry.Point{Coordinates: []float64{1, 2}},
+		},
+		{
+			name:     "latitude first urn",
+			gml:      `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326"><gml:pos>40 -120</gml:pos></gml:Point>`,
+			geometry: &geometry.Point{Coordinates: []float64{-120, 40}},
+		},
+		{
+			name: "latitude first uri with metadata",
+			gml: `<g:LineString xmlns:g="http://www.opengis.net/gml/3.2" srsName="http://www.opengis.net/def/crs/EPSG/0/4326" g:id="l1">
+				<g:description>a line</g:description>
+				<g:posList>40 -120 41 -121</g:posList>
+			</g:LineString>`,
+			geometry: &geometry.LineString{Coordinates: [][]float64{{-120, 40}, {-121, 41}}},
+		},
+		{
+			name: "pos elements",
+			gml: `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:pos>0 0</gml:pos>
+				<gml:pos>1 1</gml:pos>
+			</gml:LineString>`,
+			geometry: &geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+		},
+		{
+			name: "point members",
+			gml: `<gml:MultiPoint xmlns:gml="http://www.opengis.net/gml/3.2">
+				<gml:pointMembers>
+					<gml:Point><gml:pos>1 2</gml:pos></gml:Point>
+					<gml:Point><gml:pos>3 4</gml:pos></gml:Point>
+				</gml:pointMembers>
+			</gml:MultiPoint>`,
+			geometry: &geometry.MultiPoint{Coordinates: [][]float64{{1, 2}, {3, 4}}},
+		},
+		{
+			name:     "dimension on posList",
+			gml:      `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2"><gml:posList srsDimension="3">0 0 0 1 1 1</gml:posList></gml:LineString>`,
+			geometry: &geometry.LineString{Coordinates: [][]float64{{0, 0, 0}, {1, 1, 1}}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := geometry.UnmarshalGML([]byte(tc.gml))
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+		})
+	}
+}
+
+func TestUnmarshalGMLErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		gml  string
+		err  string
+	}{
+		{
+			name: "wrong namespace",
+			gml:  `<gml:Point xmlns:gml="http://www.opengis.net/gml"><gml:pos>1 2</gml:pos></gml:Point>`,
+			err:  "expected a GML element, found {http://www.opengis.net/gml}Point",
+		},
+		{
+			name: "unsupported type",
+			gml:  `<gml:Curve xmlns:gml="http://www.opengis.net/gml/3.2"/>`,
+			err:  "unsupported GML geometry gml:Curve",
+		},
+		{
+			name: "unsupported crs",
+			gml:  `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2" srsName="EPSG:3857"><gml:pos>1 2</gml:pos></gml:Point>`,
+			err:  `unsupported srsName "EPSG:3857"`,
+		},
+		{
+			name: "odd number of values",
+			gml:  `<gml:LineString xmlns:gml="http://www.opengis.net/gml/3.2"><gml:posList>0 0 1</gml:posList></gml:LineString>`,
+			err:  "expected a multiple of 2 values in gml:posList, found 3",
+		},
+		{
+			name: "bad number",
+			gml:  `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:pos>1 x</gml:pos></gml:Point>`,
+			err:  `invalid value "x" in gml:pos`,
+		},
+		{
+			name: "unexpected element",
+			gml:  `<gml:Point xmlns:gml="http://www.opengis.net/gml/3.2"><gml:coordinates>1,2</gml:coordinates></gml:Point>`,
+			err:  "unexpected gml:coordinates element",
+		},
+		{
+			name: "unclosed ring",
+			gml:  `<gml:Polygon xmlns:gml="http://www.opengis.net/gml/3.2"><gml:exterior><gml:LinearRing><gml:posList>0 0 1 0 1 1 0 1</gml:posList></gml:LinearRing></gml:exterior></gml:Polygon>`,
+			err:  "invalid polygon ring 0: ring is not closed",
+		},
+		{
+			name: "wrong member type",
+			gml:  `<gml:MultiCurve xmlns:gml="http://www.opengis.net/gml/3.2"><gml:curveMember><gml:Point><gml:pos>1 2</gml:pos></gml:Point></gml:curveMember></gml:MultiCurve>`,
+			err:  "unexpected gml:Point in gml:curveMember",
+		},
+		{
+			name: "empty document",
+			gml:  ``,
+			err:  "missing GML element",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := geometry.UnmarshalGML([]byte(tc.gml))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestGMLEnvelope(t *testing.T) {
+	gml, err := geometry.MarshalGMLEnvelope([]float64{-10, -5, 10, 5})
+	require.NoError(t, err)
+	assert.Equal(t, `<gml:Envelope `+gmlRoot+`><gml:lowerCorner>-10 -5</gml:lowerCorner><gml:upperCorner>10 5</gml:upperCorner></gml:Envelope>`, gml)
+
+	decodeEnvelope := func(data string) ([]float64, error) {
+		decoder := xml.NewDecoder(bytes.NewReader([]byte(data)))
+		token, err := decoder.Token()
+		require.NoError(t, err)
+		return geometry.DecodeGMLEnvelope(decoder, token.(xml.StartElement))
+	}
+
+	bounds, err := decodeEnvelope(gml)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-10, -5, 10, 5}, bounds)
+
+	bounds, err = decodeEnvelope(`<gml:Envelope xmlns:gml="http://www.opengis.net/gml/3.2" srsName="urn:ogc:def:crs:EPSG::4326">
+		<gml:lowerCorner>-5 -10</gml:lowerCorner>
+		<gml:upperCorner>5 10</gml:upperCorner>
+	</gml:Envelope>`)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-10, -5, 10, 5}, bounds)
+
+	_, err = decodeEnvelope(`<gml:Envelope xmlns:gml="http://www.opengis.net/gml/3.2"><gml:lowerCorner>0 0</gml:lowerCorner></gml:Envelope>`)
+	assert.EqualError(t, err, "expected lowerCorner and upperCorner in envelope")
+
+	_, err = geometry.MarshalGMLEnvelope([]float64{1, 2, 3})
+	assert.EqualError(t, err, "expected 4 or 6 values in bounds, found 3")
+}
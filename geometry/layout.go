@@ -0,0 +1,213 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import "fmt"
+
+// Layout describes the values in each position of a geometry.
+//
+// The zero value (LayoutDefault) means the layout is inferred from the number of values
+// in a position: two for XY, three for XYZ, and four for XYZM.  Positions with a measure
+// but no elevation must use LayoutXYM.
+type Layout int
+
+const (
+	LayoutDefault Layout = iota
+	LayoutXY
+	LayoutXYZ
+	LayoutXYM
+	LayoutXYZM
+)
+
+// Stride returns the number of values in each position, or zero for the default layout.
+func (l Layout) Stride() int {
+	switch l {
+	case LayoutXY:
+		return 2
+	case LayoutXYZ, LayoutXYM:
+		return 3
+	case LayoutXYZM:
+		return 4
+	}
+	return 0
+}
+
+// HasZ reports whether positions include an elevation.
+func (l Layout) HasZ() bool {
+	return l == LayoutXYZ || l == LayoutXYZM
+}
+
+// HasM reports whether positions include a measure.
+func (l Layout) HasM() bool {
+	return l == LayoutXYM || l == LayoutXYZM
+}
+
+func (l Layout) String() string {
+	switch l {
+	case LayoutXY:
+		return "XY"
+	case LayoutXYZ:
+		return "XYZ"
+	case LayoutXYM:
+		return "XYM"
+	case LayoutXYZM:
+		return "XYZM"
+	}
+	return "default"
+}
+
+func layoutForStride(stride int) (Layout, error) {
+	switch stride {
+	case 2:
+		return LayoutXY, nil
+	case 3:
+		return LayoutXYZ, nil
+	case 4:
+		return LayoutXYZM, nil
+	}
+	return LayoutDefault, fmt.Errorf("unsupported number of values in position: %d", stride)
+}
+
+// spatialDims returns the number of leading position values that are spatial coordinates.
+func (l Layout) spatialDims() int {
+	switch l {
+	case LayoutXY, LayoutXYM:
+		return 2
+	}
+	return 3
+}
+
+func layoutOf(g Geometry) Layout {
+	switch t := g.(type) {
+	case *Point:
+		return t.Layout
+	case *LineString:
+		return t.Layout
+	case *Polygon:
+		return t.Layout
+	case *MultiPoint:
+		return t.Layout
+	case *MultiLineString:
+		return t.Layout
+	case *MultiPolygon:
+		return t.Layout
+	}
+	return LayoutDefault
+}
+
+func setLayout(g Geometry, layout Layout) {
+	switch t := g.(type) {
+	case *Point:
+		t.Layout = layout
+	case *LineString:
+		t.Layout = layout
+	case *Polygon:
+		t.Layout = layout
+	case *MultiPoint:
+		t.Layout = layout
+	case *MultiLineString:
+		t.Layout = layout
+	case *MultiPolygon:
+		t.Layout = layout
+	}
+}
+
+// firstPosition returns the first position in a geometry or nil if it is empty.
+func firstPosition(g Geometry) []float64 {
+	switch t := g.(type) {
+	case *Point:
+		if len(t.Coordinates) > 0 {
+			return t.Coordinates
+		}
+	case *LineString:
+		if len(t.Coordinates) > 0 {
+			return t.Coordinates[0]
+		}
+	case *MultiPoint:
+		if len(t.Coordinates) > 0 {
+			return t.Coordinates[0]
+		}
+	case *Polygon:
+		for _, ring := range t.Coordinates {
+			if len(ring) > 0 {
+				return ring[0]
+			}
+		}
+	case *MultiLineString:
+		for _, line := range t.Coordinates {
+			if len(line) > 0 {
+				return line[0]
+			}
+		}
+	case *MultiPolygon:
+		for _, polygon := range t.Coordinates {
+			for _, ring := range polygon {
+				if len(ring) > 0 {
+					return ring[0]
+				}
+			}
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			if position := firstPosition(child); position != nil {
+				return position
+			}
+		}
+	}
+	return nil
+}
+
+// resolveLayout returns the explicit layout of a geometry or infers one from its first position.
+// Empty geometries with the default layout are treated as XY.
+func resolveLayout(g Geometry) (Layout, error) {
+	if layout := layoutOf(g); layout != LayoutDefault {
+		return layout, nil
+	}
+	position := firstPosition(g)
+	if position == nil {
+		return LayoutXY, nil
+	}
+	return layoutForStride(len(position))
+}
+
+// dropMeasures returns a copy of the coordinates with only the first two values in each position.
+func dropMeasures(coordinates any) any {
+	switch c := coordinates.(type) {
+	case []float64:
+		if len(c) > 2 {
+			return c[:2]
+		}
+		return c
+	case [][]float64:
+		result := make([][]float64, len(c))
+		for i, v := range c {
+			result[i] = dropMeasures(v).([]float64)
+		}
+		return result
+	case [][][]float64:
+		result := make([][][]float64, len(c))
+		for i, v := range c {
+			result[i] = dropMeasures(v).([][]float64)
+		}
+		return result
+	case [][][][]float64:
+		result := make([][][][]float64, len(c))
+		for i, v := range c {
+			result[i] = dropMeasures(v).([][][]float64)
+		}
+		return result
+	}
+	return coordinates
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LineString is a line made up of two or more positions.  A line string with no positions is empty.
+type LineString struct {
+	Coordinates [][]float64
+	Layout      Layout
+}
+
+var (
+	_ Geometry         = (*LineString)(nil)
+	_ json.Unmarshaler = (*LineString)(nil)
+)
+
+func (*LineString) geometry() {}
+
+func (*LineString) Type() string {
+	return TypeLineString
+}
+
+func (g *LineString) Bounds() []float64 {
+	e := newExtent(g.Layout)
+	e.addPositions(g.Coordinates)
+	return e.bounds()
+}
+
+func (g *LineString) Validate() error {
+	if err := validateLine(g.Coordinates, g.Layout); err != nil {
+		return fmt.Errorf("invalid linestring: %w", err)
+	}
+	return nil
+}
+
+func (g *LineString) MarshalJSON() ([]byte, error) {
+	return marshalCoordinates(TypeLineString, g.Layout, g.Coordinates)
+}
+
+func (g *LineString) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypeLineString)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*LineString)
+	return nil
+}
+
+// MultiLineString is a collection of lines.
+type MultiLineString struct {
+	Coordinates [][][]float64
+	Layout      Layout
+}
+
+var (
+	_ Geometry         = (*MultiLineString)(nil)
+	_ json.Unmarshaler = (*MultiLineString)(nil)
+)
+
+func (*MultiLineString) geometry() {}
+
+func (*MultiLineString) Type() string {
+	return TypeMultiLineString
+}
+
+func (g *MultiLineString) Bounds() []float64 {
+	e := newExtent(g.Layout)
+	for _, line := range g.Coordinates {
+		e.addPositions(line)
+	}
+	return e.bounds()
+}
+
+func (g *MultiLineString) Validate() error {
+	for i, line := range g.Coordinates {
+		if err := validateLine(line, g.Layout); err != nil {
+			return fmt.Errorf("invalid line %d in multilinestring: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (g *MultiLineString) MarshalJSON() ([]byte, error) {
+	return marshalCoordinates(TypeMultiLineString, g.Layout, g.Coordinates)
+}
+
+func (g *MultiLineString) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypeMultiLineString)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*MultiLineString)
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Point is a single position.  A point with nil coordinates is empty.
+type Point struct {
+	Coordinates []float64
+	Layout      Layout
+}
+
+var (
+	_ Geometry         = (*Point)(nil)
+	_ json.Unmarshaler = (*Point)(nil)
+)
+
+func (*Point) geometry() {}
+
+func (*Point) Type() string {
+	return TypePoint
+}
+
+func (g *Point) Bounds() []float64 {
+	e := newExtent(g.Layout)
+	if len(g.Coordinates) > 0 {
+		e.addPosition(g.Coordinates)
+	}
+	return e.bounds()
+}
+
+func (g *Point) Validate() error {
+	if len(g.Coordinates) == 0 {
+		return nil
+	}
+	return validatePosition(g.Coordinates, g.Layout)
+}
+
+func (g *Point) MarshalJSON() ([]byte, error) {
+	return marshalCoordinates(TypePoint, g.Layout, g.Coordinates)
+}
+
+func (g *Point) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypePoint)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*Point)
+	return nil
+}
+
+// MultiPoint is a collection of positions.
+type MultiPoint struct {
+	Coordinates [][]float64
+	Layout      Layout
+}
+
+var (
+	_ Geometry         = (*MultiPoint)(nil)
+	_ json.Unmarshaler = (*MultiPoint)(nil)
+)
+
+func (*MultiPoint) geometry() {}
+
+func (*MultiPoint) Type() string {
+	return TypeMultiPoint
+}
+
+func (g *MultiPoint) Bounds() []float64 {
+	e := newExtent(g.Layout)
+	e.addPositions(g.Coordinates)
+	return e.bounds()
+}
+
+func (g *MultiPoint) Validate() error {
+	if err := validatePositions(g.Coordinates, g.Layout); err != nil {
+		return fmt.Errorf("invalid multipoint: %w", err)
+	}
+	return nil
+}
+
+func (g *MultiPoint) MarshalJSON() ([]byte, error) {
+	return marshalCoordinates(TypeMultiPoint, g.Layout, g.Coordinates)
+}
+
+func (g *MultiPoint) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypeMultiPoint)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*MultiPoint)
+	return nil
+}
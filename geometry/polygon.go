@@ -0,0 +1,112 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Polygon is made up of an exterior ring followed by any number of interior rings.
+// Each ring must have at least four positions and the first and last positions must be equal.
+type Polygon struct {
+	Coordinates [][][]float64
+	Layout      Layout
+}
+
+var (
+	_ Geometry         = (*Polygon)(nil)
+	_ json.Unmarshaler = (*Polygon)(nil)
+)
+
+func (*Polygon) geometry() {}
+
+func (*Polygon) Type() string {
+	return TypePolygon
+}
+
+func (g *Polygon) Bounds() []float64 {
+	e := newExtent(g.Layout)
+	if len(g.Coordinates) > 0 {
+		e.addPositions(g.Coordinates[0])
+	}
+	return e.bounds()
+}
+
+func (g *Polygon) Validate() error {
+	return validatePolygon(g.Coordinates, g.Layout)
+}
+
+func (g *Polygon) MarshalJSON() ([]byte, error) {
+	return marshalCoordinates(TypePolygon, g.Layout, g.Coordinates)
+}
+
+func (g *Polygon) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypePolygon)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*Polygon)
+	return nil
+}
+
+// MultiPolygon is a collection of polygons.
+type MultiPolygon struct {
+	Coordinates [][][][]float64
+	Layout      Layout
+}
+
+var (
+	_ Geometry         = (*MultiPolygon)(nil)
+	_ json.Unmarshaler = (*MultiPolygon)(nil)
+)
+
+func (*MultiPolygon) geometry() {}
+
+func (*MultiPolygon) Type() string {
+	return TypeMultiPolygon
+}
+
+func (g *MultiPolygon) Bounds() []float64 {
+	e := newExtent(g.Layout)
+	for _, polygon := range g.Coordinates {
+		if len(polygon) > 0 {
+			e.addPositions(polygon[0])
+		}
+	}
+	return e.bounds()
+}
+
+func (g *MultiPolygon) Validate() error {
+	for i, polygon := range g.Coordinates {
+		if err := validatePolygon(polygon, g.Layout); err != nil {
+			return fmt.Errorf("invalid polygon %d in multipolygon: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (g *MultiPolygon) MarshalJSON() ([]byte, error) {
+	return marshalCoordinates(TypeMultiPolygon, g.Layout, g.Coordinates)
+}
+
+func (g *MultiPolygon) UnmarshalJSON(data []byte) error {
+	decoded, err := unmarshalTyped(data, TypeMultiPolygon)
+	if err != nil {
+		return err
+	}
+	*g = *decoded.(*MultiPolygon)
+	return nil
+}
@@ -0,0 +1,671 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"math"
+	"sort"
+)
+
+// The functions below implement the OGC Simple Features spatial predicates for planar
+// geometries.  Only the first two values of each position are considered.  Geometry
+// collections are treated as the union of their members, and an empty geometry intersects
+// nothing.
+
+// Intersects reports whether the geometries have at least one point in common.
+func Intersects(a, b Geometry) bool {
+	return intersects(newRelateGeometry(a), newRelateGeometry(b))
+}
+
+// Disjoint reports whether the geometries have no points in common.
+func Disjoint(a, b Geometry) bool {
+	return !Intersects(a, b)
+}
+
+// Contains reports whether no point of b is outside a and the interiors of the geometries
+// intersect.
+func Contains(a, b Geometry) bool {
+	ra, rb := newRelateGeometry(a), newRelateGeometry(b)
+	return covers(ra, rb) && interiorDimension(ra, rb) >= 0
+}
+
+// Within reports whether a is contained by b.
+func Within(a, b Geometry) bool {
+	return Contains(b, a)
+}
+
+// Equals reports whether the geometries are topologically equal.
+func Equals(a, b Geometry) bool {
+	ra, rb := newRelateGeometry(a), newRelateGeometry(b)
+	if ra.empty() || rb.empty() {
+		return ra.empty() && rb.empty()
+	}
+	return covers(ra, rb) && covers(rb, ra)
+}
+
+// Touches reports whether the geometries have at least one point in common but their
+// interiors do not intersect.
+func Touches(a, b Geometry) bool {
+	ra, rb := newRelateGeometry(a), newRelateGeometry(b)
+	if ra.dimension() == 0 && rb.dimension() == 0 {
+		return false
+	}
+	return intersects(ra, rb) && interiorDimension(ra, rb) < 0
+}
+
+// Crosses reports whether the interiors of the geometries intersect in a way that is of
+// lower dimension than the higher dimension geometry.  For a point or line and a higher
+// dimension geometry, some of the lower dimension geometry must be outside the other.  For
+// two lines, the interiors must intersect only at points.
+func Crosses(a, b Geometry) bool {
+	ra, rb := newRelateGeometry(a), newRelateGeometry(b)
+	da, db := ra.dimension(), rb.dimension()
+	switch {
+	case da < 0 || db < 0:
+		return false
+	case da < db:
+		return interiorDimension(ra, rb) >= 0 && !covers(rb, ra)
+	case da > db:
+		return interiorDimension(ra, rb) >= 0 && !covers(ra, rb)
+	case da == 1:
+		return interiorDimension(ra, rb) == 0
+	}
+	return false
+}
+
+// Overlaps reports whether geometries of the same dimension have interiors that intersect
+// with the same dimension, and each geometry has points outside the other.
+func Overlaps(a, b Geometry) bool {
+	ra, rb := newRelateGeometry(a), newRelateGeometry(b)
+	da := ra.dimension()
+	if da < 0 || da != rb.dimension() {
+		return false
+	}
+	return interiorDimension(ra, rb) == da && !covers(ra, rb) && !covers(rb, ra)
+}
+
+type vec struct {
+	x float64
+	y float64
+}
+
+func (v vec) sub(o vec) vec {
+	return vec{v.x - o.x, v.y - o.y}
+}
+
+func (v vec) dot(o vec) float64 {
+	return v.x*o.x + v.y*o.y
+}
+
+func (v vec) lerp(o vec, t float64) vec {
+	return vec{v.x + (o.x-v.x)*t, v.y + (o.y-v.y)*t}
+}
+
+type segment struct {
+	a vec
+	b vec
+}
+
+// relateGeometry is a geometry decomposed into points, lines, and polygons.
+type relateGeometry struct {
+	points   []vec
+	lines    [][]vec
+	polygons [][][]vec
+
+	// boundary holds the line end points that are on the boundary (by the mod-2 rule)
+	boundary []vec
+}
+
+func newRelateGeometry(g Geometry) *relateGeometry {
+	r := &relateGeometry{}
+	r.add(g)
+
+	counts := map[vec]int{}
+	for _, line := range r.lines {
+		if len(line) < 2 || line[0] == line[len(line)-1] {
+			continue
+		}
+		counts[line[0]] += 1
+		counts[line[len(line)-1]] += 1
+	}
+	for point, count := range counts {
+		if count%2 == 1 {
+			r.boundary = append(r.boundary, point)
+		}
+	}
+	return r
+}
+
+func toVec(position []float64) vec {
+	return vec{position[0], position[1]}
+}
+
+func toVecs(positions [][]float64) []vec {
+	vecs := make([]vec, len(positions))
+	for i, position := range positions {
+		vecs[i] = toVec(position)
+	}
+	return vecs
+}
+
+func (r *relateGeometry) addPolygon(rings [][][]float64) {
+	if len(rings) == 0 || len(rings[0]) == 0 {
+		return
+	}
+	polygon := make([][]vec, 0, len(rings))
+	for _, ring := range rings {
+		if len(ring) > 0 {
+			polygon = append(polygon, toVecs(ring))
+		}
+	}
+	r.polygons = append(r.polygons, polygon)
+}
+
+func (r *relateGeometry) add(g Geometry) {
+	switch t := g.(type) {
+	case *Point:
+		if len(t.Coordinates) > 0 {
+			r.points = append(r.points, toVec(t.Coordinates))
+		}
+	case *MultiPoint:
+		for _, position := range t.Coordinates {
+			r.points = append(r.points, toVec(position))
+		}
+	case *LineString:
+		if len(t.Coordinates) > 0 {
+			r.lines = append(r.lines, toVecs(t.Coordinates))
+		}
+	case *MultiLineString:
+		for _, line := range t.Coordinates {
+			if len(line) > 0 {
+				r.lines = append(r.lines, toVecs(line))
+			}
+		}
+	case *Polygon:
+		r.addPolygon(t.Coordinates)
+	case *MultiPolygon:
+		for _, polygon := range t.Coordinates {
+			r.addPolygon(polygon)
+		}
+	case *GeometryCollection:
+		for _, child := range t.Geometries {
+			r.add(child)
+		}
+	}
+}
+
+func (r *relateGeometry) empty() bool {
+	return r.dimension() < 0
+}
+
+func (r *relateGeometry) dimension() int {
+	switch {
+	case len(r.polygons) > 0:
+		return 2
+	case len(r.lines) > 0:
+		return 1
+	case len(r.points) > 0:
+		return 0
+	}
+	return -1
+}
+
+func (r *relateGeometry) lineSegments() []segment {
+	segments := []segment{}
+	for _, line := range r.lines {
+		segments = appendSegments(segments, line)
+	}
+	return segments
+}
+
+func (r *relateGeometry) ringSegments() []segment {
+	segments := []segment{}
+	for _, polygon := range r.polygons {
+		for _, ring := range polygon {
+			segments = appendSegments(segments, ring)
+		}
+	}
+	return segments
+}
+
+func appendSegments(segments []segment, positions []vec) []segment {
+	if len(positions) == 1 {
+		return append(segments, segment{positions[0], positions[0]})
+	}
+	for i := 1; i < len(positions); i++ {
+		segments = append(segments, segment{positions[i-1], positions[i]})
+	}
+	return segments
+}
+
+// location is the position of a point relative to part of a geometry.
+type location int
+
+const (
+	exterior location = iota
+	boundary
+	interior
+)
+
+// tolerance returns the distance within which points are considered to be on a segment.
+func tolerance(s segment) float64 {
+	scale := math.Max(math.Max(math.Abs(s.a.x), math.Abs(s.a.y)), math.Max(math.Abs(s.b.x), math.Abs(s.b.y)))
+	return 1e-9 * math.Max(1, scale)
+}
+
+func onSegment(p vec, s segment) bool {
+	d := s.b.sub(s.a)
+	length := d.dot(d)
+	if length == 0 {
+		return distance(p, s.a) <= tolerance(s)
+	}
+	t := p.sub(s.a).dot(d) / length
+	if t < 0 || t > 1 {
+		return distance(p, s.a) <= tolerance(s) || distance(p, s.b) <= tolerance(s)
+	}
+	return distance(p, s.a.lerp(s.b, t)) <= tolerance(s)
+}
+
+func distance(a, b vec) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+func (r *relateGeometry) locatePoints(p vec) location {
+	for _, point := range r.points {
+		if distance(p, point) <= tolerance(segment{point, point}) {
+			return interior
+		}
+	}
+	return exterior
+}
+
+func (r *relateGeometry) locateLines(p vec) location {
+	for _, s := range r.lineSegments() {
+		if onSegment(p, s) {
+			for _, b := range r.boundary {
+				if distance(p, b) <= tolerance(segment{b, b}) {
+					return boundary
+				}
+			}
+			return interior
+		}
+	}
+	return exterior
+}
+
+func (r *relateGeometry) locatePolygons(p vec) location {
+	result := exterior
+	for _, polygon := range r.polygons {
+		switch locatePolygon(p, polygon) {
+		case interior:
+			return interior
+		case boundary:
+			result = boundary
+		}
+	}
+	return result
+}
+
+// locate returns the location of a point relative to the whole geometry.
+func (r *relateGeometry) locate(p vec) location {
+	return max(r.locatePoints(p), r.locateLines(p), r.locatePolygons(p))
+}
+
+func locatePolygon(p vec, rings [][]vec) location {
+	inside := false
+	for _, ring := range rings {
+		for i := 1; i < len(ring); i++ {
+			a, b := ring[i-1], ring[i]
+			if onSegment(p, segment{a, b}) {
+				return boundary
+			}
+			if (a.y > p.y) != (b.y > p.y) {
+				x := a.x + (p.y-a.y)*(b.x-a.x)/(b.y-a.y)
+				if p.x < x {
+					inside = !inside
+				}
+			}
+		}
+	}
+	if inside {
+		return interior
+	}
+	return exterior
+}
+
+func orient(a, b, c vec) float64 {
+	return (b.x-a.x)*(c.y-a.y) - (b.y-a.y)*(c.x-a.x)
+}
+
+// intersections returns the parameters along s where it intersects t.  Two parameters are
+// returned if the segments overlap.
+func intersections(s, t segment) []float64 {
+	d := s.b.sub(s.a)
+	length := d.dot(d)
+	if length == 0 {
+		if onSegment(s.a, t) {
+			return []float64{0}
+		}
+		return nil
+	}
+	project := func(p vec) float64 {
+		return p.sub(s.a).dot(d) / length
+	}
+
+	d1, d2 := orient(t.a, t.b, s.a), orient(t.a, t.b, s.b)
+	d3, d4 := orient(s.a, s.b, t.a), orient(s.a, s.b, t.b)
+
+	if t.a == t.b || (d3 == 0 && d4 == 0) {
+		if !onSegment(t.a, s) && !onSegment(t.b, s) && !onSegment(s.a, t) && !onSegment(s.b, t) {
+			return nil
+		}
+		low := math.Max(0, math.Min(project(t.a), project(t.b)))
+		high := math.Min(1, math.Max(project(t.a), project(t.b)))
+		if low > high {
+			return nil
+		}
+		if low == high {
+			return []float64{low}
+		}
+		return []float64{low, high}
+	}
+
+	if (d1 > 0 && d2 > 0) || (d1 < 0 && d2 < 0) || (d3 > 0 && d4 > 0) || (d3 < 0 && d4 < 0) {
+		// near misses within the tolerance are treated as touching
+		for _, p := range []vec{t.a, t.b} {
+			if onSegment(p, s) {
+				return []float64{math.Min(1, math.Max(0, project(p)))}
+			}
+		}
+		for _, p := range []vec{s.a, s.b} {
+			if onSegment(p, t) {
+				return []float64{project(p)}
+			}
+		}
+		return nil
+	}
+	return []float64{d1 / (d1 - d2)}
+}
+
+// pieces splits a segment at its intersections with other segments and returns the points
+// to test: the split points and the midpoints of the resulting pieces.
+func pieces(s segment, others []segment) (vertices []vec, midpoints []vec) {
+	params := []float64{0, 1}
+	for _, other := range others {
+		params = append(params, intersections(s, other)...)
+	}
+	sort.Float64s(params)
+
+	unique := params[:1]
+	for _, t := range params[1:] {
+		if t-unique[len(unique)-1] > 1e-12 {
+			unique = append(unique, t)
+		}
+	}
+
+	for i, t := range unique {
+		vertices = append(vertices, s.a.lerp(s.b, t))
+		if i > 0 {
+			midpoints = append(midpoints, s.a.lerp(s.b, (unique[i-1]+t)/2))
+		}
+	}
+	return vertices, midpoints
+}
+
+// interiorPoint returns a point in the interior of a polygon.
+func interiorPoint(rings [][]vec) (vec, bool) {
+	ys := []float64{}
+	for _, ring := range rings {
+		for _, p := range ring {
+			ys = append(ys, p.y)
+		}
+	}
+	sort.Float64s(ys)
+
+	// scan between distinct vertex heights, starting from the middle
+	middle := len(ys) / 2
+	for offset := 0; offset < len(ys); offset++ {
+		for _, i := range []int{middle + offset, middle - offset - 1} {
+			if i < 0 || i+1 >= len(ys) || ys[i] == ys[i+1] {
+				continue
+			}
+			y := (ys[i] + ys[i+1]) / 2
+			xs := []float64{}
+			for _, ring := range rings {
+				for j := 1; j < len(ring); j++ {
+					a, b := ring[j-1], ring[j]
+					if (a.y > y) != (b.y > y) {
+						xs = append(xs, a.x+(y-a.y)*(b.x-a.x)/(b.y-a.y))
+					}
+				}
+			}
+			sort.Float64s(xs)
+
+			best, width := vec{}, 0.0
+			for k := 0; k+1 < len(xs); k += 2 {
+				if xs[k+1]-xs[k] > width {
+					best, width = vec{(xs[k] + xs[k+1]) / 2, y}, xs[k+1]-xs[k]
+				}
+			}
+			if width > 0 {
+				return best, true
+			}
+		}
+	}
+	return vec{}, false
+}
+
+func boundsOverlap(a, b *relateGeometry) bool {
+	ba, bb := a.bounds(), b.bounds()
+	return ba[0] <= bb[2] && bb[0] <= ba[2] && ba[1] <= bb[3] && bb[1] <= ba[3]
+}
+
+func (r *relateGeometry) bounds() [4]float64 {
+	b := [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	add := func(p vec) {
+		b[0], b[1] = math.Min(b[0], p.x), math.Min(b[1], p.y)
+		b[2], b[3] = math.Max(b[2], p.x), math.Max(b[3], p.y)
+	}
+	for _, p := range r.points {
+		add(p)
+	}
+	for _, line := range r.lines {
+		for _, p := range line {
+			add(p)
+		}
+	}
+	for _, polygon := range r.polygons {
+		for _, p := range polygon[0] {
+			add(p)
+		}
+	}
+	return b
+}
+
+func (r *relateGeometry) firstVertices() []vec {
+	vertices := []vec{}
+	for _, line := range r.lines {
+		vertices = append(vertices, line[0])
+	}
+	for _, polygon := range r.polygons {
+		vertices = append(vertices, polygon[0][0])
+	}
+	return vertices
+}
+
+func intersects(a, b *relateGeometry) bool {
+	if a.empty() || b.empty() || !boundsOverlap(a, b) {
+		return false
+	}
+	for _, p := range a.points {
+		if b.locate(p) != exterior {
+			return true
+		}
+	}
+	for _, p := range b.points {
+		if a.locate(p) != exterior {
+			return true
+		}
+	}
+
+	segmentsA := append(a.lineSegments(), a.ringSegments()...)
+	segmentsB := append(b.lineSegments(), b.ringSegments()...)
+	for _, s := range segmentsA {
+		for _, t := range segmentsB {
+			if len(intersections(s, t)) > 0 {
+				return true
+			}
+		}
+	}
+
+	// with no crossing segments, a line or polygon may be entirely inside a polygon
+	for _, p := range a.firstVertices() {
+		if b.locatePolygons(p) != exterior {
+			return true
+		}
+	}
+	for _, p := range b.firstVertices() {
+		if a.locatePolygons(p) != exterior {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether every point of b is in a.
+func covers(a, b *relateGeometry) bool {
+	if a.empty() || b.empty() {
+		return false
+	}
+	for _, p := range b.points {
+		if a.locate(p) == exterior {
+			return false
+		}
+	}
+
+	segmentsA := append(a.lineSegments(), a.ringSegments()...)
+	for _, s := range append(b.lineSegments(), b.ringSegments()...) {
+		vertices, midpoints := pieces(s, segmentsA)
+		for _, p := range append(vertices, midpoints...) {
+			if a.locate(p) == exterior {
+				return false
+			}
+		}
+	}
+
+	if len(b.polygons) == 0 {
+		return true
+	}
+	if len(a.polygons) == 0 {
+		return false
+	}
+
+	// the boundary of b is covered, so b is covered unless some of the boundary of a is
+	// inside b (or b is bounded by something other than the polygons of a)
+	ringsB := b.ringSegments()
+	for _, s := range a.ringSegments() {
+		vertices, midpoints := pieces(s, ringsB)
+		for _, p := range append(vertices, midpoints...) {
+			if b.locatePolygons(p) == interior {
+				return false
+			}
+		}
+	}
+	for _, polygon := range b.polygons {
+		p, ok := interiorPoint(polygon)
+		if ok && a.locatePolygons(p) == exterior {
+			return false
+		}
+	}
+	return true
+}
+
+// interiorDimension returns the dimension of the intersection of the interiors of the
+// geometries or -1 if the interiors do not intersect.
+func interiorDimension(a, b *relateGeometry) int {
+	if a.empty() || b.empty() || !boundsOverlap(a, b) {
+		return -1
+	}
+
+	dimension := -1
+	for _, pair := range [][2]*relateGeometry{{a, b}, {b, a}} {
+		x, y := pair[0], pair[1]
+		for _, p := range x.points {
+			if y.locate(p) == interior {
+				return 0
+			}
+		}
+	}
+
+	linesA, linesB := a.lineSegments(), b.lineSegments()
+	ringsA, ringsB := a.ringSegments(), b.ringSegments()
+
+	// line and line
+	for _, s := range linesA {
+		vertices, midpoints := pieces(s, linesB)
+		for _, p := range midpoints {
+			if b.locateLines(p) == interior {
+				return 1
+			}
+		}
+		for _, p := range vertices {
+			if a.locateLines(p) == interior && b.locateLines(p) == interior {
+				dimension = 0
+			}
+		}
+	}
+
+	// line and polygon
+	for _, pair := range []struct {
+		lines []segment
+		rings []segment
+		areal *relateGeometry
+	}{{linesA, ringsB, b}, {linesB, ringsA, a}} {
+		for _, s := range pair.lines {
+			_, midpoints := pieces(s, pair.rings)
+			for _, p := range midpoints {
+				if pair.areal.locatePolygons(p) == interior {
+					dimension = 1
+				}
+			}
+		}
+	}
+	if dimension == 1 && (len(a.polygons) == 0 || len(b.polygons) == 0) {
+		return dimension
+	}
+
+	// polygon and polygon
+	for _, pair := range []struct {
+		rings  []segment
+		others []segment
+		x      *relateGeometry
+		y      *relateGeometry
+	}{{ringsA, ringsB, a, b}, {ringsB, ringsA, b, a}} {
+		for _, s := range pair.rings {
+			_, midpoints := pieces(s, pair.others)
+			for _, p := range midpoints {
+				if pair.y.locatePolygons(p) == interior {
+					return 2
+				}
+			}
+		}
+		for _, polygon := range pair.x.polygons {
+			if p, ok := interiorPoint(polygon); ok && pair.y.locatePolygons(p) == interior {
+				return 2
+			}
+		}
+	}
+	return dimension
+}
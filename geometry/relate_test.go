@@ -0,0 +1,222 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelate(t *testing.T) {
+	predicates := []struct {
+		name string
+		test func(a, b geometry.Geometry) bool
+	}{
+		{"intersects", geometry.Intersects},
+		{"disjoint", geometry.Disjoint},
+		{"contains", geometry.Contains},
+		{"within", geometry.Within},
+		{"equals", geometry.Equals},
+		{"touches", geometry.Touches},
+		{"crosses", geometry.Crosses},
+		{"overlaps", geometry.Overlaps},
+	}
+
+	cases := []struct {
+		a    string
+		b    string
+		true []string
+	}{
+		{
+			a:    "POINT (1 1)",
+			b:    "POINT (1 1)",
+			true: []string{"intersects", "contains", "within", "equals"},
+		},
+		{
+			a:    "POINT (1 1)",
+			b:    "POINT (2 1)",
+			true: []string{"disjoint"},
+		},
+		{
+			a:    "MULTIPOINT ((1 1), (2 2))",
+			b:    "MULTIPOINT ((2 2), (3 3))",
+			true: []string{"intersects", "overlaps"},
+		},
+		{
+			a:    "POINT (1 1)",
+			b:    "LINESTRING (0 0, 2 2)",
+			true: []string{"intersects", "within"},
+		},
+		{
+			a:    "POINT (0 0)",
+			b:    "LINESTRING (0 0, 2 2)",
+			true: []string{"intersects", "touches"},
+		},
+		{
+			a:    "POINT (5 5)",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			true: []string{"intersects", "within"},
+		},
+		{
+			a:    "POINT (10 5)",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			true: []string{"intersects", "touches"},
+		},
+		{
+			a:    "POINT (5 5)",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (4 4, 6 4, 6 6, 4 6, 4 4))",
+			true: []string{"disjoint"},
+		},
+		{
+			a:    "MULTIPOINT ((5 5), (20 20))",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			true: []string{"intersects", "crosses"},
+		},
+		{
+			a:    "LINESTRING (0 0, 2 2)",
+			b:    "LINESTRING (0 2, 2 0)",
+			true: []string{"intersects", "crosses"},
+		},
+		{
+			a:    "LINESTRING (0 0, 2 2)",
+			b:    "LINESTRING (2 2, 3 0)",
+			true: []string{"intersects", "touches"},
+		},
+		{
+			a:    "LINESTRING (0 0, 2 2)",
+			b:    "LINESTRING (1 1, 3 3)",
+			true: []string{"intersects", "overlaps"},
+		},
+		{
+			a:    "LINESTRING (0 0, 4 4)",
+			b:    "LINESTRING (1 1, 2 2)",
+			true: []string{"intersects", "contains"},
+		},
+		{
+			a:    "LINESTRING (0 0, 2 2)",
+			b:    "MULTILINESTRING ((0 0, 1 1), (1 1, 2 2))",
+			true: []string{"intersects", "contains", "within", "equals"},
+		},
+		{
+			a:    "LINESTRING (0 0, 2 2)",
+			b:    "LINESTRING (3 3, 4 4)",
+			true: []string{"disjoint"},
+		},
+		{
+			a:    "LINESTRING (2 2, 4 4)",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			true: []string{"intersects", "within"},
+		},
+		{
+			a:    "LINESTRING (5 5, 15 5)",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			true: []string{"intersects", "crosses"},
+		},
+		{
+			a:    "LINESTRING (0 0, 10 0)",
+			b:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			true: []string{"intersects", "touches"},
+		},
+		{
+			a:    "LINESTRING (2 2, 4 4)",
+			b:    "POLYGON ((0 0, 1 0, 1 1, 0 1, 0 0))",
+			true: []string{"disjoint"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "POLYGON ((2 2, 4 2, 4 4, 2 4, 2 2))",
+			true: []string{"intersects", "contains"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "POLYGON ((0 0, 5 0, 5 5, 0 5, 0 0))",
+			true: []string{"intersects", "contains"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "POLYGON ((5 5, 15 5, 15 15, 5 15, 5 5))",
+			true: []string{"intersects", "overlaps"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "POLYGON ((10 0, 20 0, 20 10, 10 10, 10 0))",
+			true: []string{"intersects", "touches"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "POLYGON ((10 0, 0 0, 0 10, 10 10, 10 0))",
+			true: []string{"intersects", "contains", "within", "equals"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 8 2, 8 8, 2 8, 2 2))",
+			b:    "POLYGON ((4 4, 6 4, 6 6, 4 6, 4 4))",
+			true: []string{"disjoint"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 8 2, 8 8, 2 8, 2 2))",
+			b:    "POLYGON ((1 1, 9 1, 9 9, 1 9, 1 1))",
+			true: []string{"intersects", "overlaps"},
+		},
+		{
+			a:    "MULTIPOLYGON (((0 0, 5 0, 5 5, 0 5, 0 0)), ((5 5, 10 5, 10 10, 5 10, 5 5)))",
+			b:    "POLYGON ((1 1, 4 1, 4 4, 1 4, 1 1))",
+			true: []string{"intersects", "contains"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "GEOMETRYCOLLECTION (POINT (5 5), LINESTRING (1 1, 2 2))",
+			true: []string{"intersects", "contains"},
+		},
+		{
+			a:    "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))",
+			b:    "POINT EMPTY",
+			true: []string{"disjoint"},
+		},
+	}
+
+	for _, c := range cases {
+		a, err := geometry.UnmarshalWKT(c.a)
+		require.NoError(t, err)
+		b, err := geometry.UnmarshalWKT(c.b)
+		require.NoError(t, err)
+
+		t.Run(c.a+" "+c.b, func(t *testing.T) {
+			for _, predicate := range predicates {
+				expected := false
+				for _, name := range c.true {
+					if name == predicate.name {
+						expected = true
+					}
+				}
+				assert.Equal(t, expected, predicate.test(a, b), predicate.name)
+			}
+		})
+	}
+}
+
+func TestRelateSymmetric(t *testing.T) {
+	a, err := geometry.UnmarshalWKT("POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0))")
+	require.NoError(t, err)
+	b, err := geometry.UnmarshalWKT("LINESTRING (5 5, 15 5)")
+	require.NoError(t, err)
+
+	assert.True(t, geometry.Crosses(a, b))
+	assert.True(t, geometry.Crosses(b, a))
+	assert.True(t, geometry.Intersects(b, a))
+	assert.False(t, geometry.Contains(a, b))
+}
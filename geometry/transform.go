@@ -0,0 +1,66 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+// Transform returns a copy of the geometry with each position replaced by the result of calling
+// the transform function.  The transform must not modify the provided position.  The layout of
+// the geometry is preserved.
+func Transform(g Geometry, transform func(position []float64) []float64) Geometry {
+	switch t := g.(type) {
+	case *Point:
+		point := &Point{Layout: t.Layout}
+		if len(t.Coordinates) > 0 {
+			point.Coordinates = transform(t.Coordinates)
+		}
+		return point
+	case *MultiPoint:
+		return &MultiPoint{Coordinates: transformPositions(t.Coordinates, transform), Layout: t.Layout}
+	case *LineString:
+		return &LineString{Coordinates: transformPositions(t.Coordinates, transform), Layout: t.Layout}
+	case *MultiLineString:
+		return &MultiLineString{Coordinates: transformRings(t.Coordinates, transform), Layout: t.Layout}
+	case *Polygon:
+		return &Polygon{Coordinates: transformRings(t.Coordinates, transform), Layout: t.Layout}
+	case *MultiPolygon:
+		polygons := make([][][][]float64, len(t.Coordinates))
+		for i, rings := range t.Coordinates {
+			polygons[i] = transformRings(rings, transform)
+		}
+		return &MultiPolygon{Coordinates: polygons, Layout: t.Layout}
+	case *GeometryCollection:
+		geometries := make([]Geometry, len(t.Geometries))
+		for i, child := range t.Geometries {
+			geometries[i] = Transform(child, transform)
+		}
+		return &GeometryCollection{Geometries: geometries}
+	}
+	return g
+}
+
+func transformPositions(positions [][]float64, transform func([]float64) []float64) [][]float64 {
+	transformed := make([][]float64, len(positions))
+	for i, position := range positions {
+		transformed[i] = transform(position)
+	}
+	return transformed
+}
+
+func transformRings(rings [][][]float64, transform func([]float64) []float64) [][][]float64 {
+	transformed := make([][][]float64, len(rings))
+	for i, ring := range rings {
+		transformed[i] = transformPositions(ring, transform)
+	}
+	return transformed
+}
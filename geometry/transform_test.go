@@ -0,0 +1,84 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry_test
+
+import (
+	"testing"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	shift := func(position []float64) []float64 {
+		shifted := append([]float64{}, position...)
+		shifted[0] += 10
+		return shifted
+	}
+
+	cases := []struct {
+		name     string
+		input    geometry.Geometry
+		expected geometry.Geometry
+	}{
+		{
+			name:     "point",
+			input:    &geometry.Point{Coordinates: []float64{1, 2, 3}, Layout: geometry.LayoutXYZ},
+			expected: &geometry.Point{Coordinates: []float64{11, 2, 3}, Layout: geometry.LayoutXYZ},
+		},
+		{
+			name:     "empty point",
+			input:    &geometry.Point{},
+			expected: &geometry.Point{},
+		},
+		{
+			name:     "line",
+			input:    &geometry.LineString{Coordinates: [][]float64{{1, 2}, {3, 4}}},
+			expected: &geometry.LineString{Coordinates: [][]float64{{11, 2}, {13, 4}}},
+		},
+		{
+			name:     "polygon",
+			input:    &geometry.Polygon{Coordinates: [][][]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}},
+			expected: &geometry.Polygon{Coordinates: [][][]float64{{{10, 0}, {11, 0}, {11, 1}, {10, 0}}}},
+		},
+		{
+			name:     "multipolygon",
+			input:    &geometry.MultiPolygon{Coordinates: [][][][]float64{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}},
+			expected: &geometry.MultiPolygon{Coordinates: [][][][]float64{{{{10, 0}, {11, 0}, {11, 1}, {10, 0}}}}},
+		},
+		{
+			name: "collection",
+			input: &geometry.GeometryCollection{Geometries: []geometry.Geometry{
+				&geometry.MultiPoint{Coordinates: [][]float64{{1, 2}}},
+				&geometry.MultiLineString{Coordinates: [][][]float64{{{1, 2}, {3, 4}}}},
+			}},
+			expected: &geometry.GeometryCollection{Geometries: []geometry.Geometry{
+				&geometry.MultiPoint{Coordinates: [][]float64{{11, 2}}},
+				&geometry.MultiLineString{Coordinates: [][][]float64{{{11, 2}, {13, 4}}}},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, geometry.Transform(c.input, shift))
+		})
+	}
+
+	// the input is not modified
+	line := &geometry.LineString{Coordinates: [][]float64{{1, 2}, {3, 4}}}
+	geometry.Transform(line, shift)
+	assert.Equal(t, [][]float64{{1, 2}, {3, 4}}, line.Coordinates)
+}
@@ -0,0 +1,513 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	wkbXDR = 0
+	wkbNDR = 1
+
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+
+	// quiet NaN used for the coordinates of empty points
+	wkbNaN = 0x7ff8000000000000
+)
+
+var wkbCodes = map[string]uint32{
+	TypePoint:              1,
+	TypeLineString:         2,
+	TypePolygon:            3,
+	TypeMultiPoint:         4,
+	TypeMultiLineString:    5,
+	TypeMultiPolygon:       6,
+	TypeGeometryCollection: 7,
+}
+
+var wkbTypes = map[uint32]string{
+	1: TypePoint,
+	2: TypeLineString,
+	3: TypePolygon,
+	4: TypeMultiPoint,
+	5: TypeMultiLineString,
+	6: TypeMultiPolygon,
+	7: TypeGeometryCollection,
+}
+
+// MarshalWKB encodes a geometry as ISO Well-Known Binary with the provided byte order
+// (binary.LittleEndian or binary.BigEndian).  Empty points are encoded with NaN coordinates.
+func MarshalWKB(g Geometry, order binary.ByteOrder) ([]byte, error) {
+	w, err := newWKBWriter(order, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.write(g, 0); err != nil {
+		return nil, err
+	}
+	return w.buffer.Bytes(), nil
+}
+
+// MarshalEWKB encodes a geometry as Extended Well-Known Binary (as used by PostGIS).  If the
+// provided SRID is non-zero, it is included in the output.
+func MarshalEWKB(g Geometry, order binary.ByteOrder, srid int) ([]byte, error) {
+	w, err := newWKBWriter(order, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.write(g, srid); err != nil {
+		return nil, err
+	}
+	return w.buffer.Bytes(), nil
+}
+
+type wkbWriter struct {
+	buffer   *bytes.Buffer
+	order    binary.ByteOrder
+	marker   byte
+	extended bool
+}
+
+func newWKBWriter(order binary.ByteOrder, extended bool) (*wkbWriter, error) {
+	w := &wkbWriter{buffer: &bytes.Buffer{}, order: order, extended: extended}
+	switch order {
+	case binary.LittleEndian:
+		w.marker = wkbNDR
+	case binary.BigEndian:
+		w.marker = wkbXDR
+	default:
+		return nil, errors.New("unsupported byte order")
+	}
+	return w, nil
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	var b [4]byte
+	w.order.PutUint32(b[:], v)
+	w.buffer.Write(b[:])
+}
+
+func (w *wkbWriter) float64(v float64) {
+	var b [8]byte
+	w.order.PutUint64(b[:], math.Float64bits(v))
+	w.buffer.Write(b[:])
+}
+
+func (w *wkbWriter) header(geometryType string, layout Layout, srid int) {
+	code := wkbCodes[geometryType]
+	if w.extended {
+		if layout.HasZ() {
+			code |= ewkbZ
+		}
+		if layout.HasM() {
+			code |= ewkbM
+		}
+		if srid != 0 {
+			code |= ewkbSRID
+		}
+	} else {
+		switch layout {
+		case LayoutXYZ:
+			code += 1000
+		case LayoutXYM:
+			code += 2000
+		case LayoutXYZM:
+			code += 3000
+		}
+	}
+	w.buffer.WriteByte(w.marker)
+	w.uint32(code)
+	if w.extended && srid != 0 {
+		w.uint32(uint32(int32(srid)))
+	}
+}
+
+func (w *wkbWriter) positions(positions [][]float64, stride int) error {
+	w.uint32(uint32(len(positions)))
+	for _, position := range positions {
+		if err := w.position(position, stride); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wkbWriter) position(position []float64, stride int) error {
+	if len(position) != stride {
+		return fmt.Errorf("expected %d values in position, found %d", stride, len(position))
+	}
+	for _, v := range position {
+		w.float64(v)
+	}
+	return nil
+}
+
+func (w *wkbWriter) rings(rings [][][]float64, stride int) error {
+	w.uint32(uint32(len(rings)))
+	for _, ring := range rings {
+		if err := w.positions(ring, stride); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wkbWriter) write(g Geometry, srid int) error {
+	if g == nil {
+		return errors.New("missing geometry")
+	}
+	layout, err := resolveLayout(g)
+	if err != nil {
+		return err
+	}
+	stride := layout.Stride()
+
+	w.header(g.Type(), layout, srid)
+	switch t := g.(type) {
+	case *Point:
+		if len(t.Coordinates) == 0 {
+			for i := 0; i < stride; i++ {
+				w.float64(math.Float64frombits(wkbNaN))
+			}
+			return nil
+		}
+		return w.position(t.Coordinates, stride)
+	case *LineString:
+		return w.positions(t.Coordinates, stride)
+	case *Polygon:
+		return w.rings(t.Coordinates, stride)
+	case *MultiPoint:
+		w.uint32(uint32(len(t.Coordinates)))
+		for _, position := range t.Coordinates {
+			if err := w.write(&Point{Coordinates: position, Layout: layout}, 0); err != nil {
+				return err
+			}
+		}
+	case *MultiLineString:
+		w.uint32(uint32(len(t.Coordinates)))
+		for _, line := range t.Coordinates {
+			if err := w.write(&LineString{Coordinates: line, Layout: layout}, 0); err != nil {
+				return err
+			}
+		}
+	case *MultiPolygon:
+		w.uint32(uint32(len(t.Coordinates)))
+		for _, polygon := range t.Coordinates {
+			if err := w.write(&Polygon{Coordinates: polygon, Layout: layout}, 0); err != nil {
+				return err
+			}
+		}
+	case *GeometryCollection:
+		w.uint32(uint32(len(t.Geometries)))
+		for _, child := range t.Geometries {
+			if err := w.write(child, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// UnmarshalWKB decodes and validates a geometry from Well-Known Binary.  OGC, ISO, and Extended
+// (PostGIS) variants are supported.  Any SRID in Extended WKB is ignored.
+func UnmarshalWKB(data []byte) (Geometry, error) {
+	g, _, err := UnmarshalEWKB(data)
+	return g, err
+}
+
+// UnmarshalEWKB decodes and validates a geometry from Extended Well-Known Binary.  The returned
+// SRID is zero if the data does not include one.  OGC and ISO Well-Known Binary are also supported.
+func UnmarshalEWKB(data []byte) (Geometry, int, error) {
+	r := &wkbReader{data: data}
+	g, srid, err := r.read()
+	if err != nil {
+		return nil, 0, err
+	}
+	if r.pos != len(data) {
+		return nil, 0, fmt.Errorf("unexpected %d bytes after geometry", len(data)-r.pos)
+	}
+	if err := g.Validate(); err != nil {
+		return nil, 0, err
+	}
+	return g, srid, nil
+}
+
+var errWKBTruncated = errors.New("unexpected end of WKB data")
+
+type wkbReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wkbReader) bytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errWKBTruncated
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *wkbReader) uint32(order binary.ByteOrder) (uint32, error) {
+	b, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return order.Uint32(b), nil
+}
+
+// count reads a number of items and checks that the remaining data could hold that many items.
+func (r *wkbReader) count(order binary.ByteOrder, minItemSize int) (int, error) {
+	n, err := r.uint32(order)
+	if err != nil {
+		return 0, err
+	}
+	if int(n) > (len(r.data)-r.pos)/minItemSize {
+		return 0, errWKBTruncated
+	}
+	return int(n), nil
+}
+
+func (r *wkbReader) position(order binary.ByteOrder, stride int) ([]float64, error) {
+	b, err := r.bytes(8 * stride)
+	if err != nil {
+		return nil, err
+	}
+	position := make([]float64, stride)
+	for i := range position {
+		position[i] = math.Float64frombits(order.Uint64(b[8*i:]))
+	}
+	return position, nil
+}
+
+func (r *wkbReader) positions(order binary.ByteOrder, stride int) ([][]float64, error) {
+	n, err := r.count(order, 8*stride)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	positions := make([][]float64, n)
+	for i := range positions {
+		position, err := r.position(order, stride)
+		if err != nil {
+			return nil, err
+		}
+		positions[i] = position
+	}
+	return positions, nil
+}
+
+func (r *wkbReader) rings(order binary.ByteOrder, stride int) ([][][]float64, error) {
+	n, err := r.count(order, 4)
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	rings := make([][][]float64, n)
+	for i := range rings {
+		ring, err := r.positions(order, stride)
+		if err != nil {
+			return nil, err
+		}
+		rings[i] = ring
+	}
+	return rings, nil
+}
+
+func (r *wkbReader) header() (binary.ByteOrder, string, Layout, int, error) {
+	marker, err := r.bytes(1)
+	if err != nil {
+		return nil, "", LayoutDefault, 0, err
+	}
+	var order binary.ByteOrder
+	switch marker[0] {
+	case wkbNDR:
+		order = binary.LittleEndian
+	case wkbXDR:
+		order = binary.BigEndian
+	default:
+		return nil, "", LayoutDefault, 0, fmt.Errorf("invalid WKB byte order %d", marker[0])
+	}
+
+	code, err := r.uint32(order)
+	if err != nil {
+		return nil, "", LayoutDefault, 0, err
+	}
+
+	hasZ := code&ewkbZ != 0
+	hasM := code&ewkbM != 0
+	srid := 0
+	if code&ewkbSRID != 0 {
+		s, err := r.uint32(order)
+		if err != nil {
+			return nil, "", LayoutDefault, 0, err
+		}
+		srid = int(int32(s))
+	}
+
+	code &= 0x0fffffff
+	switch code / 1000 {
+	case 0:
+	case 1:
+		hasZ = true
+	case 2:
+		hasM = true
+	case 3:
+		hasZ = true
+		hasM = true
+	default:
+		return nil, "", LayoutDefault, 0, fmt.Errorf("unsupported WKB geometry type %d", code)
+	}
+
+	geometryType, ok := wkbTypes[code%1000]
+	if !ok {
+		return nil, "", LayoutDefault, 0, fmt.Errorf("unsupported WKB geometry type %d", code)
+	}
+
+	layout := LayoutXY
+	switch {
+	case hasZ && hasM:
+		layout = LayoutXYZM
+	case hasZ:
+		layout = LayoutXYZ
+	case hasM:
+		layout = LayoutXYM
+	}
+	return order, geometryType, layout, srid, nil
+}
+
+func (r *wkbReader) read() (Geometry, int, error) {
+	order, geometryType, layout, srid, err := r.header()
+	if err != nil {
+		return nil, 0, err
+	}
+	stride := layout.Stride()
+
+	// explicit layouts are only retained when they cannot be inferred from the positions
+	retained := LayoutDefault
+	if layout == LayoutXYM {
+		retained = layout
+	}
+
+	var g Geometry
+	switch geometryType {
+	case TypePoint:
+		position, err := r.position(order, stride)
+		if err != nil {
+			return nil, 0, err
+		}
+		empty := true
+		for _, v := range position {
+			if !math.IsNaN(v) {
+				empty = false
+			}
+		}
+		if empty {
+			g = &Point{Layout: retained}
+		} else {
+			g = &Point{Coordinates: position, Layout: retained}
+		}
+	case TypeLineString:
+		positions, err := r.positions(order, stride)
+		if err != nil {
+			return nil, 0, err
+		}
+		g = &LineString{Coordinates: positions, Layout: retained}
+	case TypePolygon:
+		rings, err := r.rings(order, stride)
+		if err != nil {
+			return nil, 0, err
+		}
+		g = &Polygon{Coordinates: rings, Layout: retained}
+	case TypeMultiPoint, TypeMultiLineString, TypeMultiPolygon, TypeGeometryCollection:
+		n, err := r.count(order, 5)
+		if err != nil {
+			return nil, 0, err
+		}
+		children := make([]Geometry, n)
+		for i := range children {
+			child, _, err := r.read()
+			if err != nil {
+				return nil, 0, err
+			}
+			children[i] = child
+		}
+		g, err = collect(geometryType, children, retained)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if layout != LayoutXY && firstPosition(g) == nil {
+		setLayout(g, layout)
+	}
+	return g, srid, nil
+}
+
+// collect assembles a multi-part geometry from its parts.
+func collect(geometryType string, children []Geometry, layout Layout) (Geometry, error) {
+	if len(children) == 0 {
+		switch geometryType {
+		case TypeMultiPoint:
+			return &MultiPoint{Layout: layout}, nil
+		case TypeMultiLineString:
+			return &MultiLineString{Layout: layout}, nil
+		case TypeMultiPolygon:
+			return &MultiPolygon{Layout: layout}, nil
+		}
+	}
+
+	switch geometryType {
+	case TypeMultiPoint:
+		coordinates := make([][]float64, len(children))
+		for i, child := range children {
+			point, ok := child.(*Point)
+			if !ok {
+				return nil, fmt.Errorf("expected point in multipoint, got %s", child.Type())
+			}
+			if len(point.Coordinates) == 0 {
+				return nil, errors.New("unsupported empty point in multipoint")
+			}
+			coordinates[i] = point.Coordinates
+		}
+		return &MultiPoint{Coordinates: coordinates, Layout: layout}, nil
+	case TypeMultiLineString:
+		coordinates := make([][][]float64, len(children))
+		for i, child := range children {
+			line, ok := child.(*LineString)
+			if !ok {
+				return nil, fmt.Errorf("expected linestring in multilinestring, got %s", child.Type())
+			}
+			coordinates[i] = line.Coordinates
+		}
+		return &MultiLineString{Coordinates: coordinates, Layout: layout}, nil
+	case TypeMultiPolygon:
+		coordinates := make([][][][]float64, len(children))
+		for i, child := range children {
+			polygon, ok := child.(*Polygon)
+			if !ok {
+				return nil, fmt.Errorf("expected polygon in multipolygon, got %s", child.Type())
+			}
+			coordinates[i] = polygon.Coordinates
+		}
+		return &MultiPolygon{Coordinates: coordinates, Layout: layout}, nil
+	}
+	return &GeometryCollection{Geometries: children}, nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(t *testing.T, value string) []byte {
+	data, err := hex.DecodeString(value)
+	require.NoError(t, err)
+	return data
+}
+
+func TestWKB(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry geometry.Geometry
+		order    binary.ByteOrder
+		wkb      string
+	}{
+		{
+			name:     "point little endian",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2}},
+			order:    binary.LittleEndian,
+			wkb:      "0101000000000000000000f03f0000000000000040",
+		},
+		{
+			name:     "point big endian",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2}},
+			order:    binary.BigEndian,
+			wkb:      "00000000013ff00000000000004000000000000000",
+		},
+		{
+			name:     "point z",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+			order:    binary.LittleEndian,
+			wkb:      "01e9030000000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "point m",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}, Layout: geometry.LayoutXYM},
+			order:    binary.LittleEndian,
+			wkb:      "01d1070000000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "empty point",
+			geometry: &geometry.Point{},
+			order:    binary.LittleEndian,
+			wkb:      "0101000000000000000000f87f000000000000f87f",
+		},
+		{
+			name:     "linestring",
+			geometry: &geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+			order:    binary.LittleEndian,
+			wkb:      "01020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f",
+		},
+		{
+			name: "polygon",
+			geometry: &geometry.Polygon{Coordinates: [][][]float64{
+				{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			}},
+			order: binary.LittleEndian,
+			wkb: "010300000001000000040000000000000000000000000000000000000000000000" +
+				"0000f03f0000000000000000000000000000f03f000000000000f03f00000000" +
+				"000000000000000000000000",
+		},
+		{
+			name:     "multipoint",
+			geometry: &geometry.MultiPoint{Coordinates: [][]float64{{1, 2}, {3, 4}}},
+			order:    binary.LittleEndian,
+			wkb: "010400000002000000" +
+				"0101000000000000000000f03f0000000000000040" +
+				"010100000000000000000008400000000000001040",
+		},
+		{
+			name: "geometry collection",
+			geometry: &geometry.GeometryCollection{Geometries: []geometry.Geometry{
+				&geometry.Point{Coordinates: []float64{1, 2}},
+				&geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+			}},
+			order: binary.LittleEndian,
+			wkb: "010700000002000000" +
+				"0101000000000000000000f03f0000000000000040" +
+				"01020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := geometry.MarshalWKB(tc.geometry, tc.order)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wkb, hex.EncodeToString(data))
+
+			decoded, err := geometry.UnmarshalWKB(mustDecodeHex(t, tc.wkb))
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+		})
+	}
+}
+
+func TestEWKB(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry geometry.Geometry
+		srid     int
+		ewkb     string
+	}{
+		{
+			name:     "point with srid",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2}},
+			srid:     4326,
+			ewkb:     "0101000020e6100000000000000000f03f0000000000000040",
+		},
+		{
+			name:     "point z without srid",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+			ewkb:     "0101000080000000000000f03f00000000000000400000000000000840",
+		},
+		{
+			name:     "linestring zm with srid",
+			geometry: &geometry.LineString{Coordinates: [][]float64{{0, 0, 1, 2}, {1, 1, 3, 4}}},
+			srid:     3857,
+			ewkb: "01020000e0110f000002000000" +
+				"00000000000000000000000000000000000000000000f03f0000000000000040" +
+				"000000000000f03f000000000000f03f00000000000008400000000000001040",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := geometry.MarshalEWKB(tc.geometry, binary.LittleEndian, tc.srid)
+			require.NoError(t, err)
+			assert.Equal(t, tc.ewkb, hex.EncodeToString(data))
+
+			decoded, srid, err := geometry.UnmarshalEWKB(mustDecodeHex(t, tc.ewkb))
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+			assert.Equal(t, tc.srid, srid)
+		})
+	}
+}
+
+func TestWKBRoundTripFromWKT(t *testing.T) {
+	cases := []string{
+		"POINT ZM (1 2 3 4)",
+		"LINESTRING M (0 0 1, 1 1 2)",
+		"POLYGON Z ((0 0 0, 1 0 0, 1 1 0, 0 0 0))",
+		"MULTILINESTRING ((0 0, 1 1), (2 2, 3 3))",
+		"MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 7, 5 5)))",
+		"MULTIPOINT Z EMPTY",
+		"GEOMETRYCOLLECTION (POINT (1 2), POLYGON ((0 0, 1 0, 1 1, 0 0)))",
+	}
+
+	for _, wkt := range cases {
+		t.Run(wkt, func(t *testing.T) {
+			g, err := geometry.UnmarshalWKT(wkt)
+			require.NoError(t, err)
+
+			for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+				data, err := geometry.MarshalWKB(g, order)
+				require.NoError(t, err)
+				decoded, err := geometry.UnmarshalWKB(data)
+				require.NoError(t, err)
+				assert.Equal(t, g, decoded)
+
+				data, err = geometry.MarshalEWKB(g, order, 4326)
+				require.NoError(t, err)
+				decoded, srid, err := geometry.UnmarshalEWKB(data)
+				require.NoError(t, err)
+				assert.Equal(t, g, decoded)
+				assert.Equal(t, 4326, srid)
+
+				text, err := geometry.MarshalWKT(decoded)
+				require.NoError(t, err)
+				assert.Equal(t, wkt, text)
+			}
+		})
+	}
+}
+
+func TestUnmarshalWKBErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		wkb  string
+		err  string
+	}{
+		{
+			name: "empty",
+			wkb:  "",
+			err:  "unexpected end of WKB data",
+		},
+		{
+			name: "bad byte order",
+			wkb:  "0201000000000000000000f03f0000000000000040",
+			err:  "invalid WKB byte order 2",
+		},
+		{
+			name: "unknown type",
+			wkb:  "0109000000000000000000f03f0000000000000040",
+			err:  "unsupported WKB geometry type 9",
+		},
+		{
+			name: "truncated point",
+			wkb:  "0101000000000000000000f03f",
+			err:  "unexpected end of WKB data",
+		},
+		{
+			name: "huge count",
+			wkb:  "0102000000ffffffff",
+			err:  "unexpected end of WKB data",
+		},
+		{
+			name: "trailing bytes",
+			wkb:  "0101000000000000000000f03f0000000000000040" + strings.Repeat("00", 2),
+			err:  "unexpected 2 bytes after geometry",
+		},
+		{
+			name: "wrong part type",
+			wkb:  "01040000000100000001020000000200000000000000000000000000000000000000000000000000f03f000000000000f03f",
+			err:  "expected point in multipoint, got LineString",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := geometry.UnmarshalWKB(mustDecodeHex(t, tc.wkb))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
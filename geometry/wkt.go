@@ -0,0 +1,539 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var wktNames = map[string]string{
+	TypePoint:              "POINT",
+	TypeLineString:         "LINESTRING",
+	TypePolygon:            "POLYGON",
+	TypeMultiPoint:         "MULTIPOINT",
+	TypeMultiLineString:    "MULTILINESTRING",
+	TypeMultiPolygon:       "MULTIPOLYGON",
+	TypeGeometryCollection: "GEOMETRYCOLLECTION",
+}
+
+var wktTypes = map[string]string{
+	"POINT":              TypePoint,
+	"LINESTRING":         TypeLineString,
+	"POLYGON":            TypePolygon,
+	"MULTIPOINT":         TypeMultiPoint,
+	"MULTILINESTRING":    TypeMultiLineString,
+	"MULTIPOLYGON":       TypeMultiPolygon,
+	"GEOMETRYCOLLECTION": TypeGeometryCollection,
+}
+
+// MarshalWKT encodes a geometry as Well-Known Text.  Geometries with a third or fourth
+// position value are tagged with Z, M, or ZM as appropriate.
+func MarshalWKT(g Geometry) (string, error) {
+	builder := &strings.Builder{}
+	if err := writeWKT(builder, g); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+func writeWKT(b *strings.Builder, g Geometry) error {
+	if g == nil {
+		return errors.New("missing geometry")
+	}
+	layout, err := resolveLayout(g)
+	if err != nil {
+		return err
+	}
+
+	b.WriteString(wktNames[g.Type()])
+	switch layout {
+	case LayoutXYZ:
+		b.WriteString(" Z")
+	case LayoutXYM:
+		b.WriteString(" M")
+	case LayoutXYZM:
+		b.WriteString(" ZM")
+	}
+
+	if firstPosition(g) == nil {
+		if collection, ok := g.(*GeometryCollection); !ok || len(collection.Geometries) == 0 {
+			b.WriteString(" EMPTY")
+			return nil
+		}
+	}
+	b.WriteString(" ")
+
+	stride := layout.Stride()
+	switch t := g.(type) {
+	case *Point:
+		return writeWKTPositions(b, [][]float64{t.Coordinates}, stride)
+	case *LineString:
+		return writeWKTPositions(b, t.Coordinates, stride)
+	case *Polygon:
+		return writeWKTRings(b, t.Coordinates, stride)
+	case *MultiPoint:
+		b.WriteString("(")
+		for i, position := range t.Coordinates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeWKTPositions(b, [][]float64{position}, stride); err != nil {
+				return err
+			}
+		}
+		b.WriteString(")")
+	case *MultiLineString:
+		return writeWKTRings(b, t.Coordinates, stride)
+	case *MultiPolygon:
+		b.WriteString("(")
+		for i, polygon := range t.Coordinates {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeWKTRings(b, polygon, stride); err != nil {
+				return err
+			}
+		}
+		b.WriteString(")")
+	case *GeometryCollection:
+		b.WriteString("(")
+		for i, child := range t.Geometries {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if err := writeWKT(b, child); err != nil {
+				return err
+			}
+		}
+		b.WriteString(")")
+	}
+	return nil
+}
+
+func writeWKTPositions(b *strings.Builder, positions [][]float64, stride int) error {
+	b.WriteString("(")
+	for i, position := range positions {
+		if len(position) != stride {
+			return fmt.Errorf("expected %d values in position, found %d", stride, len(position))
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		for j, v := range position {
+			if j > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+	b.WriteString(")")
+	return nil
+}
+
+func writeWKTRings(b *strings.Builder, rings [][][]float64, stride int) error {
+	b.WriteString("(")
+	for i, ring := range rings {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeWKTPositions(b, ring, stride); err != nil {
+			return err
+		}
+	}
+	b.WriteString(")")
+	return nil
+}
+
+// UnmarshalWKT decodes and validates a geometry from Well-Known Text.  Both the OGC and ISO
+// forms of Z, M, and ZM geometries are supported (e.g. "POINT Z (1 2 3)" and "POINTZ (1 2 3)"),
+// as are EMPTY geometries.  An Extended WKT "SRID=<srid>;" prefix is accepted and ignored.
+func UnmarshalWKT(text string) (Geometry, error) {
+	p := &wktParser{input: text}
+	p.skipSRID()
+	g, err := p.parseGeometry()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected %q after geometry at position %d", p.input[p.pos:], p.pos)
+	}
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type wktParser struct {
+	input string
+	pos   int
+}
+
+func (p *wktParser) skipSpace() {
+	for p.pos < len(p.input) {
+		switch p.input[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos += 1
+		default:
+			return
+		}
+	}
+}
+
+func (p *wktParser) skipSRID() {
+	p.skipSpace()
+	rest := p.input[p.pos:]
+	if len(rest) < 5 || !strings.EqualFold(rest[:5], "SRID=") {
+		return
+	}
+	if end := strings.IndexByte(rest, ';'); end > 0 {
+		p.pos += end + 1
+	}
+}
+
+func (p *wktParser) peek() byte {
+	p.skipSpace()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *wktParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			break
+		}
+		p.pos += 1
+	}
+	return strings.ToUpper(p.input[start:p.pos])
+}
+
+func (p *wktParser) expect(c byte) error {
+	if p.peek() != c {
+		if p.pos >= len(p.input) {
+			return fmt.Errorf("expected %q, found end of input", c)
+		}
+		return fmt.Errorf("expected %q at position %d", c, p.pos)
+	}
+	p.pos += 1
+	return nil
+}
+
+func (p *wktParser) number() (float64, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if (c < '0' || c > '9') && c != '-' && c != '+' && c != '.' && c != 'e' && c != 'E' {
+			break
+		}
+		p.pos += 1
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("expected number at position %d", start)
+	}
+	value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q at position %d", p.input[start:p.pos], start)
+	}
+	return value, nil
+}
+
+// parseType reads a geometry type name and an optional Z, M, or ZM tag (possibly attached to the name).
+func (p *wktParser) parseType() (string, Layout, error) {
+	start := p.pos
+	name := p.word()
+	if name == "" {
+		return "", LayoutDefault, fmt.Errorf("expected geometry type at position %d", start)
+	}
+
+	geometryType, ok := wktTypes[name]
+	tag := ""
+	if !ok {
+		for _, suffix := range []string{"ZM", "Z", "M"} {
+			if t, found := wktTypes[strings.TrimSuffix(name, suffix)]; found && strings.HasSuffix(name, suffix) {
+				geometryType = t
+				tag = suffix
+				ok = true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", LayoutDefault, fmt.Errorf("unsupported geometry type %q", name)
+	}
+
+	if tag == "" {
+		save := p.pos
+		switch next := p.word(); next {
+		case "Z", "M", "ZM":
+			tag = next
+		default:
+			p.pos = save
+		}
+	}
+
+	switch tag {
+	case "Z":
+		return geometryType, LayoutXYZ, nil
+	case "M":
+		return geometryType, LayoutXYM, nil
+	case "ZM":
+		return geometryType, LayoutXYZM, nil
+	}
+	return geometryType, LayoutDefault, nil
+}
+
+// isEmpty consumes an EMPTY keyword if it is next.
+func (p *wktParser) isEmpty() bool {
+	save := p.pos
+	if p.word() == "EMPTY" {
+		return true
+	}
+	p.pos = save
+	return false
+}
+
+func (p *wktParser) parseGeometry() (Geometry, error) {
+	geometryType, layout, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	// explicit layouts are only retained when they cannot be inferred from the positions
+	empty := p.isEmpty()
+	retained := LayoutDefault
+	if layout == LayoutXYM || (empty && layout != LayoutDefault) {
+		retained = layout
+	}
+
+	if geometryType == TypeGeometryCollection {
+		collection := &GeometryCollection{Geometries: []Geometry{}}
+		if empty {
+			return collection, nil
+		}
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		for {
+			child, err := p.parseGeometry()
+			if err != nil {
+				return nil, err
+			}
+			collection.Geometries = append(collection.Geometries, child)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos += 1
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return collection, nil
+	}
+
+	if empty {
+		switch geometryType {
+		case TypePoint:
+			return &Point{Layout: retained}, nil
+		case TypeLineString:
+			return &LineString{Layout: retained}, nil
+		case TypePolygon:
+			return &Polygon{Layout: retained}, nil
+		case TypeMultiPoint:
+			return &MultiPoint{Layout: retained}, nil
+		case TypeMultiLineString:
+			return &MultiLineString{Layout: retained}, nil
+		case TypeMultiPolygon:
+			return &MultiPolygon{Layout: retained}, nil
+		}
+	}
+
+	positions := &wktPositions{stride: layout.Stride()}
+	var g Geometry
+	switch geometryType {
+	case TypePoint:
+		coordinates, err := p.parsePositions(positions)
+		if err != nil {
+			return nil, err
+		}
+		if len(coordinates) != 1 {
+			return nil, fmt.Errorf("expected a single position in point, found %d", len(coordinates))
+		}
+		g = &Point{Coordinates: coordinates[0], Layout: retained}
+	case TypeLineString:
+		coordinates, err := p.parsePositions(positions)
+		if err != nil {
+			return nil, err
+		}
+		g = &LineString{Coordinates: coordinates, Layout: retained}
+	case TypePolygon:
+		coordinates, err := p.parseRings(positions)
+		if err != nil {
+			return nil, err
+		}
+		g = &Polygon{Coordinates: coordinates, Layout: retained}
+	case TypeMultiPoint:
+		coordinates, err := p.parseMultiPoint(positions)
+		if err != nil {
+			return nil, err
+		}
+		g = &MultiPoint{Coordinates: coordinates, Layout: retained}
+	case TypeMultiLineString:
+		coordinates, err := p.parseRings(positions)
+		if err != nil {
+			return nil, err
+		}
+		g = &MultiLineString{Coordinates: coordinates, Layout: retained}
+	case TypeMultiPolygon:
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		coordinates := [][][][]float64{}
+		for {
+			polygon, err := p.parseRings(positions)
+			if err != nil {
+				return nil, err
+			}
+			coordinates = append(coordinates, polygon)
+			if p.peek() != ',' {
+				break
+			}
+			p.pos += 1
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		g = &MultiPolygon{Coordinates: coordinates, Layout: retained}
+	}
+	return g, nil
+}
+
+// wktPositions tracks the expected number of values in each position of a geometry.
+type wktPositions struct {
+	stride int
+}
+
+func (p *wktParser) parsePosition(positions *wktPositions) ([]float64, error) {
+	position := []float64{}
+	for {
+		c := p.peek()
+		if c == ',' || c == ')' || c == 0 {
+			break
+		}
+		value, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		position = append(position, value)
+	}
+	if positions.stride == 0 {
+		if len(position) < 2 || len(position) > 4 {
+			return nil, fmt.Errorf("expected 2 to 4 values in position, found %d", len(position))
+		}
+		positions.stride = len(position)
+	} else if len(position) != positions.stride {
+		return nil, fmt.Errorf("expected %d values in position, found %d", positions.stride, len(position))
+	}
+	return position, nil
+}
+
+func (p *wktParser) parsePositions(positions *wktPositions) ([][]float64, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	coordinates := [][]float64{}
+	for {
+		position, err := p.parsePosition(positions)
+		if err != nil {
+			return nil, err
+		}
+		coordinates = append(coordinates, position)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos += 1
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return coordinates, nil
+}
+
+func (p *wktParser) parseRings(positions *wktPositions) ([][][]float64, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	rings := [][][]float64{}
+	for {
+		ring, err := p.parsePositions(positions)
+		if err != nil {
+			return nil, err
+		}
+		rings = append(rings, ring)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos += 1
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return rings, nil
+}
+
+// parseMultiPoint supports both "MULTIPOINT ((1 2), (3 4))" and "MULTIPOINT (1 2, 3 4)".
+func (p *wktParser) parseMultiPoint(positions *wktPositions) ([][]float64, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	coordinates := [][]float64{}
+	for {
+		var position []float64
+		if p.peek() == '(' {
+			p.pos += 1
+			pos, err := p.parsePosition(positions)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(')'); err != nil {
+				return nil, err
+			}
+			position = pos
+		} else {
+			pos, err := p.parsePosition(positions)
+			if err != nil {
+				return nil, err
+			}
+			position = pos
+		}
+		coordinates = append(coordinates, position)
+		if p.peek() != ',' {
+			break
+		}
+		p.pos += 1
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return coordinates, nil
+}
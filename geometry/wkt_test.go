@@ -0,0 +1,245 @@
+// Copyright 2023 Planet Labs PBC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geometry_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/planetlabs/go-ogc/geometry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWKT(t *testing.T) {
+	cases := []struct {
+		name     string
+		geometry geometry.Geometry
+		wkt      string
+	}{
+		{
+			name:     "point",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2.5}},
+			wkt:      "POINT (1 2.5)",
+		},
+		{
+			name:     "point z",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+			wkt:      "POINT Z (1 2 3)",
+		},
+		{
+			name:     "point m",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}, Layout: geometry.LayoutXYM},
+			wkt:      "POINT M (1 2 3)",
+		},
+		{
+			name:     "point zm",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3, 4}},
+			wkt:      "POINT ZM (1 2 3 4)",
+		},
+		{
+			name:     "empty point",
+			geometry: &geometry.Point{},
+			wkt:      "POINT EMPTY",
+		},
+		{
+			name:     "empty point z",
+			geometry: &geometry.Point{Layout: geometry.LayoutXYZ},
+			wkt:      "POINT Z EMPTY",
+		},
+		{
+			name:     "linestring",
+			geometry: &geometry.LineString{Coordinates: [][]float64{{-10.5, 0}, {1e-7, 20}}},
+			wkt:      "LINESTRING (-10.5 0, 0.0000001 20)",
+		},
+		{
+			name:     "empty linestring",
+			geometry: &geometry.LineString{},
+			wkt:      "LINESTRING EMPTY",
+		},
+		{
+			name: "polygon with hole",
+			geometry: &geometry.Polygon{Coordinates: [][][]float64{
+				{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+				{{2, 2}, {2, 4}, {4, 4}, {2, 2}},
+			}},
+			wkt: "POLYGON ((0 0, 10 0, 10 10, 0 10, 0 0), (2 2, 2 4, 4 4, 2 2))",
+		},
+		{
+			name:     "multipoint",
+			geometry: &geometry.MultiPoint{Coordinates: [][]float64{{1, 2}, {3, 4}}},
+			wkt:      "MULTIPOINT ((1 2), (3 4))",
+		},
+		{
+			name:     "multilinestring z",
+			geometry: &geometry.MultiLineString{Coordinates: [][][]float64{{{0, 0, 1}, {1, 1, 2}}, {{2, 2, 3}, {3, 3, 4}}}},
+			wkt:      "MULTILINESTRING Z ((0 0 1, 1 1 2), (2 2 3, 3 3 4))",
+		},
+		{
+			name: "multipolygon",
+			geometry: &geometry.MultiPolygon{Coordinates: [][][][]float64{
+				{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}},
+				{{{5, 5}, {6, 5}, {6, 7}, {5, 5}}},
+			}},
+			wkt: "MULTIPOLYGON (((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 7, 5 5)))",
+		},
+		{
+			name:     "empty multipolygon m",
+			geometry: &geometry.MultiPolygon{Layout: geometry.LayoutXYM},
+			wkt:      "MULTIPOLYGON M EMPTY",
+		},
+		{
+			name: "geometry collection",
+			geometry: &geometry.GeometryCollection{Geometries: []geometry.Geometry{
+				&geometry.Point{Coordinates: []float64{1, 2}},
+				&geometry.LineString{Coordinates: [][]float64{{0, 0}, {1, 1}}},
+				&geometry.Point{},
+			}},
+			wkt: "GEOMETRYCOLLECTION (POINT (1 2), LINESTRING (0 0, 1 1), POINT EMPTY)",
+		},
+		{
+			name:     "empty geometry collection",
+			geometry: &geometry.GeometryCollection{Geometries: []geometry.Geometry{}},
+			wkt:      "GEOMETRYCOLLECTION EMPTY",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			wkt, err := geometry.MarshalWKT(tc.geometry)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wkt, wkt)
+
+			decoded, err := geometry.UnmarshalWKT(tc.wkt)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+		})
+	}
+}
+
+func TestUnmarshalWKTVariants(t *testing.T) {
+	cases := []struct {
+		name     string
+		wkt      string
+		geometry geometry.Geometry
+	}{
+		{
+			name:     "lowercase without spaces",
+			wkt:      "point(1 2)",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2}},
+		},
+		{
+			name:     "attached tag",
+			wkt:      "POINTZ (1 2 3)",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+		},
+		{
+			name:     "attached m tag",
+			wkt:      "LINESTRINGM (1 2 3, 4 5 6)",
+			geometry: &geometry.LineString{Coordinates: [][]float64{{1, 2, 3}, {4, 5, 6}}, Layout: geometry.LayoutXYM},
+		},
+		{
+			name:     "untagged 3d",
+			wkt:      "POINT (1 2 3)",
+			geometry: &geometry.Point{Coordinates: []float64{1, 2, 3}},
+		},
+		{
+			name:     "multipoint without parentheses",
+			wkt:      "MULTIPOINT (1 2, 3 4)",
+			geometry: &geometry.MultiPoint{Coordinates: [][]float64{{1, 2}, {3, 4}}},
+		},
+		{
+			name:     "extended wkt",
+			wkt:      "SRID=4326;POINT(-120 40)",
+			geometry: &geometry.Point{Coordinates: []float64{-120, 40}},
+		},
+		{
+			name: "whitespace",
+			wkt:  "\n  POLYGON (\n\t(0 0, 1 0, 1 1, 0 0)\n)\n",
+			geometry: &geometry.Polygon{Coordinates: [][][]float64{
+				{{0, 0}, {1, 0}, {1, 1}, {0, 0}},
+			}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, err := geometry.UnmarshalWKT(tc.wkt)
+			require.NoError(t, err)
+			assert.Equal(t, tc.geometry, decoded)
+		})
+	}
+}
+
+func TestUnmarshalWKTErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		wkt  string
+		err  string
+	}{
+		{
+			name: "unknown type",
+			wkt:  "CIRCLE (1 2)",
+			err:  `unsupported geometry type "CIRCLE"`,
+		},
+		{
+			name: "missing parenthesis",
+			wkt:  "POINT (1 2",
+			err:  `expected ')', found end of input`,
+		},
+		{
+			name: "trailing content",
+			wkt:  "POINT (1 2) foo",
+			err:  `unexpected "foo" after geometry at position 12`,
+		},
+		{
+			name: "wrong dimension for tag",
+			wkt:  "POINT Z (1 2)",
+			err:  "expected 3 values in position, found 2",
+		},
+		{
+			name: "mixed dimensions",
+			wkt:  "LINESTRING (0 0, 1 1 1)",
+			err:  "expected 2 values in position, found 3",
+		},
+		{
+			name: "unclosed ring",
+			wkt:  "POLYGON ((0 0, 1 0, 1 1, 0 1))",
+			err:  "invalid polygon ring 0: ring is not closed",
+		},
+		{
+			name: "bad number",
+			wkt:  "POINT (1 2-)",
+			err:  `invalid number "2-" at position 9`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := geometry.UnmarshalWKT(tc.wkt)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestMeasuredGeoJSON(t *testing.T) {
+	g, err := geometry.UnmarshalWKT("LINESTRING M (0 0 10, 1 1 20)")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}`, string(data))
+	assert.Equal(t, []float64{0, 0, 1, 1}, g.Bounds())
+}